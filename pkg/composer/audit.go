@@ -2,15 +2,26 @@ package composer
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
 	"strings"
 )
 
+// ErrAuditFailed 表示安全审计没有成功执行
+var ErrAuditFailed = errors.New("安全审计失败")
+
 // AuditResult 表示安全审计结果
+//
+// 对应`composer audit --format=json`输出中的 advisories 和 abandoned 两部分，
+// Vulnerabilities 是将 advisories 按包名展开后的漏洞列表。
 type AuditResult struct {
 	Vulnerabilities []Vulnerability `json:"vulnerabilities"`
 	Found           int             `json:"found"`
-	Advisory        string          `json:"advisory,omitempty"`
-	WithoutDev      bool            `json:"without-dev,omitempty"`
+	// Abandoned 已放弃维护的包及其推荐的替代包，没有替代包时为空字符串
+	Abandoned  map[string]string `json:"abandoned,omitempty"`
+	Advisory   string            `json:"advisory,omitempty"`
+	WithoutDev bool              `json:"without-dev,omitempty"`
 }
 
 // Vulnerability 表示一个安全漏洞
@@ -25,6 +36,128 @@ type Vulnerability struct {
 	Severity    string   `json:"severity,omitempty"`
 	Source      string   `json:"source,omitempty"`
 	Affectedver string   `json:"affectedver,omitempty"`
+	// Sources 公告在各个来源中的编号，例如 GitHub 的 GHSA 编号
+	Sources []AdvisorySource `json:"sources,omitempty"`
+	// ReportedAt 公告的发布时间
+	ReportedAt string `json:"reported-at,omitempty"`
+}
+
+// AdvisorySource 表示安全公告的一个来源
+type AdvisorySource struct {
+	Name     string `json:"name"`
+	RemoteID string `json:"remoteId"`
+}
+
+// auditAdvisory 对应 composer audit JSON 输出中的一条安全公告
+type auditAdvisory struct {
+	AdvisoryID       string           `json:"advisoryId"`
+	PackageName      string           `json:"packageName"`
+	AffectedVersions string           `json:"affectedVersions"`
+	Title            string           `json:"title"`
+	CVE              *string          `json:"cve"`
+	Link             string           `json:"link"`
+	ReportedAt       string           `json:"reportedAt"`
+	Sources          []AdvisorySource `json:"sources"`
+	Severity         *string          `json:"severity"`
+}
+
+// vulnerability 将安全公告转换为 Vulnerability
+func (a auditAdvisory) vulnerability(name string) Vulnerability {
+	vuln := Vulnerability{
+		Package:     a.PackageName,
+		Title:       a.Title,
+		Link:        a.Link,
+		Advisory:    a.AdvisoryID,
+		Affectedver: a.AffectedVersions,
+		Sources:     a.Sources,
+		ReportedAt:  a.ReportedAt,
+	}
+	if vuln.Package == "" {
+		vuln.Package = name
+	}
+	if a.CVE != nil && *a.CVE != "" {
+		vuln.CVE = []string{*a.CVE}
+	}
+	if a.Severity != nil {
+		vuln.Severity = *a.Severity
+	}
+	if len(a.Sources) > 0 {
+		vuln.Source = a.Sources[0].Name
+	}
+	return vuln
+}
+
+// UnmarshalJSON 解析 composer audit 的 JSON 输出
+//
+// Composer 在没有结果时会把 advisories 和 abandoned 输出为空数组`[]`，
+// 部分公告被忽略后每个包的公告列表也可能被输出为以序号为键的对象，这里都能正确处理。
+// 缺少 advisories 字段的输出不是审计结果，会返回错误。
+func (r *AuditResult) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Advisories json.RawMessage `json:"advisories"`
+		Abandoned  json.RawMessage `json:"abandoned"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	if len(raw.Advisories) == 0 {
+		return fmt.Errorf("%w: 输出中缺少advisories", ErrAuditFailed)
+	}
+
+	var packages map[string]json.RawMessage
+	if err := decodePHPMap(raw.Advisories, &packages); err != nil {
+		return fmt.Errorf("解析advisories失败: %w", err)
+	}
+	var abandoned map[string]*string
+	if err := decodePHPMap(raw.Abandoned, &abandoned); err != nil {
+		return fmt.Errorf("解析abandoned失败: %w", err)
+	}
+
+	*r = AuditResult{}
+	for name, list := range packages {
+		var advisories []auditAdvisory
+		if err := json.Unmarshal(list, &advisories); err != nil {
+			var indexed map[string]auditAdvisory
+			if err := decodePHPMap(list, &indexed); err != nil {
+				return fmt.Errorf("解析 %s 的安全公告失败: %w", name, err)
+			}
+			for _, advisory := range indexed {
+				advisories = append(advisories, advisory)
+			}
+		}
+		for _, advisory := range advisories {
+			r.Vulnerabilities = append(r.Vulnerabilities, advisory.vulnerability(name))
+		}
+	}
+	sort.Slice(r.Vulnerabilities, func(i, j int) bool {
+		a, b := r.Vulnerabilities[i], r.Vulnerabilities[j]
+		if a.Package != b.Package {
+			return a.Package < b.Package
+		}
+		return a.Advisory < b.Advisory
+	})
+	r.Found = len(r.Vulnerabilities)
+
+	if len(abandoned) > 0 {
+		r.Abandoned = make(map[string]string, len(abandoned))
+		for name, replacement := range abandoned {
+			if replacement != nil {
+				r.Abandoned[name] = *replacement
+			} else {
+				r.Abandoned[name] = ""
+			}
+		}
+	}
+	return nil
+}
+
+// decodePHPMap 解析由 PHP 关联数组编码而来的 JSON 对象，空数组视为空对象
+func decodePHPMap(data json.RawMessage, v interface{}) error {
+	trimmed := strings.TrimSpace(string(data))
+	if trimmed == "" || trimmed == "null" || strings.Join(strings.Fields(trimmed), "") == "[]" {
+		return nil
+	}
+	return json.Unmarshal(data, v)
 }
 
 // Audit 执行安全审计
@@ -59,6 +192,8 @@ func (c *Composer) Audit() (string, error) {
 //
 //	该方法对项目的依赖进行安全审计，并返回结构化的JSON格式结果。
 //	相当于执行`composer audit --format=json`命令，并将输出解析为结构体。
+//	Composer 在发现漏洞时会返回非零退出码，此时只要输出中包含审计结果就会正常返回；
+//	如果命令失败且没有解析出任何漏洞或已放弃的包，则返回 ErrAuditFailed。
 //
 // 用法示例：
 //
@@ -73,14 +208,20 @@ func (c *Composer) Audit() (string, error) {
 //	    fmt.Printf("详情: %s\n\n", vuln.Link)
 //	}
 func (c *Composer) AuditWithJSON() (*AuditResult, error) {
-	output, err := c.Run("audit", "--format=json")
-	if err != nil {
-		return nil, err
-	}
+	output, runErr := c.Run("audit", "--format=json")
 
 	var result AuditResult
 	if err := json.Unmarshal([]byte(output), &result); err != nil {
-		return nil, err
+		if runErr != nil {
+			return nil, fmt.Errorf("%w: %v", ErrAuditFailed, runErr)
+		}
+		return nil, fmt.Errorf("%w: %v", ErrAuditFailed, err)
+	}
+
+	// Composer 只会因为发现漏洞或已放弃的包而以非零退出码结束，
+	// 没有任何结果却失败说明审计本身出错（例如无法获取安全公告），不能视为安全
+	if runErr != nil && result.Found == 0 && len(result.Abandoned) == 0 {
+		return nil, fmt.Errorf("%w: %v", ErrAuditFailed, runErr)
 	}
 
 	return &result, nil
@@ -151,6 +292,8 @@ func (c *Composer) AuditWithFormat(format string) (string, error) {
 //
 //	该方法检查项目是否存在任何安全漏洞，如果存在则返回true。
 //	这个方法会自动解析审计命令的输出，即使命令因发现漏洞而返回非零退出码也能正确处理。
+//	如果通过 SetAuditIgnorePolicy 设置了忽略策略，则只有未被忽略或忽略规则已过期的
+//	漏洞才会使其返回true，便于在CI中作为门禁使用。
 //
 // 用法示例：
 //
@@ -165,12 +308,16 @@ func (c *Composer) AuditWithFormat(format string) (string, error) {
 //	    fmt.Println("项目中未发现安全漏洞。")
 //	}
 func (c *Composer) HasVulnerabilities() (bool, error) {
+	if c.auditIgnorePolicy != nil {
+		report, err := c.AuditWithPolicy(c.auditIgnorePolicy)
+		if err != nil {
+			return false, err
+		}
+		return report.HasVulnerabilities(), nil
+	}
+
 	result, err := c.AuditWithJSON()
 	if err != nil {
-		// 如果命令失败且出错信息包含漏洞，也认为有漏洞
-		if strings.Contains(err.Error(), "Found") && strings.Contains(err.Error(), "vulnerabilities") {
-			return true, nil
-		}
 		return false, err
 	}
 
@@ -305,7 +452,8 @@ func (c *Composer) AuditLock(lockFilePath string) (string, error) {
 //
 // 功能说明：
 //
-//	该方法执行安全审计并筛选出已被标记为"abandoned"（已放弃维护）的包。
+//	该方法执行安全审计并返回审计结果中"abandoned"（已放弃维护）部分列出的包，
+//	如果包作者推荐了替代包，会写入 Title 字段。
 //	使用已放弃的包可能存在安全风险，应考虑替换它们。
 //
 // 用法示例：
@@ -318,8 +466,7 @@ func (c *Composer) AuditLock(lockFilePath string) (string, error) {
 //	if len(abandoned) > 0 {
 //	    fmt.Printf("发现 %d 个已放弃维护的包:\n", len(abandoned))
 //	    for _, pkg := range abandoned {
-//	        fmt.Printf("包: %s 版本: %s\n", pkg.Package, pkg.Version)
-//	        fmt.Printf("详情: %s\n\n", pkg.Link)
+//	        fmt.Printf("包: %s %s\n", pkg.Package, pkg.Title)
 //	    }
 //	    fmt.Println("建议替换这些包以避免潜在的安全风险。")
 //	} else {
@@ -332,11 +479,16 @@ func (c *Composer) GetAbandonedPackages() ([]Vulnerability, error) {
 	}
 
 	var abandoned []Vulnerability
-	for _, vuln := range result.Vulnerabilities {
-		if vuln.Abandoned {
-			abandoned = append(abandoned, vuln)
+	for name, replacement := range result.Abandoned {
+		pkg := Vulnerability{Package: name, Abandoned: true}
+		if replacement != "" {
+			pkg.Title = "建议使用 " + replacement + " 替代"
 		}
+		abandoned = append(abandoned, pkg)
 	}
+	sort.Slice(abandoned, func(i, j int) bool {
+		return abandoned[i].Package < abandoned[j].Package
+	})
 
 	return abandoned, nil
 }
@@ -0,0 +1,233 @@
+package composer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// ErrInvalidAuditIgnorePolicy 表示审计忽略策略文件内容无效
+var ErrInvalidAuditIgnorePolicy = errors.New("审计忽略策略无效")
+
+// AuditIgnoreRule 表示一条带有效期和审批信息的审计忽略规则
+type AuditIgnoreRule struct {
+	// ID 安全公告ID（如 PKSA-xxxx、GHSA-xxxx）或 CVE 编号
+	ID string `json:"id"`
+	// Package 限定规则生效的包名，为空表示匹配所有包
+	Package string `json:"package,omitempty"`
+	// Reason 忽略该漏洞的理由
+	Reason string `json:"reason"`
+	// ExpiresAt 过期时间，支持 "2006-01-02" 或 RFC3339 格式
+	ExpiresAt string `json:"expires-at"`
+	// Approver 批准该忽略规则的负责人
+	Approver string `json:"approver"`
+}
+
+// AuditIgnorePolicy 表示审计忽略策略文件
+type AuditIgnorePolicy struct {
+	Rules []AuditIgnoreRule `json:"ignore"`
+}
+
+// IgnoredFinding 表示被某条忽略规则匹配到的漏洞
+type IgnoredFinding struct {
+	Vulnerability Vulnerability   `json:"vulnerability"`
+	Rule          AuditIgnoreRule `json:"rule"`
+}
+
+// AuditReport 表示应用忽略策略后的审计报告
+type AuditReport struct {
+	// Active 未被任何规则忽略的漏洞
+	Active []Vulnerability `json:"active"`
+	// Ignored 被有效规则忽略的漏洞
+	Ignored []IgnoredFinding `json:"ignored"`
+	// ExpiredIgnores 匹配到已过期规则的漏洞，视同未忽略
+	ExpiredIgnores []IgnoredFinding `json:"expired-ignores"`
+}
+
+// HasVulnerabilities 判断报告中是否仍存在需要处理的漏洞
+//
+// 已过期的忽略规则不再生效，因此其匹配到的漏洞同样会被计入。
+func (r *AuditReport) HasVulnerabilities() bool {
+	return len(r.Active) > 0 || len(r.ExpiredIgnores) > 0
+}
+
+// Expiry 解析规则的过期时间
+//
+// 仅包含日期的值视为当天结束（UTC 23:59:59）时过期。
+func (r AuditIgnoreRule) Expiry() (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, r.ExpiresAt); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse("2006-01-02", r.ExpiresAt)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("无法解析过期时间 %q: %w", r.ExpiresAt, err)
+	}
+	return t.Add(24*time.Hour - time.Second), nil
+}
+
+// Matches 判断规则是否匹配指定漏洞
+//
+// 规则ID与漏洞的公告ID、任一CVE编号或来源编号（如GHSA）相同（不区分大小写）即视为匹配，
+// 如果规则指定了包名，则包名也必须一致。
+func (r AuditIgnoreRule) Matches(vuln Vulnerability) bool {
+	if r.Package != "" && !strings.EqualFold(r.Package, vuln.Package) {
+		return false
+	}
+	if strings.EqualFold(r.ID, vuln.Advisory) {
+		return true
+	}
+	for _, cve := range vuln.CVE {
+		if strings.EqualFold(r.ID, cve) {
+			return true
+		}
+	}
+	for _, source := range vuln.Sources {
+		if strings.EqualFold(r.ID, source.RemoteID) {
+			return true
+		}
+	}
+	return false
+}
+
+// LoadAuditIgnorePolicy 从文件加载并校验审计忽略策略
+//
+// 参数：
+//   - path: 策略文件路径
+//
+// 返回值：
+//   - *AuditIgnorePolicy: 解析后的策略
+//   - error: 如果读取、解析或校验失败，则返回相应的错误信息
+//
+// 用法示例：
+//
+//	policy, err := composer.LoadAuditIgnorePolicy("audit-ignore.json")
+//	if err != nil {
+//	    log.Fatalf("加载审计忽略策略失败: %v", err)
+//	}
+//	comp.SetAuditIgnorePolicy(policy)
+func LoadAuditIgnorePolicy(path string) (*AuditIgnorePolicy, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var policy AuditIgnorePolicy
+	if err := json.Unmarshal(content, &policy); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidAuditIgnorePolicy, err)
+	}
+
+	if err := policy.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &policy, nil
+}
+
+// Validate 校验策略中的每条规则
+//
+// 每条规则都必须包含ID、理由、审批人以及可解析的过期时间，
+// 以避免出现无人负责或永久生效的忽略规则。
+func (p *AuditIgnorePolicy) Validate() error {
+	for i, rule := range p.Rules {
+		switch {
+		case rule.ID == "":
+			return fmt.Errorf("%w: 第%d条规则缺少id", ErrInvalidAuditIgnorePolicy, i+1)
+		case rule.Reason == "":
+			return fmt.Errorf("%w: 规则 %s 缺少reason", ErrInvalidAuditIgnorePolicy, rule.ID)
+		case rule.Approver == "":
+			return fmt.Errorf("%w: 规则 %s 缺少approver", ErrInvalidAuditIgnorePolicy, rule.ID)
+		}
+		if _, err := rule.Expiry(); err != nil {
+			return fmt.Errorf("%w: 规则 %s: %v", ErrInvalidAuditIgnorePolicy, rule.ID, err)
+		}
+	}
+	return nil
+}
+
+// Apply 将策略应用于审计结果
+//
+// 参数：
+//   - result: 审计结果
+//   - now: 用于判断规则是否过期的当前时间
+//
+// 返回值：
+//   - *AuditReport: 按活跃、已忽略、忽略已过期分类的审计报告
+func (p *AuditIgnorePolicy) Apply(result *AuditResult, now time.Time) *AuditReport {
+	report := &AuditReport{}
+	if result == nil {
+		return report
+	}
+
+	for _, vuln := range result.Vulnerabilities {
+		rule, ok := p.match(vuln)
+		if !ok {
+			report.Active = append(report.Active, vuln)
+			continue
+		}
+
+		finding := IgnoredFinding{Vulnerability: vuln, Rule: rule}
+		if expiry, err := rule.Expiry(); err != nil || now.After(expiry) {
+			report.ExpiredIgnores = append(report.ExpiredIgnores, finding)
+		} else {
+			report.Ignored = append(report.Ignored, finding)
+		}
+	}
+
+	return report
+}
+
+// match 返回第一条匹配漏洞的规则
+func (p *AuditIgnorePolicy) match(vuln Vulnerability) (AuditIgnoreRule, bool) {
+	if p == nil {
+		return AuditIgnoreRule{}, false
+	}
+	for _, rule := range p.Rules {
+		if rule.Matches(vuln) {
+			return rule, true
+		}
+	}
+	return AuditIgnoreRule{}, false
+}
+
+// SetAuditIgnorePolicy 设置审计忽略策略
+//
+// 设置后 HasVulnerabilities 只会在存在未忽略或忽略已过期的漏洞时返回true，
+// 传入nil则取消策略。
+func (c *Composer) SetAuditIgnorePolicy(policy *AuditIgnorePolicy) {
+	c.auditIgnorePolicy = policy
+}
+
+// AuditWithPolicy 执行安全审计并应用忽略策略
+//
+// 参数：
+//   - policy: 审计忽略策略，为nil时所有漏洞均视为活跃
+//
+// 返回值：
+//   - *AuditReport: 应用策略后的审计报告
+//   - error: 如果执行安全审计或解析结果失败，则返回相应的错误信息
+//
+// 功能说明：
+//
+//	该方法执行`composer audit --format=json`并将结果按照策略分类。
+//	Composer在发现漏洞时会返回非零退出码，只要输出中包含审计结果就会正常解析；
+//	命令失败且没有任何审计结果时返回 ErrAuditFailed，而不是当作没有漏洞。
+//
+// 用法示例：
+//
+//	report, err := comp.AuditWithPolicy(policy)
+//	if err != nil {
+//	    log.Fatalf("执行安全审计失败: %v", err)
+//	}
+//	for _, f := range report.ExpiredIgnores {
+//	    fmt.Printf("忽略规则已过期: %s (%s)\n", f.Rule.ID, f.Rule.Approver)
+//	}
+func (c *Composer) AuditWithPolicy(policy *AuditIgnorePolicy) (*AuditReport, error) {
+	result, err := c.AuditWithJSON()
+	if err != nil {
+		return nil, err
+	}
+	return policy.Apply(result, time.Now()), nil
+}
@@ -0,0 +1,160 @@
+package composer
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// readTestdata 读取 testdata 目录中的文件
+func readTestdata(t *testing.T, name string) string {
+	t.Helper()
+	content, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("读取测试数据失败: %v", err)
+	}
+	return string(content)
+}
+
+func TestAuditIgnorePolicyApply(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	policy := &AuditIgnorePolicy{Rules: []AuditIgnoreRule{
+		{ID: "cve-2024-0001", Reason: "不可达代码路径", ExpiresAt: "2025-12-31", Approver: "sec-team"},
+		{ID: "PKSA-bbbb", Package: "vendor/b", Reason: "等待上游修复", ExpiresAt: "2025-05-31", Approver: "sec-team"},
+		{ID: "PKSA-cccc", Package: "vendor/other", Reason: "包名不匹配", ExpiresAt: "2030-01-01", Approver: "sec-team"},
+	}}
+
+	result := &AuditResult{Vulnerabilities: []Vulnerability{
+		{Package: "vendor/a", Advisory: "PKSA-aaaa", CVE: []string{"CVE-2024-0001"}},
+		{Package: "vendor/b", Advisory: "PKSA-bbbb"},
+		{Package: "vendor/c", Advisory: "PKSA-cccc"},
+	}}
+
+	report := policy.Apply(result, now)
+
+	if len(report.Ignored) != 1 || report.Ignored[0].Vulnerability.Package != "vendor/a" {
+		t.Errorf("vendor/a应被忽略，实际为%+v", report.Ignored)
+	}
+	if len(report.ExpiredIgnores) != 1 || report.ExpiredIgnores[0].Vulnerability.Package != "vendor/b" {
+		t.Errorf("vendor/b的忽略规则应已过期，实际为%+v", report.ExpiredIgnores)
+	}
+	if len(report.Active) != 1 || report.Active[0].Package != "vendor/c" {
+		t.Errorf("vendor/c应为活跃漏洞，实际为%+v", report.Active)
+	}
+	if !report.HasVulnerabilities() {
+		t.Error("存在活跃漏洞时HasVulnerabilities应返回true")
+	}
+}
+
+func TestAuditIgnoreRuleExpiry(t *testing.T) {
+	rule := AuditIgnoreRule{ExpiresAt: "2025-05-31"}
+	expiry, err := rule.Expiry()
+	if err != nil {
+		t.Fatalf("解析日期失败: %v", err)
+	}
+	if !expiry.Equal(time.Date(2025, 5, 31, 23, 59, 59, 0, time.UTC)) {
+		t.Errorf("仅日期的过期时间应为当天结束，实际为%v", expiry)
+	}
+
+	rule.ExpiresAt = "2025-05-31T08:00:00+08:00"
+	if _, err := rule.Expiry(); err != nil {
+		t.Errorf("应支持RFC3339格式: %v", err)
+	}
+
+	rule.ExpiresAt = "next week"
+	if _, err := rule.Expiry(); err == nil {
+		t.Error("无效的过期时间应返回错误")
+	}
+}
+
+func TestLoadAuditIgnorePolicy(t *testing.T) {
+	dir := t.TempDir()
+
+	validPath := filepath.Join(dir, "valid.json")
+	valid := `{"ignore": [{"id": "CVE-2024-0001", "package": "vendor/a", "reason": "误报", "expires-at": "2025-12-31", "approver": "alice"}]}`
+	if err := os.WriteFile(validPath, []byte(valid), 0644); err != nil {
+		t.Fatalf("写入策略文件失败: %v", err)
+	}
+
+	policy, err := LoadAuditIgnorePolicy(validPath)
+	if err != nil {
+		t.Fatalf("加载有效策略失败: %v", err)
+	}
+	if len(policy.Rules) != 1 || policy.Rules[0].Approver != "alice" {
+		t.Errorf("策略内容解析不正确: %+v", policy.Rules)
+	}
+
+	invalidCases := map[string]string{
+		"missing-approver.json": `{"ignore": [{"id": "CVE-1", "reason": "r", "expires-at": "2025-12-31"}]}`,
+		"missing-expiry.json":   `{"ignore": [{"id": "CVE-1", "reason": "r", "approver": "a"}]}`,
+		"missing-reason.json":   `{"ignore": [{"id": "CVE-1", "approver": "a", "expires-at": "2025-12-31"}]}`,
+		"broken.json":           `{"ignore": [`,
+	}
+	for name, content := range invalidCases {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("写入策略文件失败: %v", err)
+		}
+		if _, err := LoadAuditIgnorePolicy(path); !errors.Is(err, ErrInvalidAuditIgnorePolicy) {
+			t.Errorf("%s 应返回ErrInvalidAuditIgnorePolicy，实际为%v", name, err)
+		}
+	}
+}
+
+func TestHasVulnerabilitiesWithPolicy(t *testing.T) {
+	ClearMockOutputs()
+	defer ClearMockOutputs()
+
+	// Composer发现漏洞时以非零退出码结束，但输出仍是JSON
+	SetupMockOutput("audit --format=json", readTestdata(t, "audit.json"), errors.New("exit status 1"))
+
+	composer, err := New(Options{ExecutablePath: "/path/to/composer"})
+	if err != nil {
+		t.Fatalf("创建Composer实例失败: %v", err)
+	}
+
+	// 分别通过公告ID、CVE编号和GHSA编号匹配
+	expires := time.Now().Add(24 * time.Hour).Format(time.RFC3339)
+	composer.SetAuditIgnorePolicy(&AuditIgnorePolicy{Rules: []AuditIgnoreRule{
+		{ID: "PKSA-yfw5-9gnj-n2c7", Reason: "r", ExpiresAt: expires, Approver: "a"},
+		{ID: "CVE-2023-29197", Package: "guzzlehttp/psr7", Reason: "r", ExpiresAt: expires, Approver: "a"},
+	}})
+
+	hasVulns, err := composer.HasVulnerabilities()
+	if err != nil {
+		t.Fatalf("HasVulnerabilities执行失败: %v", err)
+	}
+	if !hasVulns {
+		t.Error("GHSA-q7rv-6hp3-vh96未被忽略，应返回true")
+	}
+
+	composer.SetAuditIgnorePolicy(&AuditIgnorePolicy{Rules: []AuditIgnoreRule{
+		{ID: "PKSA-yfw5-9gnj-n2c7", Reason: "r", ExpiresAt: expires, Approver: "a"},
+		{ID: "CVE-2023-29197", Package: "guzzlehttp/psr7", Reason: "r", ExpiresAt: expires, Approver: "a"},
+		{ID: "ghsa-q7rv-6hp3-vh96", Reason: "r", ExpiresAt: expires, Approver: "a"},
+	}})
+
+	report, err := composer.AuditWithPolicy(composer.auditIgnorePolicy)
+	if err != nil {
+		t.Fatalf("AuditWithPolicy执行失败: %v", err)
+	}
+	if len(report.Ignored) != 3 {
+		t.Errorf("应忽略3个漏洞，实际为%d", len(report.Ignored))
+	}
+
+	hasVulns, err = composer.HasVulnerabilities()
+	if err != nil {
+		t.Fatalf("HasVulnerabilities执行失败: %v", err)
+	}
+	if hasVulns {
+		t.Error("所有漏洞均被有效规则忽略时应返回false")
+	}
+
+	// 审计命令失败且没有任何结果时不能视为没有漏洞
+	SetupMockOutput("audit --format=json", "", errors.New("exit status 100"))
+	if _, err := composer.HasVulnerabilities(); !errors.Is(err, ErrAuditFailed) {
+		t.Errorf("期望错误 %v，但得到 %v", ErrAuditFailed, err)
+	}
+}
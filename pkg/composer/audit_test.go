@@ -0,0 +1,92 @@
+package composer
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestAuditWithJSON(t *testing.T) {
+	ClearMockOutputs()
+	defer ClearMockOutputs()
+
+	composer, err := New(Options{ExecutablePath: "/path/to/composer"})
+	if err != nil {
+		t.Fatalf("创建Composer实例失败: %v", err)
+	}
+
+	SetupMockOutput("audit --format=json", readTestdata(t, "audit.json"), errors.New("exit status 1"))
+	result, err := composer.AuditWithJSON()
+	if err != nil {
+		t.Fatalf("AuditWithJSON执行失败: %v", err)
+	}
+	if result.Found != 3 || len(result.Vulnerabilities) != 3 {
+		t.Fatalf("期望3个漏洞，实际为%d: %+v", result.Found, result.Vulnerabilities)
+	}
+
+	// 以序号为键的公告列表同样能被解析，结果按包名和公告ID排序
+	guzzle := result.Vulnerabilities[0]
+	if guzzle.Package != "guzzlehttp/guzzle" || guzzle.Advisory != "PKSA-yfw5-9gnj-n2c7" || guzzle.Severity != "high" {
+		t.Errorf("guzzlehttp/guzzle 的漏洞解析错误: %+v", guzzle)
+	}
+	if !reflect.DeepEqual(guzzle.CVE, []string{"CVE-2022-31091"}) || guzzle.Affectedver != "<6.5.8|>=7,<7.4.5" {
+		t.Errorf("CVE或受影响版本解析错误: %+v", guzzle)
+	}
+	if guzzle.Source != "GitHub" || len(guzzle.Sources) != 2 || guzzle.Sources[0].RemoteID != "GHSA-q559-8m2m-g699" {
+		t.Errorf("公告来源解析错误: %+v", guzzle.Sources)
+	}
+	psr7 := result.Vulnerabilities[2]
+	if psr7.Advisory != "PKSA-8dz5-n4kf-rxy1" || psr7.CVE != nil || psr7.Severity != "" {
+		t.Errorf("cve和severity为null时应为空值: %+v", psr7)
+	}
+
+	expected := map[string]string{"fzaninotto/faker": "", "swiftmailer/swiftmailer": "symfony/mailer"}
+	if !reflect.DeepEqual(result.Abandoned, expected) {
+		t.Errorf("abandoned 解析错误: 期望 %v，实际 %v", expected, result.Abandoned)
+	}
+
+	high, err := composer.GetHighSeverityVulnerabilities()
+	if err != nil || len(high) != 1 || high[0].Package != "guzzlehttp/guzzle" {
+		t.Errorf("高危漏洞筛选错误: %+v, %v", high, err)
+	}
+	abandoned, err := composer.GetAbandonedPackages()
+	if err != nil || len(abandoned) != 2 || abandoned[1].Package != "swiftmailer/swiftmailer" || !abandoned[1].Abandoned {
+		t.Errorf("已放弃的包错误: %+v, %v", abandoned, err)
+	}
+}
+
+func TestAuditWithJSONClean(t *testing.T) {
+	ClearMockOutputs()
+	defer ClearMockOutputs()
+
+	composer, err := New(Options{ExecutablePath: "/path/to/composer"})
+	if err != nil {
+		t.Fatalf("创建Composer实例失败: %v", err)
+	}
+
+	// 没有结果时 Composer 将 advisories 和 abandoned 输出为空数组
+	SetupMockOutput("audit --format=json", readTestdata(t, "audit-clean.json"), nil)
+	hasVulns, err := composer.HasVulnerabilities()
+	if err != nil {
+		t.Fatalf("HasVulnerabilities执行失败: %v", err)
+	}
+	if hasVulns {
+		t.Error("没有安全公告时应返回false")
+	}
+
+	tests := []struct {
+		name   string
+		output string
+		err    error
+	}{
+		{"非零退出码且没有结果", readTestdata(t, "audit-clean.json"), errors.New("exit status 1")},
+		{"输出不是审计结果", `{"packages": []}`, nil},
+		{"无法获取安全公告", "The following exception probably indicates you are offline", errors.New("exit status 100")},
+	}
+	for _, tt := range tests {
+		SetupMockOutput("audit --format=json", tt.output, tt.err)
+		if _, err := composer.AuditWithJSON(); !errors.Is(err, ErrAuditFailed) {
+			t.Errorf("%s: 期望错误 %v，但得到 %v", tt.name, ErrAuditFailed, err)
+		}
+	}
+}
@@ -11,6 +11,7 @@ import (
 
 	"github.com/scagogogo/go-composer-sdk/pkg/detector"
 	"github.com/scagogogo/go-composer-sdk/pkg/installer"
+	"github.com/scagogogo/go-composer-sdk/pkg/resolver"
 )
 
 // 常见错误
@@ -134,10 +135,20 @@ type Composer struct {
 	installer *installer.Installer
 	// 自定义检测器
 	detector *detector.Detector
-	// 环境变量
+	// 实例的环境变量，覆盖继承的进程环境变量
 	env []string
+	// 执行命令时从继承的进程环境变量中删除的变量
+	unsetEnv []string
 	// 默认超时时间
 	defaultTimeout time.Duration
+	// 审计忽略策略
+	auditIgnorePolicy *AuditIgnorePolicy
+	// 运行composer.phar使用的PHP可执行文件，为空时直接运行executablePath
+	phpBinary string
+	// 通过容器运行Composer的配置，为nil时在本机运行
+	container *ContainerOptions
+	// 通过容器运行时使用的容器引擎的完整路径
+	containerEngine string
 }
 
 // Options 用于自定义Composer实例的选项
@@ -156,6 +167,19 @@ type Options struct {
 	Env []string
 	// 默认超时时间
 	DefaultTimeout time.Duration
+	// 运行Composer使用的PHP可执行文件，设置后以 "<PHPBinary> <composer.phar>" 的方式运行，
+	// 可以从 detector.DetectPHP 的结果中选择
+	PHPBinary string
+	// 要求的Composer版本约束，例如 ^2.5 或 >=2.5，语法与composer.json相同；
+	// 设置后New只使用满足约束的Composer，见 New 的说明
+	RequiredVersion string
+	// 设置了 RequiredVersion 时，是否在工作目录中查找项目自带的Composer（composer.phar、
+	// vendor/bin/composer）并优先使用。这些文件来自检出的代码，检测时会被运行，
+	// 只应对可信的项目开启；默认关闭
+	PreferProjectLocal bool
+	// 通过 docker 或 podman 使用官方 composer 镜像运行Composer，设置后不需要本机安装PHP和Composer，
+	// 也不会检测或自动安装Composer；不能与 ExecutablePath 同时设置
+	Container *ContainerOptions
 }
 
 // DefaultOptions 返回默认选项
@@ -196,7 +220,17 @@ func DefaultOptions() Options {
 //
 //	该方法会创建一个新的Composer实例。如果未指定可执行文件路径，它会尝试
 //	检测系统中已安装的Composer。如果未找到且autoInstall设置为true，则会
-//	尝试自动安装Composer。
+//	尝试自动安装Composer；使用默认安装器且没有系统目录的写权限时，
+//	会改为安装到用户目录（见 installer.DefaultUserConfig）。
+//
+//	设置了 RequiredVersion 时，未指定可执行文件路径则使用 detector.DetectAll 找到的
+//	第一个满足约束的安装（开启了 PreferProjectLocal 时优先使用工作目录中的项目内安装）；都不满足且 AutoInstall 为 true 时
+//	安装满足约束的版本：约束是完整版本号时安装该版本，否则安装 installer.FetchReleases 中
+//	满足约束的最高版本，没有时在下载前返回错误；安装器已经指定了 Version 或 Channel 时保持不变。指定了可执行文件路径时检查其版本。
+//	找不到满足约束的Composer时返回 *VersionMismatchError，其中列出找到的版本。
+//
+//	设置了 Container 时不检测本机的Composer，而是查找容器引擎，之后的命令通过
+//	"<engine> run ... <image> composer <args>" 在容器中运行；同时设置 ExecutablePath 时返回 ErrInitFailed。
 //
 // 用法示例：
 //
@@ -215,29 +249,73 @@ func New(options Options) (*Composer, error) {
 		detector:       options.Detector,
 		env:            options.Env,
 		defaultTimeout: options.DefaultTimeout,
+		phpBinary:      options.PHPBinary,
+		container:      options.Container,
+	}
+
+	var constraint *resolver.Constraint
+	if options.RequiredVersion != "" {
+		var err error
+		constraint, err = resolver.ParseConstraint(options.RequiredVersion)
+		if err != nil {
+			return nil, fmt.Errorf("%w: 无效的版本约束: %v", ErrInitFailed, err)
+		}
 	}
 
 	// 如果未提供安装器，使用默认安装器
-	if c.installer == nil {
+	defaultInstaller := c.installer == nil
+	if defaultInstaller {
 		c.installer = installer.NewInstaller(installer.DefaultConfig())
 	}
 
-	// 如果未提供检测器，使用默认检测器
+	// 如果未提供检测器，使用默认检测器，开启了 PreferProjectLocal 时在工作目录中查找项目内的Composer
 	if c.detector == nil {
 		c.detector = detector.NewDetector()
+		if options.PreferProjectLocal {
+			projectDir := c.workingDir
+			if projectDir == "" {
+				projectDir, _ = os.Getwd()
+			}
+			c.detector.SetProjectDir(projectDir)
+		}
+	}
+
+	// 通过容器运行时只需要找到容器引擎
+	if c.container != nil {
+		if c.executablePath != "" {
+			return nil, fmt.Errorf("%w: ExecutablePath 不能与 Container 同时使用", ErrInitFailed)
+		}
+		engine, err := exec.LookPath(c.container.engine())
+		if err != nil {
+			return nil, fmt.Errorf("%w: 未找到容器引擎 %s: %v", ErrComposerNotFound, c.container.engine(), err)
+		}
+		c.containerEngine = engine
+		if constraint != nil {
+			if err := c.checkRequiredVersion(options.RequiredVersion, constraint); err != nil {
+				return nil, err
+			}
+		}
+		return c, nil
 	}
 
 	// 如果未指定可执行文件路径，则尝试检测
-	if c.executablePath == "" {
+	if c.executablePath == "" && constraint != nil {
+		execPath, err := c.detectRequiredVersion(options.RequiredVersion, constraint, defaultInstaller, options.PreferProjectLocal)
+		if err != nil {
+			return nil, err
+		}
+		c.executablePath = execPath
+	} else if c.executablePath == "" {
 		execPath, err := c.detector.Detect()
 		if err != nil {
 			if c.autoInstall {
 				// 尝试安装composer
-				if err := c.installer.Install(); err != nil {
+				if err := c.installComposer(defaultInstaller); err != nil {
 					return nil, fmt.Errorf("%w: %v", ErrComposerInstallation, err)
 				}
 
-				// 重新尝试检测
+				// 重新尝试检测，安装位置不在默认路径中时也能找到
+				c.detector.AddPossiblePath(c.installer.ExecutablePath())
 				execPath, err = c.detector.Detect()
 				if err != nil {
 					return nil, fmt.Errorf("%w: %v", ErrComposerNotFound, err)
@@ -254,11 +332,31 @@ func New(options Options) (*Composer, error) {
 				return nil, fmt.Errorf("%w: 指定的可执行文件不存在: %s", ErrComposerNotFound, c.executablePath)
 			}
 		}
+		if constraint != nil {
+			if err := c.checkRequiredVersion(options.RequiredVersion, constraint); err != nil {
+				return nil, err
+			}
+		}
 	}
 
 	return c, nil
 }
 
+// installComposer 使用安装器安装Composer
+//
+// 使用默认安装器且没有系统目录的写权限时，改为以相同的版本设置安装到用户目录。
+func (c *Composer) installComposer(defaultInstaller bool) error {
+	err := c.installer.Install()
+	if err != nil && defaultInstaller && errors.Is(err, installer.ErrInsufficientRights) {
+		config := c.installer.GetConfig()
+		userConfig := installer.DefaultUserConfig()
+		userConfig.Version, userConfig.Channel = config.Version, config.Channel
+		c.installer = installer.NewInstaller(userConfig)
+		err = c.installer.Install()
+	}
+	return err
+}
+
 // SetWorkingDir 设置composer命令的工作目录
 //
 // 参数：
@@ -285,6 +383,8 @@ func (c *Composer) SetWorkingDir(dir string) {
 //
 //	该方法用于设置执行Composer命令时的环境变量。
 //	可以用来配置HTTP代理、身份验证信息或其他影响Composer行为的环境变量。
+//	这些变量覆盖继承的进程环境变量中的同名变量，PATH、HOME 等其他变量保持不变。
+//	该方法替换实例之前设置的所有环境变量，包括 SetEnvVariable 等方法设置和删除的变量。
 //
 // 用法示例：
 //
@@ -296,6 +396,7 @@ func (c *Composer) SetWorkingDir(dir string) {
 //	})
 func (c *Composer) SetEnv(env []string) {
 	c.env = env
+	c.unsetEnv = nil
 }
 
 // Run 执行composer命令并返回输出
@@ -392,13 +493,40 @@ func (c *Composer) RunWithTimeout(timeout time.Duration, args ...string) (string
 //	    }
 //	}
 func (c *Composer) RunWithContext(ctx context.Context, args ...string) (string, error) {
+	return c.RunWithEnv(ctx, nil, args...)
+}
+
+// RunWithEnv 在指定上下文中使用额外的环境变量执行composer命令
+//
+// 参数：
+//   - ctx: 上下文，可用于取消或设置超时
+//   - env: 只用于本次调用的环境变量，格式为["KEY=VALUE", ...]，覆盖实例和进程中的同名变量
+//   - args: 命令参数，第一个参数是composer子命令
+//
+// 返回值：
+//   - string: 命令的标准输出
+//   - error: 如果命令执行失败，则返回相应的错误信息
+//
+// 功能说明：
+//
+//	命令继承当前进程的环境变量，依次覆盖实例的环境变量（SetEnv、SetEnvVariable 等设置）
+//	和本次调用的环境变量。不修改实例或进程的环境变量，可以在多个 goroutine 中使用不同的值并发调用。
+//
+// 用法示例：
+//
+//	output, err := comp.RunWithEnv(ctx, []string{"COMPOSER_MEMORY_LIMIT=-1"}, "update", "--with-all-dependencies")
+func (c *Composer) RunWithEnv(ctx context.Context, env []string, args ...string) (string, error) {
 	// 检查是否有模拟输出
 	if output, err, ok := getMockOutput(args...); ok {
 		return output, err
 	}
 
-	// 创建命令
-	cmd := exec.CommandContext(ctx, c.executablePath, args...)
+	// 创建命令，指定了PHP时通过该PHP运行composer.phar
+	name, cmdArgs, err := c.commandLine(args, env)
+	if err != nil {
+		return "", err
+	}
+	cmd := exec.CommandContext(ctx, name, cmdArgs...)
 
 	// 设置工作目录
 	if c.workingDir != "" {
@@ -406,9 +534,7 @@ func (c *Composer) RunWithContext(ctx context.Context, args ...string) (string,
 	}
 
 	// 设置环境变量
-	if len(c.env) > 0 {
-		cmd.Env = c.env
-	}
+	cmd.Env = c.environ(env)
 
 	// 执行命令并获取输出
 	out, err := cmd.CombinedOutput()
@@ -426,7 +552,7 @@ func (c *Composer) RunWithContext(ctx context.Context, args ...string) (string,
 //
 // 功能说明：
 //
-//	该方法返回Composer实例使用的可执行文件路径。
+//	该方法返回Composer实例使用的可执行文件路径，通过容器运行时为空。
 //
 // 用法示例：
 //
@@ -480,7 +606,8 @@ func (c *Composer) GetEnv() []string {
 //
 // 功能说明：
 //
-//	该方法检查当前Composer实例是否已经有指向有效Composer可执行文件的路径。
+//	该方法检查当前Composer实例是否已经有指向有效Composer可执行文件的路径，
+//	通过容器运行时检查是否找到了容器引擎。
 //	它仅检查路径是否存在，不会验证可执行文件是否可正常工作。
 //
 // 用法示例：
@@ -491,5 +618,5 @@ func (c *Composer) GetEnv() []string {
 //	    fmt.Println("Composer未安装")
 //	}
 func (c *Composer) IsInstalled() bool {
-	return c.executablePath != ""
+	return c.executablePath != "" || c.containerEngine != ""
 }
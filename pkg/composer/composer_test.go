@@ -766,8 +766,6 @@ func TestRunWithContextCancellation(t *testing.T) {
 	}
 }
 
-// TestGetVersionWithInvalidOutput - 暂时注释掉，API行为与预期不符
-/*
 func TestGetVersionWithInvalidOutput(t *testing.T) {
 	// Reset mock outputs before test
 	ClearMockOutputs()
@@ -789,7 +787,6 @@ func TestGetVersionWithInvalidOutput(t *testing.T) {
 		t.Errorf("无效版本输出时版本应为空，实际为\"%s\"", version)
 	}
 }
-*/
 
 func TestGetVersionWithCommandError(t *testing.T) {
 	// Reset mock outputs before test
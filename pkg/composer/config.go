@@ -1,6 +1,7 @@
 package composer
 
 import (
+	"fmt"
 	"strings"
 )
 
@@ -11,7 +12,16 @@ func (c *Composer) Validate() error {
 }
 
 // GetComposerHome 获取Composer主目录
+//
+// 通过容器运行时返回挂载为 COMPOSER_HOME 的宿主机目录，而不是容器中的路径。
 func (c *Composer) GetComposerHome() (string, error) {
+	if c.container != nil {
+		home, _, err := c.containerComposerDirs()
+		if err != nil {
+			return "", fmt.Errorf("%w: %v", ErrGetComposerHome, err)
+		}
+		return home, nil
+	}
 	output, err := c.Run("config", "--global", "home")
 	if err != nil {
 		return "", err
@@ -0,0 +1,157 @@
+package composer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultContainerImage 是通过容器运行Composer时默认使用的官方镜像
+const DefaultContainerImage = "composer:2"
+
+// 容器中的目录
+const (
+	// containerWorkDir 工作目录的挂载位置，与官方镜像的 WORKDIR 相同
+	containerWorkDir = "/app"
+	// containerHomeDir COMPOSER_HOME 的挂载位置
+	containerHomeDir = "/tmp/composer"
+	// containerCacheDir COMPOSER_CACHE_DIR 的挂载位置
+	containerCacheDir = "/tmp/composer-cache"
+)
+
+// ContainerOptions 配置通过 docker 或 podman 运行Composer
+type ContainerOptions struct {
+	// 容器引擎可执行文件，例如 docker、podman 或其完整路径，为空时使用 docker
+	Engine string
+	// 使用的镜像，为空时使用 DefaultContainerImage
+	Image string
+	// 挂载为 COMPOSER_HOME 的宿主机目录，为空时依次使用 GetEnvVariable 返回的 COMPOSER_HOME、~/.composer
+	ComposerHome string
+	// 挂载为 COMPOSER_CACHE_DIR 的宿主机目录，为空时依次使用 GetEnvVariable 返回的 COMPOSER_CACHE_DIR、
+	// ComposerHome 下的 cache 目录
+	CacheDir string
+	// 容器中运行的用户，格式为 uid:gid，为空时使用当前用户（podman 使用 --userns=keep-id，Windows 上不设置）
+	User string
+	// 追加到 run 子命令的其他参数，例如 --network host
+	ExtraArgs []string
+}
+
+// engine 返回容器引擎，未设置时为 docker
+func (o *ContainerOptions) engine() string {
+	if o.Engine == "" {
+		return "docker"
+	}
+	return o.Engine
+}
+
+// image 返回使用的镜像，未设置时为 DefaultContainerImage
+func (o *ContainerOptions) image() string {
+	if o.Image == "" {
+		return DefaultContainerImage
+	}
+	return o.Image
+}
+
+// GetContainerOptions 获取通过容器运行Composer的配置，没有使用容器时返回 nil
+func (c *Composer) GetContainerOptions() *ContainerOptions {
+	return c.container
+}
+
+// containerCommandLine 返回在容器中运行Composer命令的容器引擎和参数
+//
+// 工作目录挂载到 /app，COMPOSER_HOME 和缓存目录挂载到容器中并通过环境变量指定，
+// 实例和本次调用的环境变量以 -e NAME 的形式转发，值由容器引擎从自身环境中读取，不会出现在命令行中。
+func (c *Composer) containerCommandLine(args []string, env []string) (string, []string, error) {
+	workDir := c.workingDir
+	if workDir == "" {
+		var err error
+		if workDir, err = os.Getwd(); err != nil {
+			return "", nil, fmt.Errorf("%w: %v", ErrCommandExecution, err)
+		}
+	}
+	workDir, err := filepath.Abs(workDir)
+	if err != nil {
+		return "", nil, fmt.Errorf("%w: %v", ErrCommandExecution, err)
+	}
+
+	home, cache, err := c.containerComposerDirs()
+	if err != nil {
+		return "", nil, fmt.Errorf("%w: %v", ErrCommandExecution, err)
+	}
+	// 提前创建目录，避免容器引擎以 root 身份创建
+	for _, dir := range []string{home, cache} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return "", nil, fmt.Errorf("%w: 创建目录 %s 失败: %v", ErrCommandExecution, dir, err)
+		}
+	}
+
+	runArgs := []string{
+		"run", "--rm",
+		"-v", workDir + ":" + containerWorkDir,
+		"-w", containerWorkDir,
+		"-v", home + ":" + containerHomeDir,
+		"-e", string(EnvComposerHome) + "=" + containerHomeDir,
+		"-v", cache + ":" + containerCacheDir,
+		"-e", string(EnvComposerCacheDir) + "=" + containerCacheDir,
+	}
+	runArgs = append(runArgs, c.containerUserArgs()...)
+
+	forwarded := map[string]bool{string(EnvComposerHome): true, string(EnvComposerCacheDir): true}
+	for _, kv := range append(append([]string{}, c.env...), env...) {
+		name, _, _ := strings.Cut(kv, "=")
+		if name == "" || forwarded[envKey(name)] {
+			continue
+		}
+		forwarded[envKey(name)] = true
+		runArgs = append(runArgs, "-e", name)
+	}
+
+	runArgs = append(runArgs, c.container.ExtraArgs...)
+	runArgs = append(runArgs, c.container.image(), "composer")
+	return c.containerEngine, append(runArgs, args...), nil
+}
+
+// containerUserArgs 返回容器中运行用户的参数，使容器中创建的文件属于当前用户
+func (c *Composer) containerUserArgs() []string {
+	if c.container.User != "" {
+		return []string{"--user", c.container.User}
+	}
+	if strings.Contains(strings.ToLower(filepath.Base(c.container.engine())), "podman") {
+		return []string{"--userns=keep-id"}
+	}
+	if uid := os.Getuid(); uid >= 0 {
+		return []string{"--user", fmt.Sprintf("%d:%d", uid, os.Getgid())}
+	}
+	return nil
+}
+
+// containerComposerDirs 返回挂载到容器中的 COMPOSER_HOME 和缓存目录
+func (c *Composer) containerComposerDirs() (string, string, error) {
+	home := c.container.ComposerHome
+	if home == "" {
+		home = c.GetEnvVariable(EnvComposerHome)
+	}
+	if home == "" {
+		userHome, err := os.UserHomeDir()
+		if err != nil {
+			return "", "", err
+		}
+		home = filepath.Join(userHome, ".composer")
+	}
+
+	cache := c.container.CacheDir
+	if cache == "" {
+		cache = c.GetEnvVariable(EnvComposerCacheDir)
+	}
+	if cache == "" {
+		cache = filepath.Join(home, "cache")
+	}
+
+	home, err := filepath.Abs(home)
+	if err != nil {
+		return "", "", err
+	}
+	cache, err = filepath.Abs(cache)
+	return home, cache, err
+}
@@ -0,0 +1,210 @@
+package composer
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// fakeContainerEngine 创建一个记录参数的容器引擎脚本，返回脚本路径和参数记录文件
+func fakeContainerEngine(t *testing.T, name string) (string, string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("模拟的容器引擎只在类 Unix 系统上运行")
+	}
+	dir := t.TempDir()
+	argsFile := filepath.Join(dir, "args")
+	script := "#!/bin/sh\nfor arg in \"$@\"; do echo \"$arg\" >> '" + argsFile + "'; done\n" +
+		"echo 'Composer version 2.7.7 2024-06-10 22:11:12'\necho \"auth=$COMPOSER_AUTH\"\n"
+	engine := filepath.Join(dir, name)
+	if err := os.WriteFile(engine, []byte(script), 0755); err != nil {
+		t.Fatalf("创建模拟容器引擎失败: %v", err)
+	}
+	return engine, argsFile
+}
+
+// readArgs 读取并删除容器引擎记录的参数
+func readArgs(t *testing.T, path string) []string {
+	t.Helper()
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("读取参数记录失败: %v", err)
+	}
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	return strings.Split(strings.TrimSuffix(string(content), "\n"), "\n")
+}
+
+// containsArg 判断参数列表中是否包含 arg
+func containsArg(args []string, arg string) bool {
+	for _, a := range args {
+		if a == arg {
+			return true
+		}
+	}
+	return false
+}
+
+func TestContainerRunner(t *testing.T) {
+	ClearMockOutputs()
+	engine, argsFile := fakeContainerEngine(t, "docker")
+	workDir := t.TempDir()
+	home := filepath.Join(t.TempDir(), "home")
+
+	comp, err := New(Options{
+		WorkingDir:      workDir,
+		DefaultTimeout:  DefaultOptions().DefaultTimeout,
+		RequiredVersion: "^2.5",
+		Container: &ContainerOptions{
+			Engine:       engine,
+			Image:        "composer:2.7",
+			ComposerHome: home,
+			ExtraArgs:    []string{"--network", "host"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("创建Composer实例失败: %v", err)
+	}
+	if comp.containerEngine != engine {
+		t.Errorf("容器引擎错误: 期望 %s，实际 %s", engine, comp.containerEngine)
+	}
+	if comp.GetExecutablePath() != "" {
+		t.Errorf("通过容器运行时可执行文件路径应为空，实际 %s", comp.GetExecutablePath())
+	}
+	if !comp.IsInstalled() {
+		t.Error("找到容器引擎后 IsInstalled 应返回 true")
+	}
+	if comp.GetContainerOptions().Image != "composer:2.7" {
+		t.Errorf("镜像错误: %s", comp.GetContainerOptions().Image)
+	}
+	readArgs(t, argsFile) // 检查版本时的调用
+
+	comp.SetEnv([]string{`COMPOSER_AUTH={"github-oauth":{"github.com":"token"}}`, "COMPOSER_HOME=/ignored"})
+	output, err := comp.Run("install", "--no-dev")
+	if err != nil {
+		t.Fatalf("运行命令失败: %v", err)
+	}
+	// 环境变量的值由容器引擎从自身环境中读取
+	if !strings.Contains(output, `auth={"github-oauth":{"github.com":"token"}}`) {
+		t.Errorf("容器引擎没有收到 COMPOSER_AUTH: %s", output)
+	}
+
+	expected := []string{
+		"run", "--rm",
+		"-v", workDir + ":/app",
+		"-w", "/app",
+		"-v", home + ":/tmp/composer",
+		"-e", "COMPOSER_HOME=/tmp/composer",
+		"-v", filepath.Join(home, "cache") + ":/tmp/composer-cache",
+		"-e", "COMPOSER_CACHE_DIR=/tmp/composer-cache",
+		"--user", fmt.Sprintf("%d:%d", os.Getuid(), os.Getgid()),
+		"-e", "COMPOSER_AUTH",
+		"--network", "host",
+		"composer:2.7", "composer", "install", "--no-dev",
+	}
+	if args := readArgs(t, argsFile); !reflect.DeepEqual(args, expected) {
+		t.Errorf("容器引擎参数错误:\n期望 %q\n实际 %q", expected, args)
+	}
+	if info, err := os.Stat(filepath.Join(home, "cache")); err != nil || !info.IsDir() {
+		t.Errorf("缓存目录没有被创建: %v", err)
+	}
+}
+
+func TestContainerRunnerPodman(t *testing.T) {
+	ClearMockOutputs()
+	engine, argsFile := fakeContainerEngine(t, "podman")
+	home := t.TempDir()
+
+	comp, err := New(Options{
+		WorkingDir:     t.TempDir(),
+		DefaultTimeout: DefaultOptions().DefaultTimeout,
+		Container:      &ContainerOptions{Engine: engine, ComposerHome: home, CacheDir: filepath.Join(home, "c")},
+	})
+	if err != nil {
+		t.Fatalf("创建Composer实例失败: %v", err)
+	}
+
+	if _, err := comp.Run("about"); err != nil {
+		t.Fatalf("运行命令失败: %v", err)
+	}
+	args := readArgs(t, argsFile)
+	if !containsArg(args, "--userns=keep-id") {
+		t.Errorf("podman 应使用 --userns=keep-id: %q", args)
+	}
+	if !containsArg(args, filepath.Join(home, "c")+":/tmp/composer-cache") {
+		t.Errorf("缓存目录挂载错误: %q", args)
+	}
+	if tail := args[len(args)-3:]; !reflect.DeepEqual(tail, []string{DefaultContainerImage, "composer", "about"}) {
+		t.Errorf("镜像和命令错误: %q", tail)
+	}
+
+	comp.GetContainerOptions().User = "1000:1000"
+	if _, err := comp.Run("about"); err != nil {
+		t.Fatalf("运行命令失败: %v", err)
+	}
+	args = readArgs(t, argsFile)
+	if containsArg(args, "--userns=keep-id") || !containsArg(args, "1000:1000") {
+		t.Errorf("指定用户后参数错误: %q", args)
+	}
+}
+
+func TestContainerComposerHome(t *testing.T) {
+	ClearMockOutputs()
+	engine, argsFile := fakeContainerEngine(t, "docker")
+	home := filepath.Join(t.TempDir(), "home")
+
+	comp, err := New(Options{
+		WorkingDir:     t.TempDir(),
+		DefaultTimeout: DefaultOptions().DefaultTimeout,
+		Container:      &ContainerOptions{Engine: engine, ComposerHome: home},
+	})
+	if err != nil {
+		t.Fatalf("创建Composer实例失败: %v", err)
+	}
+
+	// 返回宿主机上的目录，不运行容器
+	got, err := comp.GetComposerHome()
+	if err != nil || got != home {
+		t.Errorf("期望 %s，实际 %q, %v", home, got, err)
+	}
+	if _, err := os.Stat(argsFile); err == nil {
+		t.Error("获取 COMPOSER_HOME 时不应运行容器")
+	}
+
+	// 认证配置从宿主机的 auth.json 读取
+	writeTestFile(t, filepath.Join(home, "auth.json"), `{"http-basic": {"repo.example.com": {"username": "u", "password": "p"}}}`)
+	auth, err := comp.LoadRepositoryAuth()
+	if err != nil {
+		t.Fatalf("加载认证配置失败: %v", err)
+	}
+	if username := auth.HTTPBasic["repo.example.com"].Username; username != "u" {
+		t.Errorf("期望用户名 u，实际 %q", username)
+	}
+
+	// 没有设置 ComposerHome 时使用 SetEnv 中的 COMPOSER_HOME
+	envHome := filepath.Join(t.TempDir(), "env-home")
+	comp.GetContainerOptions().ComposerHome = ""
+	comp.SetEnv([]string{"COMPOSER_HOME=" + envHome})
+	if got, err := comp.GetComposerHome(); err != nil || got != envHome {
+		t.Errorf("期望 %s，实际 %q, %v", envHome, got, err)
+	}
+}
+
+func TestContainerRunnerEngineNotFound(t *testing.T) {
+	_, err := New(Options{Container: &ContainerOptions{Engine: filepath.Join(t.TempDir(), "docker")}})
+	if !errors.Is(err, ErrComposerNotFound) {
+		t.Errorf("期望错误 %v，但得到 %v", ErrComposerNotFound, err)
+	}
+
+	// 容器中运行时不使用本机的可执行文件
+	_, err = New(Options{ExecutablePath: "/usr/local/bin/composer", Container: &ContainerOptions{}})
+	if !errors.Is(err, ErrInitFailed) {
+		t.Errorf("期望错误 %v，但得到 %v", ErrInitFailed, err)
+	}
+}
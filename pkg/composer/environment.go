@@ -3,6 +3,7 @@ package composer
 import (
 	"os"
 	"os/exec"
+	"runtime"
 	"strconv"
 	"strings"
 )
@@ -40,74 +41,107 @@ const (
 	EnvComposerHtaccessProtect EnvironmentVariable = "COMPOSER_HTACCESS_PROTECT"
 	// COMPOSER_MIRROR_PATH_REPOS 控制路径仓库的镜像策略
 	EnvComposerMirrorPathRepos EnvironmentVariable = "COMPOSER_MIRROR_PATH_REPOS"
+	// COMPOSER_AUTH 以 JSON 格式提供认证信息，格式与 auth.json 相同
+	EnvComposerAuth EnvironmentVariable = "COMPOSER_AUTH"
 )
 
+// 以下函数直接修改进程的环境变量，会影响所有 Composer 实例，并且与其他 goroutine 中执行的命令竞争。
+// Composer 的同名方法只修改实例的环境变量。
+
 // SetEnvVariable 设置 Composer 环境变量
+//
+// Deprecated: 会修改进程环境变量，请使用 Composer.SetEnvVariable。
 func SetEnvVariable(name EnvironmentVariable, value string) error {
 	return os.Setenv(string(name), value)
 }
 
 // GetEnvVariable 获取 Composer 环境变量值
+//
+// Deprecated: 请使用 Composer.GetEnvVariable 获取实例实际使用的值。
 func GetEnvVariable(name EnvironmentVariable) string {
 	return os.Getenv(string(name))
 }
 
 // SetProcessTimeout 设置 Composer 进程超时时间（秒）
+//
+// Deprecated: 会修改进程环境变量，请使用 Composer.SetProcessTimeout。
 func SetProcessTimeout(seconds int) error {
 	return os.Setenv(string(EnvComposerProcessTimeout), strconv.Itoa(seconds))
 }
 
 // EnableSuperuser 允许以 root 身份运行 Composer
+//
+// Deprecated: 会修改进程环境变量，请使用 Composer.EnableSuperuser。
 func EnableSuperuser() error {
 	return os.Setenv(string(EnvComposerAllowSuperuser), "1")
 }
 
 // DisableSuperuser 禁止以 root 身份运行 Composer
+//
+// Deprecated: 会修改进程环境变量，请使用 Composer.DisableSuperuser。
 func DisableSuperuser() error {
 	return os.Unsetenv(string(EnvComposerAllowSuperuser))
 }
 
 // SetMemoryLimit 设置 PHP 内存限制
+//
+// Deprecated: 会修改进程环境变量，请使用 Composer.SetMemoryLimit。
 func SetMemoryLimit(limit string) error {
 	return os.Setenv(string(EnvComposerMemoryLimit), limit)
 }
 
 // DisableInteraction 禁用交互提示
+//
+// Deprecated: 会修改进程环境变量，请使用 Composer.DisableInteraction。
 func DisableInteraction() error {
 	return os.Setenv(string(EnvComposerNoInteraction), "1")
 }
 
 // EnableInteraction 启用交互提示
+//
+// Deprecated: 会修改进程环境变量，请使用 Composer.EnableInteraction。
 func EnableInteraction() error {
 	return os.Unsetenv(string(EnvComposerNoInteraction))
 }
 
 // SetVendorDir 设置 vendor 目录位置
+//
+// Deprecated: 会修改进程环境变量，请使用 Composer.SetVendorDir。
 func SetVendorDir(path string) error {
 	return os.Setenv(string(EnvComposerVendorDir), path)
 }
 
 // SetBinDir 设置 bin 目录位置
+//
+// Deprecated: 会修改进程环境变量，请使用 Composer.SetBinDir。
 func SetBinDir(path string) error {
 	return os.Setenv(string(EnvComposerBinDir), path)
 }
 
 // SetCaFile 设置 CA 证书文件
+//
+// Deprecated: 会修改进程环境变量，请使用 Composer.SetCaFile。
 func SetCaFile(path string) error {
 	return os.Setenv(string(EnvComposerCafile), path)
 }
 
 // DisableDev 禁用开发依赖
+//
+// Deprecated: 会修改进程环境变量，请使用 Composer.DisableDev。
 func DisableDev() error {
 	return os.Setenv(string(EnvComposerNoDev), "1")
 }
 
 // EnableDev 启用开发依赖
+//
+// Deprecated: 会修改进程环境变量，请使用 Composer.EnableDev。
 func EnableDev() error {
 	return os.Unsetenv(string(EnvComposerNoDev))
 }
 
 // SetDiscardChanges 设置是否丢弃更改
+//
+// Deprecated: 会修改进程环境变量，请使用 Composer.SetDiscardChanges。
 func SetDiscardChanges(value string) error {
 	return os.Setenv(string(EnvComposerDiscardChanges), value)
 }
@@ -145,3 +179,213 @@ func (c *Composer) GetEnvironmentInfo() (map[string]string, error) {
 
 	return info, nil
 }
+
+// SetEnvVariable 设置实例的 Composer 环境变量
+//
+// 参数：
+//   - name: 环境变量名
+//   - value: 环境变量值
+//
+// 功能说明：
+//
+//	执行命令时的环境变量分为三层：继承的进程环境变量、实例的环境变量（SetEnv 和本方法等设置），
+//	以及 RunWithEnv 传入的单次调用环境变量，后面的层覆盖前面的层。
+//	该方法只修改实例的环境变量，不影响进程和其他 Composer 实例。
+//	设置方法不能与同一实例上执行的命令并发调用；需要在并发执行的命令中使用不同的值时，使用 RunWithEnv。
+//
+// 用法示例：
+//
+//	comp.SetEnvVariable(composer.EnvComposerMemoryLimit, "2G")
+func (c *Composer) SetEnvVariable(name EnvironmentVariable, value string) {
+	c.removeEnv(string(name))
+	c.env = append(c.env, string(name)+"="+value)
+}
+
+// UnsetEnvVariable 在实例中删除 Composer 环境变量，执行命令时也不会继承进程中的同名变量
+func (c *Composer) UnsetEnvVariable(name EnvironmentVariable) {
+	c.removeEnv(string(name))
+	c.unsetEnv = append(c.unsetEnv, string(name))
+}
+
+// GetEnvVariable 获取实例执行命令时使用的 Composer 环境变量值，不包括单次调用的环境变量
+func (c *Composer) GetEnvVariable(name EnvironmentVariable) string {
+	for _, unset := range c.unsetEnv {
+		if envKey(unset) == envKey(string(name)) {
+			return ""
+		}
+	}
+	for i := len(c.env) - 1; i >= 0; i-- {
+		if key, value, ok := strings.Cut(c.env[i], "="); ok && envKey(key) == envKey(string(name)) {
+			return value
+		}
+	}
+	return os.Getenv(string(name))
+}
+
+// SetComposerHome 设置实例的 Composer 主目录
+func (c *Composer) SetComposerHome(dir string) {
+	c.SetEnvVariable(EnvComposerHome, dir)
+}
+
+// SetCacheDir 设置实例的 Composer 缓存目录
+func (c *Composer) SetCacheDir(dir string) {
+	c.SetEnvVariable(EnvComposerCacheDir, dir)
+}
+
+// SetComposerAuth 设置实例的认证信息，内容为与 auth.json 格式相同的 JSON
+func (c *Composer) SetComposerAuth(auth string) {
+	c.SetEnvVariable(EnvComposerAuth, auth)
+}
+
+// SetProcessTimeout 设置实例的 Composer 进程超时时间（秒）
+func (c *Composer) SetProcessTimeout(seconds int) {
+	c.SetEnvVariable(EnvComposerProcessTimeout, strconv.Itoa(seconds))
+}
+
+// EnableSuperuser 允许实例以 root 身份运行 Composer
+func (c *Composer) EnableSuperuser() {
+	c.SetEnvVariable(EnvComposerAllowSuperuser, "1")
+}
+
+// DisableSuperuser 禁止实例以 root 身份运行 Composer
+func (c *Composer) DisableSuperuser() {
+	c.UnsetEnvVariable(EnvComposerAllowSuperuser)
+}
+
+// SetMemoryLimit 设置实例的 PHP 内存限制，例如 2G 或 -1
+func (c *Composer) SetMemoryLimit(limit string) {
+	c.SetEnvVariable(EnvComposerMemoryLimit, limit)
+}
+
+// DisableXdebugWarn 禁用实例的 XDebug 警告
+func (c *Composer) DisableXdebugWarn() {
+	c.SetEnvVariable(EnvComposerDisableXdebugWarn, "1")
+}
+
+// DisableInteraction 禁用实例的交互提示
+func (c *Composer) DisableInteraction() {
+	c.SetEnvVariable(EnvComposerNoInteraction, "1")
+}
+
+// EnableInteraction 启用实例的交互提示
+func (c *Composer) EnableInteraction() {
+	c.UnsetEnvVariable(EnvComposerNoInteraction)
+}
+
+// SetVendorDir 设置实例的 vendor 目录位置
+func (c *Composer) SetVendorDir(path string) {
+	c.SetEnvVariable(EnvComposerVendorDir, path)
+}
+
+// SetBinDir 设置实例的 bin 目录位置
+func (c *Composer) SetBinDir(path string) {
+	c.SetEnvVariable(EnvComposerBinDir, path)
+}
+
+// SetCaFile 设置实例的 CA 证书文件
+func (c *Composer) SetCaFile(path string) {
+	c.SetEnvVariable(EnvComposerCafile, path)
+}
+
+// DisableDev 禁止实例安装开发依赖
+func (c *Composer) DisableDev() {
+	c.SetEnvVariable(EnvComposerNoDev, "1")
+}
+
+// EnableDev 允许实例安装开发依赖
+func (c *Composer) EnableDev() {
+	c.UnsetEnvVariable(EnvComposerNoDev)
+}
+
+// SetDiscardChanges 设置实例处理依赖中修改的方式，可选 true、false 或 stash
+func (c *Composer) SetDiscardChanges(value string) {
+	c.SetEnvVariable(EnvComposerDiscardChanges, value)
+}
+
+// SetHtaccessProtect 设置实例是否在 Composer 主目录、缓存和数据目录中创建 .htaccess
+func (c *Composer) SetHtaccessProtect(enabled bool) {
+	c.SetEnvVariable(EnvComposerHtaccessProtect, envBool(enabled))
+}
+
+// SetMirrorPathRepos 设置实例是否复制而不是链接路径仓库
+func (c *Composer) SetMirrorPathRepos(enabled bool) {
+	c.SetEnvVariable(EnvComposerMirrorPathRepos, envBool(enabled))
+}
+
+// environ 返回执行命令使用的环境变量：继承的进程环境变量，依次被实例和单次调用的环境变量覆盖
+func (c *Composer) environ(callEnv []string) []string {
+	unset := make(map[string]bool, len(c.unsetEnv))
+	for _, name := range c.unsetEnv {
+		unset[envKey(name)] = true
+	}
+
+	var env []string
+	index := map[string]int{}
+	add := func(kv string) {
+		name, _, ok := strings.Cut(kv, "=")
+		if !ok {
+			return
+		}
+		if name == "" {
+			// Windows 中以 = 开头的驱动器目录变量
+			env = append(env, kv)
+			return
+		}
+		key := envKey(name)
+		if i, ok := index[key]; ok {
+			env[i] = kv
+			return
+		}
+		index[key] = len(env)
+		env = append(env, kv)
+	}
+
+	for _, kv := range os.Environ() {
+		if name, _, _ := strings.Cut(kv, "="); !unset[envKey(name)] {
+			add(kv)
+		}
+	}
+	for _, kv := range c.env {
+		add(kv)
+	}
+	for _, kv := range callEnv {
+		add(kv)
+	}
+	return env
+}
+
+// removeEnv 从实例的环境变量和删除列表中移除指定变量，不修改 SetEnv 传入的切片
+func (c *Composer) removeEnv(name string) {
+	key := envKey(name)
+	var env []string
+	for _, kv := range c.env {
+		if k, _, _ := strings.Cut(kv, "="); envKey(k) != key {
+			env = append(env, kv)
+		}
+	}
+	c.env = env
+
+	var unset []string
+	for _, n := range c.unsetEnv {
+		if envKey(n) != key {
+			unset = append(unset, n)
+		}
+	}
+	c.unsetEnv = unset
+}
+
+// envKey 返回用于比较的环境变量名，Windows 上不区分大小写
+func envKey(name string) string {
+	if runtime.GOOS == "windows" {
+		return strings.ToUpper(name)
+	}
+	return name
+}
+
+// envBool 返回布尔型环境变量的值
+func envBool(enabled bool) string {
+	if enabled {
+		return "1"
+	}
+	return "0"
+}
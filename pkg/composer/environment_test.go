@@ -0,0 +1,214 @@
+package composer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// envMap 将 KEY=VALUE 列表转换为映射
+func envMap(env []string) map[string]string {
+	m := map[string]string{}
+	for _, kv := range env {
+		if key, value, ok := strings.Cut(kv, "="); ok {
+			m[key] = value
+		}
+	}
+	return m
+}
+
+func TestInstanceEnvironmentLayers(t *testing.T) {
+	t.Setenv("GO_COMPOSER_INHERITED", "os")
+	t.Setenv(string(EnvComposerNoDev), "1")
+	t.Setenv(string(EnvComposerMemoryLimit), "512M")
+
+	c := &Composer{}
+	c.SetMemoryLimit("2G")
+	c.EnableDev()
+	c.DisableInteraction()
+	c.SetHtaccessProtect(false)
+	c.SetProcessTimeout(600)
+
+	env := envMap(c.environ([]string{"COMPOSER_PROCESS_TIMEOUT=0"}))
+	expected := map[string]string{
+		"GO_COMPOSER_INHERITED":     "os",
+		"PATH":                      os.Getenv("PATH"),
+		"COMPOSER_MEMORY_LIMIT":     "2G",
+		"COMPOSER_NO_INTERACTION":   "1",
+		"COMPOSER_HTACCESS_PROTECT": "0",
+		"COMPOSER_PROCESS_TIMEOUT":  "0",
+	}
+	for key, value := range expected {
+		if env[key] != value {
+			t.Errorf("%s: 期望 %q，实际 %q", key, value, env[key])
+		}
+	}
+	if value, ok := env["COMPOSER_NO_DEV"]; ok {
+		t.Errorf("COMPOSER_NO_DEV 应被删除，实际 %q", value)
+	}
+
+	lookups := map[EnvironmentVariable]string{
+		EnvComposerMemoryLimit:    "2G",
+		EnvComposerProcessTimeout: "600",
+		EnvComposerNoDev:          "",
+		"GO_COMPOSER_INHERITED":   "os",
+	}
+	for name, value := range lookups {
+		if got := c.GetEnvVariable(name); got != value {
+			t.Errorf("GetEnvVariable(%s): 期望 %q，实际 %q", name, value, got)
+		}
+	}
+
+	// 进程环境变量保持不变
+	if got := os.Getenv(string(EnvComposerMemoryLimit)); got != "512M" {
+		t.Errorf("进程的 COMPOSER_MEMORY_LIMIT 被修改为 %q", got)
+	}
+	if got := os.Getenv(string(EnvComposerNoDev)); got != "1" {
+		t.Errorf("进程的 COMPOSER_NO_DEV 被修改为 %q", got)
+	}
+	if _, ok := os.LookupEnv(string(EnvComposerNoInteraction)); ok {
+		t.Error("不应设置进程的 COMPOSER_NO_INTERACTION")
+	}
+
+	// 再次设置时取消删除，SetEnv 替换实例的所有设置
+	c.DisableDev()
+	if got := c.GetEnvVariable(EnvComposerNoDev); got != "1" {
+		t.Errorf("再次设置后 COMPOSER_NO_DEV 应为 1，实际 %q", got)
+	}
+	c.EnableDev()
+	c.SetEnv([]string{"COMPOSER_VENDOR_DIR=lib"})
+	env = envMap(c.environ(nil))
+	expected = map[string]string{
+		"COMPOSER_NO_DEV":       "1",
+		"COMPOSER_MEMORY_LIMIT": "512M",
+		"COMPOSER_VENDOR_DIR":   "lib",
+	}
+	for key, value := range expected {
+		if env[key] != value {
+			t.Errorf("SetEnv 后 %s: 期望 %q，实际 %q", key, value, env[key])
+		}
+	}
+}
+
+func TestSetEnvVariableKeepsCallerSlice(t *testing.T) {
+	input := []string{"COMPOSER_VENDOR_DIR=lib", "COMPOSER_BIN_DIR=bin"}
+	c := &Composer{}
+	c.SetEnv(input)
+	c.SetVendorDir("vendor")
+	c.SetBinDir("tools")
+
+	if expected := []string{"COMPOSER_VENDOR_DIR=lib", "COMPOSER_BIN_DIR=bin"}; !reflect.DeepEqual(input, expected) {
+		t.Errorf("调用者的切片被修改: %v", input)
+	}
+	if expected := []string{"COMPOSER_VENDOR_DIR=vendor", "COMPOSER_BIN_DIR=tools"}; !reflect.DeepEqual(c.GetEnv(), expected) {
+		t.Errorf("实例的环境变量错误: 期望 %v，实际 %v", expected, c.GetEnv())
+	}
+}
+
+func TestRunWithEnvInheritsProcessEnvironment(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("模拟的 composer 脚本只在类 Unix 系统上运行")
+	}
+	ClearMockOutputs()
+
+	executable := filepath.Join(t.TempDir(), "composer")
+	script := "#!/bin/sh\necho \"$HOME|$COMPOSER_MEMORY_LIMIT|$CALL_ID\"\n"
+	if err := os.WriteFile(executable, []byte(script), 0755); err != nil {
+		t.Fatalf("创建模拟Composer可执行文件失败: %v", err)
+	}
+
+	comp, err := New(Options{ExecutablePath: executable, DefaultTimeout: DefaultOptions().DefaultTimeout})
+	if err != nil {
+		t.Fatalf("创建Composer实例失败: %v", err)
+	}
+	comp.SetEnv([]string{"COMPOSER_MEMORY_LIMIT=1G"})
+
+	// SetEnv 不会清除 HOME 等继承的变量
+	output, err := comp.Run("about")
+	if err != nil {
+		t.Fatalf("运行命令失败: %v", err)
+	}
+	if expected := os.Getenv("HOME") + "|1G|"; strings.TrimSpace(output) != expected {
+		t.Errorf("期望 %q，实际 %q", expected, strings.TrimSpace(output))
+	}
+
+	// 单次调用的环境变量互不影响
+	var wg sync.WaitGroup
+	outputs := make([]string, 8)
+	for i := range outputs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			env := []string{fmt.Sprintf("CALL_ID=%d", i), "COMPOSER_MEMORY_LIMIT=-1"}
+			out, err := comp.RunWithEnv(context.Background(), env, "about")
+			if err != nil {
+				t.Errorf("第 %d 次调用失败: %v", i, err)
+			}
+			outputs[i] = strings.TrimSpace(out)
+		}(i)
+	}
+	wg.Wait()
+	for i, out := range outputs {
+		if expected := fmt.Sprintf("%s|-1|%d", os.Getenv("HOME"), i); out != expected {
+			t.Errorf("第 %d 次调用: 期望 %q，实际 %q", i, expected, out)
+		}
+	}
+	if got := comp.GetEnvVariable(EnvComposerMemoryLimit); got != "1G" {
+		t.Errorf("单次调用的环境变量不应修改实例，实际 %q", got)
+	}
+}
+
+func TestEnvironmentLookupsUseInstanceLayers(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv(string(EnvComposerHome), filepath.Join(t.TempDir(), "process-home"))
+	t.Setenv(string(EnvComposerAuth), `{"bearer": {"repo.example.com": "process-token"}}`)
+
+	c := &Composer{container: &ContainerOptions{}}
+
+	// 删除的变量不会从进程环境中读取
+	c.UnsetEnvVariable(EnvComposerHome)
+	dir, _, err := c.containerComposerDirs()
+	if err != nil {
+		t.Fatalf("获取容器目录失败: %v", err)
+	}
+	if expected := filepath.Join(home, ".composer"); dir != expected {
+		t.Errorf("期望 %s，实际 %s", expected, dir)
+	}
+
+	auth, err := c.LoadRepositoryAuth()
+	if err != nil {
+		t.Fatalf("加载认证配置失败: %v", err)
+	}
+	if token := auth.Bearer["repo.example.com"]; token != "process-token" {
+		t.Errorf("期望使用进程环境中的 COMPOSER_AUTH，实际 %q", token)
+	}
+
+	// 实例的 COMPOSER_AUTH 优先于进程环境中的值
+	c.SetComposerAuth(`{"bearer": {"repo.example.com": "instance-token"}}`)
+	auth, err = c.LoadRepositoryAuth()
+	if err != nil {
+		t.Fatalf("加载认证配置失败: %v", err)
+	}
+	if token := auth.Bearer["repo.example.com"]; token != "instance-token" {
+		t.Errorf("期望使用实例的 COMPOSER_AUTH，实际 %q", token)
+	}
+	if got := os.Getenv(string(EnvComposerAuth)); !strings.Contains(got, "process-token") {
+		t.Errorf("进程的 COMPOSER_AUTH 被修改为 %q", got)
+	}
+
+	c.UnsetEnvVariable(EnvComposerAuth)
+	auth, err = c.LoadRepositoryAuth()
+	if err != nil {
+		t.Fatalf("加载认证配置失败: %v", err)
+	}
+	if token, ok := auth.Bearer["repo.example.com"]; ok {
+		t.Errorf("删除 COMPOSER_AUTH 后不应使用进程环境中的值，实际 %q", token)
+	}
+}
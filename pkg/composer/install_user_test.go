@@ -0,0 +1,64 @@
+package composer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/scagogogo/go-composer-sdk/pkg/detector"
+	"github.com/scagogogo/go-composer-sdk/pkg/installer"
+)
+
+func TestNewAutoInstallUserScope(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("包装脚本测试只在类 Unix 系统上运行")
+	}
+
+	phar := "phar 2.7.7"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/download/2.7.7/composer.phar":
+			w.Write([]byte(phar))
+		case "/download/2.7.7/composer.phar.sha256sum":
+			sum := sha256.Sum256([]byte(phar))
+			w.Write([]byte(hex.EncodeToString(sum[:]) + "  composer.phar\n"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	root := t.TempDir()
+	t.Setenv("COMPOSER_PATH", "")
+	t.Setenv("PATH", root)
+
+	config := installer.DefaultUserConfig()
+	config.InstallPath = filepath.Join(root, "bin")
+	config.PharDir = filepath.Join(root, "share")
+	config.PharMirrorURL = server.URL
+	config.Version = "2.7.7"
+
+	// 检测器的默认路径中不包含自定义的安装目录，安装后仍然能找到
+	d := detector.NewDetector()
+	d.SetPossiblePaths(nil)
+
+	comp, err := New(Options{
+		AutoInstall: true,
+		Installer:   installer.NewInstaller(config),
+		Detector:    d,
+	})
+	if err != nil {
+		t.Fatalf("自动安装失败: %v", err)
+	}
+	if expected := filepath.Join(root, "bin", "composer"); comp.GetExecutablePath() != expected {
+		t.Errorf("可执行文件路径错误: 期望 %s，实际 %s", expected, comp.GetExecutablePath())
+	}
+	if _, err := os.Stat(filepath.Join(root, "share", "composer.phar")); err != nil {
+		t.Errorf("composer.phar 未安装到 PharDir: %v", err)
+	}
+}
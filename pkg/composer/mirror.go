@@ -0,0 +1,296 @@
+package composer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/scagogogo/go-composer-sdk/pkg/packagist"
+)
+
+// ErrMirrorFailed 表示部分依赖的分发包镜像失败
+var ErrMirrorFailed = errors.New("镜像依赖失败")
+
+// unsafeFileNameChars 匹配镜像文件名中需要替换的字符
+var unsafeFileNameChars = regexp.MustCompile(`[^A-Za-z0-9._-]`)
+
+// MirrorOptions 保存镜像依赖的选项
+type MirrorOptions struct {
+	// 同时下载的分发包数量，小于等于0时使用默认值4
+	Concurrency int
+	// 不镜像 packages-dev 中的包
+	NoDev bool
+	// 生成的 packages.json 中分发包地址的前缀，例如 https://mirror.example.com；
+	// 为空时使用 file:// 绝对路径
+	BaseURL string
+	// composer.lock 的路径，为空时使用工作目录下的 composer.lock
+	LockFile string
+	// 下载使用的仓库客户端，为空时通过 NewRepositoryClient 创建，自动应用 auth.json 中的认证信息
+	Client *packagist.Client
+}
+
+// DefaultMirrorOptions 返回默认的镜像选项
+func DefaultMirrorOptions() MirrorOptions {
+	return MirrorOptions{Concurrency: 4}
+}
+
+// MirroredPackage 表示一个已镜像的分发包
+type MirroredPackage struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	// File 分发包在镜像目录中的文件名
+	File string `json:"file"`
+	// Shasum 分发包的 SHA-1 校验和
+	Shasum string `json:"shasum"`
+	// Cached 镜像目录中已存在校验通过的文件，没有重新下载
+	Cached bool `json:"cached"`
+}
+
+// MirrorResult 表示镜像依赖的结果
+type MirrorResult struct {
+	// Packages 已镜像的分发包
+	Packages []MirroredPackage `json:"packages"`
+	// Skipped 没有分发包信息而被跳过的包，例如只有源码的 path 仓库包
+	Skipped []string `json:"skipped,omitempty"`
+	// PackagesJSON 生成的 packages.json 路径
+	PackagesJSON string `json:"packages_json"`
+}
+
+// MirrorDependencies 将 composer.lock 中全部依赖的分发包下载到本地镜像目录
+//
+// 参数：
+//   - destDir: 镜像目录
+//
+// 返回值：
+//   - *MirrorResult: 镜像结果
+//   - error: 如果读取锁文件、下载或校验失败，则返回相应的错误信息
+//
+// 功能说明：
+//
+//	该方法使用 DefaultMirrorOptions 调用 MirrorDependenciesWithOptions。
+//
+// 用法示例：
+//
+//	result, err := comp.MirrorDependencies("/srv/composer-mirror")
+//	if err != nil {
+//	    log.Fatalf("镜像依赖失败: %v", err)
+//	}
+//	fmt.Printf("已镜像 %d 个包\n", len(result.Packages))
+func (c *Composer) MirrorDependencies(destDir string) (*MirrorResult, error) {
+	return c.MirrorDependenciesWithOptions(destDir, DefaultMirrorOptions())
+}
+
+// MirrorDependenciesWithOptions 使用指定选项镜像 composer.lock 中的依赖
+//
+// 参数：
+//   - destDir: 镜像目录
+//   - options: 镜像选项
+//
+// 返回值：
+//   - *MirrorResult: 镜像结果，部分失败时包含已成功的包
+//   - error: 任一分发包下载或校验失败时返回包装了 ErrMirrorFailed 的错误，此时不会生成 packages.json
+//
+// 功能说明：
+//
+//	分发包由有限数量的 goroutine 并发下载，请求时按域名应用 auth.json 中的认证信息，
+//	锁文件中提供了 shasum 时会校验 SHA-1。镜像目录的布局为：
+//	  destDir/vendor-package-1.2.3.zip   分发包，可直接作为 artifact 类型仓库使用
+//	  destDir/packages.json              引用这些分发包的 composer 类型仓库元数据
+//	镜像目录中已存在且校验通过的文件不会重新下载，因此可以重复执行以增量更新镜像。
+//	在隔离网络中可以通过以下任一方式使用镜像：
+//	  {"type": "artifact", "url": "/srv/composer-mirror"}
+//	  {"type": "composer", "url": "https://mirror.example.com"}
+func (c *Composer) MirrorDependenciesWithOptions(destDir string, options MirrorOptions) (*MirrorResult, error) {
+	lockPath := options.LockFile
+	if lockPath == "" {
+		workDir := c.workingDir
+		if workDir == "" {
+			var err error
+			if workDir, err = os.Getwd(); err != nil {
+				return nil, err
+			}
+		}
+		lockPath = filepath.Join(workDir, "composer.lock")
+	}
+
+	entries, err := readLockEntries(lockPath, options.NoDev)
+	if err != nil {
+		return nil, err
+	}
+
+	client := options.Client
+	if client == nil {
+		if client, err = c.NewRepositoryClient(""); err != nil {
+			return nil, err
+		}
+	}
+
+	absDir, err := filepath.Abs(destDir)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(absDir, 0755); err != nil {
+		return nil, err
+	}
+
+	concurrency := options.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultMirrorOptions().Concurrency
+	}
+
+	result := &MirrorResult{}
+	var jobs []int
+	for i, entry := range entries {
+		if entry.version.Dist == nil || entry.version.Dist.URL == "" {
+			result.Skipped = append(result.Skipped, entry.version.Name)
+			continue
+		}
+		jobs = append(jobs, i)
+	}
+
+	mirrored := make([]*MirroredPackage, len(entries))
+	errs := make([]error, len(entries))
+
+	jobCh := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobCh {
+				mirrored[i], errs[i] = mirrorDist(client, absDir, entries[i].version)
+			}
+		}()
+	}
+	for _, i := range jobs {
+		jobCh <- i
+	}
+	close(jobCh)
+	wg.Wait()
+
+	var failures []error
+	for _, i := range jobs {
+		if errs[i] != nil {
+			failures = append(failures, fmt.Errorf("%s %s: %w", entries[i].version.Name, entries[i].version.Version, errs[i]))
+			continue
+		}
+		result.Packages = append(result.Packages, *mirrored[i])
+	}
+	if len(failures) > 0 {
+		return result, fmt.Errorf("%w: %w", ErrMirrorFailed, errors.Join(failures...))
+	}
+
+	packages := make(map[string]map[string]map[string]interface{})
+	for _, i := range jobs {
+		entry, pkg := entries[i], mirrored[i]
+		distURL := "file://" + filepath.ToSlash(filepath.Join(absDir, pkg.File))
+		if options.BaseURL != "" {
+			distURL = strings.TrimRight(options.BaseURL, "/") + "/" + pkg.File
+		}
+		entry.raw["dist"] = map[string]interface{}{
+			"type":      entry.version.Dist.Type,
+			"url":       distURL,
+			"reference": entry.version.Dist.Reference,
+			"shasum":    pkg.Shasum,
+		}
+		if packages[pkg.Name] == nil {
+			packages[pkg.Name] = make(map[string]map[string]interface{})
+		}
+		packages[pkg.Name][pkg.Version] = entry.raw
+	}
+
+	content, err := json.MarshalIndent(map[string]interface{}{"packages": packages}, "", "    ")
+	if err != nil {
+		return nil, err
+	}
+	result.PackagesJSON = filepath.Join(absDir, "packages.json")
+	if err := os.WriteFile(result.PackagesJSON, content, 0644); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// lockEntry 表示 composer.lock 中的一个包，同时保留原始字段
+type lockEntry struct {
+	raw     map[string]interface{}
+	version packagist.Version
+}
+
+// readLockEntries 读取 composer.lock 中的包
+func readLockEntries(path string, noDev bool) ([]lockEntry, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var lock struct {
+		Packages    []json.RawMessage `json:"packages"`
+		PackagesDev []json.RawMessage `json:"packages-dev"`
+	}
+	if err := json.Unmarshal(content, &lock); err != nil {
+		return nil, fmt.Errorf("解析 %s 失败: %w", path, err)
+	}
+
+	raws := lock.Packages
+	if !noDev {
+		raws = append(raws, lock.PackagesDev...)
+	}
+
+	entries := make([]lockEntry, 0, len(raws))
+	for _, raw := range raws {
+		var entry lockEntry
+		if err := json.Unmarshal(raw, &entry.raw); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(raw, &entry.version); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// mirrorDist 下载单个分发包，镜像目录中已有校验通过的文件时直接复用
+func mirrorDist(client *packagist.Client, dir string, version packagist.Version) (*MirroredPackage, error) {
+	file := mirrorFileName(version)
+	path := filepath.Join(dir, file)
+	pkg := &MirroredPackage{Name: version.Name, Version: version.Version, File: file}
+
+	if _, err := os.Stat(path); err == nil {
+		if sum, err := packagist.FileSHA1(path); err == nil && (version.Dist.Shasum == "" || strings.EqualFold(sum, version.Dist.Shasum)) {
+			pkg.Shasum = sum
+			pkg.Cached = true
+			return pkg, nil
+		}
+	}
+
+	sum, err := client.DownloadDistFile(version.Dist, path)
+	if err != nil {
+		return nil, err
+	}
+	pkg.Shasum = sum
+	return pkg, nil
+}
+
+// mirrorFileName 返回分发包在镜像目录中的文件名，例如 monolog-monolog-3.5.0.zip
+func mirrorFileName(version packagist.Version) string {
+	name := strings.ReplaceAll(strings.ToLower(version.Name), "/", "-")
+	base := unsafeFileNameChars.ReplaceAllString(name+"-"+version.Version, "-")
+
+	ext := version.Dist.Type
+	switch version.Dist.Type {
+	case "", "zip":
+		ext = "zip"
+	case "gzip":
+		ext = "gz"
+	case "xz":
+		ext = "tar.xz"
+	}
+	return base + "." + ext
+}
@@ -0,0 +1,145 @@
+package composer
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/scagogogo/go-composer-sdk/pkg/packagist"
+)
+
+func sha1Hex(data string) string {
+	sum := sha1.Sum([]byte(data))
+	return hex.EncodeToString(sum[:])
+}
+
+// setupMirrorProject 创建包含 composer.lock 和 auth.json 的测试项目
+func setupMirrorProject(t *testing.T, serverURL string, host string, logShasum string) (*Composer, string) {
+	t.Helper()
+	ClearMockOutputs()
+	t.Cleanup(ClearMockOutputs)
+
+	home := t.TempDir()
+	project := t.TempDir()
+	SetupMockOutput("config --global home", home, nil)
+
+	lock := fmt.Sprintf(`{
+		"packages": [
+			{"name": "psr/log", "version": "3.0.0", "dist": {"type": "zip", "url": "%[1]s/dists/psr-log.zip", "reference": "abc", "shasum": "%[2]s"}, "autoload": {"psr-4": {"Psr\\Log\\": "src"}}},
+			{"name": "acme/local", "version": "dev-main", "source": {"type": "path", "url": "../local"}}
+		],
+		"packages-dev": [
+			{"name": "acme/tool", "version": "1.0.0", "dist": {"type": "zip", "url": "%[1]s/dists/acme-tool.zip", "reference": "def", "shasum": ""}}
+		]
+	}`, serverURL, logShasum)
+	_ = os.WriteFile(filepath.Join(project, "composer.lock"), []byte(lock), 0644)
+	_ = os.WriteFile(filepath.Join(project, "auth.json"), []byte(fmt.Sprintf(`{"bearer": {%q: "mirror-token"}}`, host)), 0600)
+
+	composer, err := New(Options{ExecutablePath: "/path/to/composer", WorkingDir: project})
+	if err != nil {
+		t.Fatalf("创建Composer实例失败: %v", err)
+	}
+	return composer, project
+}
+
+func newDistServer(t *testing.T, downloads *int32) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer mirror-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		atomic.AddInt32(downloads, 1)
+		switch r.URL.Path {
+		case "/dists/psr-log.zip":
+			_, _ = w.Write([]byte("psr-log-zip"))
+		case "/dists/acme-tool.zip":
+			_, _ = w.Write([]byte("acme-tool-zip"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestMirrorDependencies(t *testing.T) {
+	var downloads int32
+	server := newDistServer(t, &downloads)
+	host := server.Listener.Addr().String()
+	composer, _ := setupMirrorProject(t, server.URL, host, sha1Hex("psr-log-zip"))
+
+	dest := t.TempDir()
+	options := DefaultMirrorOptions()
+	options.BaseURL = "https://mirror.example.com/"
+	result, err := composer.MirrorDependenciesWithOptions(dest, options)
+	if err != nil {
+		t.Fatalf("镜像依赖失败: %v", err)
+	}
+
+	if len(result.Packages) != 2 || len(result.Skipped) != 1 || result.Skipped[0] != "acme/local" {
+		t.Fatalf("镜像结果不正确: %+v", result)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dest, "psr-log-3.0.0.zip")); string(data) != "psr-log-zip" {
+		t.Errorf("分发包内容不正确: %q", data)
+	}
+
+	content, err := os.ReadFile(result.PackagesJSON)
+	if err != nil {
+		t.Fatalf("读取packages.json失败: %v", err)
+	}
+	var repo struct {
+		Packages map[string]map[string]packagist.Version `json:"packages"`
+	}
+	if err := json.Unmarshal(content, &repo); err != nil {
+		t.Fatalf("解析packages.json失败: %v", err)
+	}
+	tool := repo.Packages["acme/tool"]["1.0.0"]
+	if tool.Dist == nil || tool.Dist.URL != "https://mirror.example.com/acme-tool-1.0.0.zip" || tool.Dist.Shasum != sha1Hex("acme-tool-zip") {
+		t.Errorf("packages.json中的分发包信息不正确: %+v", tool.Dist)
+	}
+	if len(repo.Packages["psr/log"]["3.0.0"].Autoload) == 0 {
+		t.Error("packages.json应保留锁文件中的其他字段")
+	}
+
+	// 再次镜像时复用已存在的文件
+	before := atomic.LoadInt32(&downloads)
+	result, err = composer.MirrorDependencies(dest)
+	if err != nil {
+		t.Fatalf("再次镜像失败: %v", err)
+	}
+	if atomic.LoadInt32(&downloads) != before || !result.Packages[0].Cached {
+		t.Errorf("已存在且校验通过的分发包不应重新下载: %+v", result.Packages)
+	}
+}
+
+func TestMirrorDependenciesChecksumMismatch(t *testing.T) {
+	var downloads int32
+	server := newDistServer(t, &downloads)
+	composer, _ := setupMirrorProject(t, server.URL, server.Listener.Addr().String(), sha1Hex("other"))
+
+	dest := t.TempDir()
+	options := DefaultMirrorOptions()
+	options.NoDev = true
+	result, err := composer.MirrorDependenciesWithOptions(dest, options)
+	if !errors.Is(err, ErrMirrorFailed) || !errors.Is(err, packagist.ErrChecksumMismatch) {
+		t.Fatalf("校验和不匹配时应返回ErrChecksumMismatch，实际为%v", err)
+	}
+	if len(result.Packages) != 0 {
+		t.Errorf("NoDev时不应镜像开发依赖: %+v", result.Packages)
+	}
+	if _, err := os.Stat(filepath.Join(dest, "psr-log-3.0.0.zip")); !os.IsNotExist(err) {
+		t.Error("校验失败的分发包不应写入镜像目录")
+	}
+	if _, err := os.Stat(filepath.Join(dest, "packages.json")); !os.IsNotExist(err) {
+		t.Error("镜像失败时不应生成packages.json")
+	}
+}
@@ -0,0 +1,254 @@
+package composer
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// LatestStatus 表示 composer outdated 对最新版本的分类
+type LatestStatus string
+
+// composer outdated 输出中 latest-status 的取值
+const (
+	// LatestStatusSemverSafe 最新版本满足当前约束，可以直接更新
+	LatestStatusSemverSafe LatestStatus = "semver-safe-update"
+	// LatestStatusUpdatePossible 最新版本不满足当前约束，需要修改约束才能更新
+	LatestStatusUpdatePossible LatestStatus = "update-possible"
+	// LatestStatusUpToDate 已是最新版本
+	LatestStatusUpToDate LatestStatus = "up-to-date"
+)
+
+// UpdateLevel 表示从当前版本升级到最新版本的语义化版本级别
+type UpdateLevel string
+
+// 语义化版本升级级别
+const (
+	// UpdateLevelMajor 主版本升级
+	UpdateLevelMajor UpdateLevel = "major"
+	// UpdateLevelMinor 次版本升级
+	UpdateLevelMinor UpdateLevel = "minor"
+	// UpdateLevelPatch 补丁版本升级
+	UpdateLevelPatch UpdateLevel = "patch"
+	// UpdateLevelNone 版本相同，无需升级
+	UpdateLevelNone UpdateLevel = "none"
+	// UpdateLevelUnknown 无法比较的版本（如 dev-master）
+	UpdateLevelUnknown UpdateLevel = "unknown"
+)
+
+// OutdatedOptions 定义 composer outdated 的选项
+type OutdatedOptions struct {
+	// Direct 只显示 composer.json 中直接声明的依赖（--direct）
+	Direct bool
+	// All 同时显示已是最新版本的包（--all）
+	All bool
+	// MajorOnly 只显示有主版本更新的包（--major-only）
+	MajorOnly bool
+	// MinorOnly 只显示有次版本更新的包（--minor-only）
+	MinorOnly bool
+	// PatchOnly 只显示有补丁版本更新的包（--patch-only）
+	PatchOnly bool
+	// Strict 存在过时的包时以非零退出码结束（--strict）
+	Strict bool
+	// Locked 基于 composer.lock 而非已安装的包（--locked）
+	Locked bool
+	// NoDev 不包含开发依赖（--no-dev）
+	NoDev bool
+	// Ignore 忽略的包名列表（--ignore）
+	Ignore []string
+	// Packages 只检查指定的包
+	Packages []string
+}
+
+// OutdatedPackage 表示 composer outdated 输出中的一个包
+type OutdatedPackage struct {
+	Name              string       `json:"name"`
+	Current           string       `json:"version"`
+	Latest            string       `json:"latest"`
+	LatestStatus      LatestStatus `json:"latest-status"`
+	Direct            bool         `json:"direct-dependency"`
+	Description       string       `json:"description"`
+	ReleaseAge        string       `json:"release-age"`
+	ReleaseDate       string       `json:"release-date"`
+	LatestReleaseDate string       `json:"latest-release-date"`
+	// Abandoned 表示包是否已被放弃维护
+	Abandoned bool `json:"-"`
+	// Replacement 已放弃的包推荐使用的替代包，可能为空
+	Replacement string `json:"-"`
+}
+
+// UnmarshalJSON 解析 composer outdated 的包信息
+//
+// Composer 使用 false、true 或替代包名表示 abandoned 字段，这里统一拆分为
+// Abandoned 和 Replacement 两个字段。
+func (p *OutdatedPackage) UnmarshalJSON(data []byte) error {
+	type plain OutdatedPackage
+	aux := struct {
+		*plain
+		Abandoned interface{} `json:"abandoned"`
+	}{plain: (*plain)(p)}
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	p.Abandoned, p.Replacement = parseAbandoned(aux.Abandoned)
+
+	return nil
+}
+
+// UpdateLevel 计算从当前版本升级到最新版本的级别
+func (p OutdatedPackage) UpdateLevel() UpdateLevel {
+	return CompareUpdateLevel(p.Current, p.Latest)
+}
+
+// outdatedResult 表示 composer outdated --format=json 的完整输出
+type outdatedResult struct {
+	Installed []OutdatedPackage `json:"installed"`
+	Locked    []OutdatedPackage `json:"locked"`
+}
+
+// Outdated 获取结构化的过时包列表
+//
+// 参数：
+//   - opts: 过时检查选项
+//
+// 返回值：
+//   - []OutdatedPackage: 过时包列表
+//   - error: 如果执行命令或解析输出失败，则返回相应的错误信息
+//
+// 功能说明：
+//
+//	该方法执行`composer outdated --format=json`并将输出解析为结构体。
+//	启用 Strict 时Composer会以非零退出码结束，只要输出仍是合法JSON就会正常解析。
+//
+// 用法示例：
+//
+//	packages, err := comp.Outdated(composer.OutdatedOptions{Direct: true})
+//	if err != nil {
+//	    log.Fatalf("获取过时包失败: %v", err)
+//	}
+//	for level, pkgs := range composer.GroupOutdatedByLevel(packages) {
+//	    fmt.Printf("%s: %d 个包\n", level, len(pkgs))
+//	}
+func (c *Composer) Outdated(opts OutdatedOptions) ([]OutdatedPackage, error) {
+	args := []string{"outdated", "--format=json"}
+
+	flags := []struct {
+		enabled bool
+		flag    string
+	}{
+		{opts.Direct, "--direct"},
+		{opts.All, "--all"},
+		{opts.MajorOnly, "--major-only"},
+		{opts.MinorOnly, "--minor-only"},
+		{opts.PatchOnly, "--patch-only"},
+		{opts.Strict, "--strict"},
+		{opts.Locked, "--locked"},
+		{opts.NoDev, "--no-dev"},
+	}
+	for _, f := range flags {
+		if f.enabled {
+			args = append(args, f.flag)
+		}
+	}
+
+	for _, ignore := range opts.Ignore {
+		args = append(args, "--ignore="+ignore)
+	}
+
+	args = append(args, opts.Packages...)
+
+	output, runErr := c.Run(args...)
+
+	var result outdatedResult
+	if err := json.Unmarshal([]byte(output), &result); err != nil {
+		if runErr != nil {
+			return nil, runErr
+		}
+		return nil, fmt.Errorf("解析outdated输出失败: %w", err)
+	}
+
+	if opts.Locked {
+		return result.Locked, nil
+	}
+	return result.Installed, nil
+}
+
+// GroupOutdatedByLevel 按语义化版本升级级别对过时包分组
+//
+// 参数：
+//   - packages: 过时包列表
+//
+// 返回值：
+//   - map[UpdateLevel][]OutdatedPackage: 以升级级别为键的分组结果
+func GroupOutdatedByLevel(packages []OutdatedPackage) map[UpdateLevel][]OutdatedPackage {
+	groups := make(map[UpdateLevel][]OutdatedPackage)
+	for _, pkg := range packages {
+		level := pkg.UpdateLevel()
+		groups[level] = append(groups[level], pkg)
+	}
+	return groups
+}
+
+// FilterOutdatedByStatus 筛选指定 latest-status 的过时包
+func FilterOutdatedByStatus(packages []OutdatedPackage, status LatestStatus) []OutdatedPackage {
+	var filtered []OutdatedPackage
+	for _, pkg := range packages {
+		if pkg.LatestStatus == status {
+			filtered = append(filtered, pkg)
+		}
+	}
+	return filtered
+}
+
+// CompareUpdateLevel 比较两个版本号，返回升级级别
+//
+// 版本号可以带有前缀"v"和稳定性后缀（如 1.2.3-beta1），只比较数字部分。
+// 任一版本无法解析为数字版本号（例如 dev-master）时返回 UpdateLevelUnknown。
+func CompareUpdateLevel(current, latest string) UpdateLevel {
+	cur, ok1 := parseNumericVersion(current)
+	lat, ok2 := parseNumericVersion(latest)
+	if !ok1 || !ok2 {
+		return UpdateLevelUnknown
+	}
+
+	switch {
+	case cur[0] != lat[0]:
+		return UpdateLevelMajor
+	case cur[1] != lat[1]:
+		return UpdateLevelMinor
+	case cur[2] != lat[2] || cur[3] != lat[3]:
+		return UpdateLevelPatch
+	default:
+		return UpdateLevelNone
+	}
+}
+
+// parseNumericVersion 将版本号解析为四段数字，缺失的部分补0
+func parseNumericVersion(version string) ([4]int, bool) {
+	var parts [4]int
+
+	v := strings.TrimPrefix(strings.TrimPrefix(strings.TrimSpace(version), "v"), "V")
+	if i := strings.IndexAny(v, "-+@ "); i >= 0 {
+		v = v[:i]
+	}
+	if v == "" {
+		return parts, false
+	}
+
+	segments := strings.Split(v, ".")
+	if len(segments) > 4 {
+		return parts, false
+	}
+	for i, seg := range segments {
+		n, err := strconv.Atoi(seg)
+		if err != nil {
+			return parts, false
+		}
+		parts[i] = n
+	}
+
+	return parts, true
+}
@@ -0,0 +1,117 @@
+package composer
+
+import (
+	"errors"
+	"testing"
+)
+
+const outdatedTestOutput = `{
+	"installed": [
+		{"name": "monolog/monolog", "direct-dependency": true, "version": "2.9.1", "latest": "3.5.0", "latest-status": "update-possible", "release-age": "1 year old", "description": "Logging", "abandoned": false},
+		{"name": "symfony/console", "direct-dependency": true, "version": "v6.3.0", "latest": "v6.4.2", "latest-status": "semver-safe-update", "release-age": "5 months old", "abandoned": false},
+		{"name": "guzzlehttp/psr7", "direct-dependency": false, "version": "2.6.1", "latest": "2.6.2", "latest-status": "semver-safe-update", "abandoned": false},
+		{"name": "swiftmailer/swiftmailer", "direct-dependency": true, "version": "6.3.0", "latest": "6.3.0", "latest-status": "up-to-date", "abandoned": "symfony/mailer"},
+		{"name": "vendor/dev", "direct-dependency": false, "version": "dev-main 1a2b3c", "latest": "dev-main 4d5e6f", "latest-status": "semver-safe-update", "abandoned": true}
+	]
+}`
+
+func TestOutdated(t *testing.T) {
+	ClearMockOutputs()
+	defer ClearMockOutputs()
+
+	SetupMockOutput("outdated --format=json --direct --ignore=vendor/skip", outdatedTestOutput, nil)
+
+	composer, err := New(Options{ExecutablePath: "/path/to/composer"})
+	if err != nil {
+		t.Fatalf("创建Composer实例失败: %v", err)
+	}
+
+	packages, err := composer.Outdated(OutdatedOptions{Direct: true, Ignore: []string{"vendor/skip"}})
+	if err != nil {
+		t.Fatalf("Outdated执行失败: %v", err)
+	}
+	if len(packages) != 5 {
+		t.Fatalf("应解析出5个包，实际为%d", len(packages))
+	}
+
+	monolog := packages[0]
+	if monolog.Current != "2.9.1" || monolog.Latest != "3.5.0" || !monolog.Direct || monolog.ReleaseAge != "1 year old" {
+		t.Errorf("monolog解析不正确: %+v", monolog)
+	}
+	if monolog.LatestStatus != LatestStatusUpdatePossible {
+		t.Errorf("monolog的latest-status应为update-possible，实际为%s", monolog.LatestStatus)
+	}
+
+	swift := packages[3]
+	if !swift.Abandoned || swift.Replacement != "symfony/mailer" {
+		t.Errorf("swiftmailer应被标记为放弃并有替代包，实际为%+v", swift)
+	}
+	if !packages[4].Abandoned || packages[4].Replacement != "" {
+		t.Errorf("abandoned为true时应无替代包，实际为%+v", packages[4])
+	}
+
+	groups := GroupOutdatedByLevel(packages)
+	expected := map[UpdateLevel]string{
+		UpdateLevelMajor:   "monolog/monolog",
+		UpdateLevelMinor:   "symfony/console",
+		UpdateLevelPatch:   "guzzlehttp/psr7",
+		UpdateLevelNone:    "swiftmailer/swiftmailer",
+		UpdateLevelUnknown: "vendor/dev",
+	}
+	for level, name := range expected {
+		if len(groups[level]) != 1 || groups[level][0].Name != name {
+			t.Errorf("%s分组应只包含%s，实际为%+v", level, name, groups[level])
+		}
+	}
+
+	safe := FilterOutdatedByStatus(packages, LatestStatusSemverSafe)
+	if len(safe) != 3 {
+		t.Errorf("应有3个semver-safe-update的包，实际为%d", len(safe))
+	}
+}
+
+func TestOutdatedStrictExitCode(t *testing.T) {
+	ClearMockOutputs()
+	defer ClearMockOutputs()
+
+	// --strict 在有过时包时会返回非零退出码
+	SetupMockOutput("outdated --format=json --strict", outdatedTestOutput, errors.New("exit status 1"))
+
+	composer, err := New(Options{ExecutablePath: "/path/to/composer"})
+	if err != nil {
+		t.Fatalf("创建Composer实例失败: %v", err)
+	}
+
+	packages, err := composer.Outdated(OutdatedOptions{Strict: true})
+	if err != nil {
+		t.Fatalf("输出为合法JSON时不应返回错误: %v", err)
+	}
+	if len(packages) != 5 {
+		t.Errorf("应解析出5个包，实际为%d", len(packages))
+	}
+
+	SetupMockOutput("outdated --format=json --strict", "", errors.New("command failed"))
+	if _, err := composer.Outdated(OutdatedOptions{Strict: true}); err == nil {
+		t.Error("命令失败且无输出时应返回错误")
+	}
+}
+
+func TestCompareUpdateLevel(t *testing.T) {
+	tests := []struct {
+		current, latest string
+		want            UpdateLevel
+	}{
+		{"1.0.0", "2.0.0", UpdateLevelMajor},
+		{"v1.2.0", "v1.3.0", UpdateLevelMinor},
+		{"1.2.3", "1.2.4", UpdateLevelPatch},
+		{"1.2", "1.2.0.1", UpdateLevelPatch},
+		{"1.2.3-beta1", "1.2.3", UpdateLevelNone},
+		{"dev-master", "1.0.0", UpdateLevelUnknown},
+	}
+
+	for _, tt := range tests {
+		if got := CompareUpdateLevel(tt.current, tt.latest); got != tt.want {
+			t.Errorf("CompareUpdateLevel(%q, %q) = %s，期望为%s", tt.current, tt.latest, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package composer
+
+import (
+	"fmt"
+
+	"github.com/scagogogo/go-composer-sdk/pkg/detector"
+)
+
+// SetPHPBinary 设置运行Composer使用的PHP可执行文件
+//
+// 参数：
+//   - path: PHP可执行文件的路径，为空时恢复为直接运行Composer可执行文件
+//
+// 功能说明：
+//
+//	设置后Run等方法以 "<php> <composer.phar> <args>" 的方式运行Composer，
+//	composer.phar 的路径由Composer可执行文件确定：可执行文件本身是PHP脚本时直接使用，
+//	是包装脚本（例如安装器创建的 composer 或 composer.bat）时使用其中引用的 .phar。
+//
+// 用法示例：
+//
+//	runtimes, _ := detector.DetectPHP()
+//	for _, php := range runtimes {
+//	    if strings.HasPrefix(php.Version, "8.2.") {
+//	        comp.SetPHPBinary(php.Path)
+//	    }
+//	}
+func (c *Composer) SetPHPBinary(path string) {
+	c.phpBinary = path
+}
+
+// GetPHPBinary 获取运行Composer使用的PHP可执行文件，未设置时返回空字符串
+func (c *Composer) GetPHPBinary() string {
+	return c.phpBinary
+}
+
+// GetPHPRuntime 获取运行Composer使用的PHP的版本、SAPI、php.ini和扩展
+//
+// 返回值：
+//   - *detector.PHPRuntime: PHP运行时信息
+//   - error: 如果找不到或无法运行PHP，则返回相应的错误信息
+//
+// 功能说明：
+//
+//	未设置PHP可执行文件时检查 detector.FindPHPBinaries 找到的第一个PHP，通常是PATH中的php。
+func (c *Composer) GetPHPRuntime() (*detector.PHPRuntime, error) {
+	path := c.phpBinary
+	if path == "" {
+		binaries := detector.FindPHPBinaries()
+		if len(binaries) == 0 {
+			return nil, detector.ErrPHPNotFound
+		}
+		path = binaries[0]
+	}
+	return detector.InspectPHP(path)
+}
+
+// commandLine 返回运行Composer命令的可执行文件和参数，env 是本次调用的环境变量
+func (c *Composer) commandLine(args []string, env []string) (string, []string, error) {
+	if c.container != nil {
+		return c.containerCommandLine(args, env)
+	}
+	if c.phpBinary == "" {
+		return c.executablePath, args, nil
+	}
+
+	phar, err := detector.ResolvePhar(c.executablePath)
+	if err != nil {
+		return "", nil, fmt.Errorf("%w: %v", ErrCommandExecution, err)
+	}
+	return c.phpBinary, append([]string{phar}, args...), nil
+}
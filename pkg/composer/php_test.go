@@ -0,0 +1,57 @@
+package composer
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestRunWithPHPBinary(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("模拟的 php 脚本只在类 Unix 系统上运行")
+	}
+
+	dir := t.TempDir()
+	php := filepath.Join(dir, "php8.2")
+	pharPath := filepath.Join(dir, "composer.phar")
+	wrapper := filepath.Join(dir, "composer")
+	files := map[string]string{
+		php:      "#!/bin/sh\necho \"php8.2 $@\"\n",
+		pharPath: "phar",
+		wrapper:  "#!/bin/sh\nexec php \"" + pharPath + "\" \"$@\"\n",
+	}
+	for file, content := range files {
+		if err := os.WriteFile(file, []byte(content), 0755); err != nil {
+			t.Fatalf("创建文件失败: %v", err)
+		}
+	}
+
+	comp, err := New(Options{ExecutablePath: wrapper, PHPBinary: php, DefaultTimeout: DefaultOptions().DefaultTimeout})
+	if err != nil {
+		t.Fatalf("创建Composer实例失败: %v", err)
+	}
+	if comp.GetPHPBinary() != php {
+		t.Errorf("PHP 可执行文件错误: 期望 %s，实际 %s", php, comp.GetPHPBinary())
+	}
+
+	output, err := comp.Run("php-binary-test", "--no-ansi")
+	if err != nil {
+		t.Fatalf("运行命令失败: %v", err)
+	}
+	if expected := "php8.2 " + pharPath + " php-binary-test --no-ansi"; strings.TrimSpace(output) != expected {
+		t.Errorf("输出错误: 期望 %q，实际 %q", expected, strings.TrimSpace(output))
+	}
+
+	// 清除后直接运行可执行文件
+	comp.SetPHPBinary("")
+	name, args, err := comp.commandLine([]string{"about"}, nil)
+	if err != nil {
+		t.Fatalf("生成命令行失败: %v", err)
+	}
+	if name != wrapper || !reflect.DeepEqual(args, []string{"about"}) {
+		t.Errorf("命令行错误: %s %v", name, args)
+	}
+}
@@ -0,0 +1,80 @@
+package composer
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/scagogogo/go-composer-sdk/pkg/packagist"
+)
+
+// LoadRepositoryAuth 加载访问私有仓库所需的认证信息
+//
+// 返回值：
+//   - *packagist.Auth: 合并后的认证信息
+//   - error: 如果读取或解析认证文件失败，则返回相应的错误信息
+//
+// 功能说明：
+//
+//	按照 Composer 的优先级从低到高合并以下来源：
+//	  1. COMPOSER_HOME 下的全局 auth.json（即 GetAuthConfig 读取的文件）
+//	  2. 工作目录下的项目 auth.json
+//	  3. COMPOSER_AUTH 环境变量，与执行命令时相同，实例的设置覆盖进程环境变量（见 GetEnvVariable）
+//	支持 http-basic、bearer、github-oauth、gitlab-oauth 和 gitlab-token 认证，
+//	http-basic 同时兼容 AddHTTPBasicAuth 写入的 "username:password" 格式。
+func (c *Composer) LoadRepositoryAuth() (*packagist.Auth, error) {
+	homeDir, err := c.GetComposerHome()
+	if err != nil {
+		return nil, err
+	}
+
+	paths := []string{filepath.Join(homeDir, "auth.json")}
+	if c.workingDir != "" {
+		paths = append(paths, filepath.Join(c.workingDir, "auth.json"))
+	}
+
+	auth, err := packagist.LoadAuthFiles(paths...)
+	if err != nil {
+		return nil, err
+	}
+
+	if value := c.GetEnvVariable(EnvComposerAuth); value != "" {
+		envAuth, err := packagist.ParseAuth([]byte(value))
+		if err != nil {
+			return nil, fmt.Errorf("COMPOSER_AUTH: %w", err)
+		}
+		auth.Merge(envAuth)
+	}
+
+	return auth, nil
+}
+
+// NewRepositoryClient 创建访问 Composer 仓库的元数据客户端
+//
+// 参数：
+//   - baseURL: 仓库地址，为空时使用 Packagist
+//
+// 返回值：
+//   - *packagist.Client: 仓库客户端，请求 packages.json、provider 索引和分发包时会按域名附加认证信息
+//   - error: 如果加载认证信息或创建客户端失败，则返回相应的错误信息
+//
+// 用法示例：
+//
+//	client, err := comp.NewRepositoryClient("https://repo.example.com")
+//	if err != nil {
+//	    log.Fatalf("创建仓库客户端失败: %v", err)
+//	}
+//	versions, err := client.GetPackageVersions("acme/private-lib")
+func (c *Composer) NewRepositoryClient(baseURL string) (*packagist.Client, error) {
+	auth, err := c.LoadRepositoryAuth()
+	if err != nil {
+		return nil, err
+	}
+
+	config := packagist.DefaultConfig()
+	if baseURL != "" {
+		config.BaseURL = baseURL
+	}
+	config.Auth = auth
+
+	return packagist.NewClient(config)
+}
@@ -0,0 +1,52 @@
+package composer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadRepositoryAuth(t *testing.T) {
+	ClearMockOutputs()
+	defer ClearMockOutputs()
+
+	home := t.TempDir()
+	project := t.TempDir()
+	SetupMockOutput("config --global home", home, nil)
+
+	_ = os.WriteFile(filepath.Join(home, "auth.json"), []byte(`{
+		"http-basic": {"repo.example.com": "alice:secret"},
+		"github-oauth": {"github.com": "global-token"}
+	}`), 0600)
+	_ = os.WriteFile(filepath.Join(project, "auth.json"), []byte(`{
+		"github-oauth": {"github.com": "project-token"}
+	}`), 0600)
+
+	composer, err := New(Options{ExecutablePath: "/path/to/composer", WorkingDir: project})
+	if err != nil {
+		t.Fatalf("创建Composer实例失败: %v", err)
+	}
+	composer.SetEnv([]string{`COMPOSER_AUTH={"bearer": {"private.example.com": "env-token"}}`})
+
+	auth, err := composer.LoadRepositoryAuth()
+	if err != nil {
+		t.Fatalf("加载仓库认证信息失败: %v", err)
+	}
+	if cred := auth.HTTPBasic["repo.example.com"]; cred.Username != "alice" || cred.Password != "secret" {
+		t.Errorf("全局http-basic认证解析不正确: %+v", cred)
+	}
+	if auth.GitHubOAuth["github.com"] != "project-token" {
+		t.Errorf("项目auth.json应覆盖全局配置，实际为%q", auth.GitHubOAuth["github.com"])
+	}
+	if auth.Bearer["private.example.com"] != "env-token" {
+		t.Errorf("应读取SetEnv中的COMPOSER_AUTH，实际为%+v", auth.Bearer)
+	}
+
+	client, err := composer.NewRepositoryClient("https://private.example.com")
+	if err != nil {
+		t.Fatalf("创建仓库客户端失败: %v", err)
+	}
+	if config := client.GetConfig(); config.Auth == nil || config.BaseURL != "https://private.example.com" {
+		t.Errorf("仓库客户端配置不正确: %+v", config)
+	}
+}
@@ -0,0 +1,326 @@
+package composer
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net"
+	"net/http"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrRepositoryServerConfig 表示仓库服务器配置无效
+var ErrRepositoryServerConfig = errors.New("仓库服务器配置无效")
+
+// RepositoryServerConfig 保存 Composer 仓库 HTTP 服务的配置
+type RepositoryServerConfig struct {
+	// 仓库文件所在目录，通常是 GenerateStaticRepository 或 MirrorDependencies 的输出目录
+	Dir string
+	// 仓库文件系统，设置后忽略 Dir，可使用 MemoryRepository 提供内存中的仓库
+	FS fs.FS
+	// HTTP Basic 认证的用户名和密码，用户名为空时不启用
+	Username string
+	Password string
+	// Bearer 认证的令牌，为空时不启用；与 Basic 认证同时配置时任一通过即可
+	Token string
+	// 访问日志的输出位置，为nil时不记录
+	AccessLog io.Writer
+}
+
+// DefaultRepositoryServerConfig 返回以指定目录为仓库根目录、不启用认证的配置
+func DefaultRepositoryServerConfig(dir string) RepositoryServerConfig {
+	return RepositoryServerConfig{Dir: dir}
+}
+
+// RepositoryServer 是提供 packages.json、p2/ 元数据和分发包下载的 http.Handler
+type RepositoryServer struct {
+	config RepositoryServerConfig
+	fsys   fs.FS
+}
+
+// NewRepositoryServer 创建 Composer 仓库 HTTP 服务
+//
+// 参数：
+//   - config: 服务配置，Dir 和 FS 至少设置一个
+//
+// 返回值：
+//   - *RepositoryServer: 实现了 http.Handler 的仓库服务
+//   - error: 如果配置无效，则返回 ErrRepositoryServerConfig
+//
+// 功能说明：
+//
+//	只响应 GET 和 HEAD 请求，不提供目录列表，也不提供以 . 开头的隐藏文件；
+//	支持条件请求和 Range 请求。配置了认证信息时，未通过认证的请求返回 401。
+//
+// 用法示例：
+//
+//	server, err := composer.NewRepositoryServer(composer.DefaultRepositoryServerConfig("public"))
+//	if err != nil {
+//	    log.Fatalf("创建仓库服务失败: %v", err)
+//	}
+//	log.Fatal(http.ListenAndServe(":8080", server))
+func NewRepositoryServer(config RepositoryServerConfig) (*RepositoryServer, error) {
+	fsys := config.FS
+	if fsys == nil {
+		if config.Dir == "" {
+			return nil, fmt.Errorf("%w: 未指定仓库目录", ErrRepositoryServerConfig)
+		}
+		info, err := os.Stat(config.Dir)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrRepositoryServerConfig, err)
+		}
+		if !info.IsDir() {
+			return nil, fmt.Errorf("%w: %s 不是目录", ErrRepositoryServerConfig, config.Dir)
+		}
+		fsys = os.DirFS(config.Dir)
+	}
+	if config.Password != "" && config.Username == "" {
+		return nil, fmt.Errorf("%w: 设置了密码但没有用户名", ErrRepositoryServerConfig)
+	}
+
+	return &RepositoryServer{config: config, fsys: fsys}, nil
+}
+
+// ServeHTTP 实现 http.Handler
+func (s *RepositoryServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+	s.serve(rec, r)
+	if s.config.AccessLog != nil {
+		s.logAccess(r, rec, time.Since(start))
+	}
+}
+
+// serve 处理单个请求
+func (s *RepositoryServer) serve(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !s.authorized(r) {
+		if s.config.Username != "" {
+			w.Header().Set("WWW-Authenticate", `Basic realm="composer"`)
+		} else {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="composer"`)
+		}
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	name := strings.TrimPrefix(path.Clean("/"+r.URL.Path), "/")
+	if name == "" {
+		name = "packages.json"
+	}
+	if !fs.ValidPath(name) || hasHiddenElement(name) {
+		http.NotFound(w, r)
+		return
+	}
+
+	f, err := s.fsys.Open(name)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil || info.IsDir() {
+		http.NotFound(w, r)
+		return
+	}
+
+	if strings.HasSuffix(name, ".json") {
+		w.Header().Set("Content-Type", "application/json")
+	}
+
+	if rs, ok := f.(io.ReadSeeker); ok {
+		http.ServeContent(w, r, info.Name(), info.ModTime(), rs)
+		return
+	}
+
+	// 不支持 Seek 的文件系统只能完整读取后返回
+	content, err := io.ReadAll(f)
+	if err != nil {
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+	http.ServeContent(w, r, info.Name(), info.ModTime(), bytes.NewReader(content))
+}
+
+// authorized 检查请求的认证信息，未配置认证时总是通过
+func (s *RepositoryServer) authorized(r *http.Request) bool {
+	if s.config.Username == "" && s.config.Token == "" {
+		return true
+	}
+
+	if s.config.Username != "" {
+		if username, password, ok := r.BasicAuth(); ok &&
+			secureEqual(username, s.config.Username) && secureEqual(password, s.config.Password) {
+			return true
+		}
+	}
+
+	if s.config.Token != "" {
+		header := r.Header.Get("Authorization")
+		if len(header) > 7 && strings.EqualFold(header[:7], "Bearer ") && secureEqual(header[7:], s.config.Token) {
+			return true
+		}
+	}
+	return false
+}
+
+// logAccess 以 Common Log Format 写入一条访问日志
+func (s *RepositoryServer) logAccess(r *http.Request, rec *statusRecorder, elapsed time.Duration) {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	user := "-"
+	if username, _, ok := r.BasicAuth(); ok && username != "" {
+		user = username
+	}
+	fmt.Fprintf(s.config.AccessLog, "%s - %s [%s] \"%s %s %s\" %d %d %s\n",
+		host, user, time.Now().Format("02/Jan/2006:15:04:05 -0700"),
+		r.Method, r.URL.RequestURI(), r.Proto, rec.status, rec.size, elapsed.Round(time.Microsecond))
+}
+
+// secureEqual 以常量时间比较两个字符串
+func secureEqual(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
+
+// hasHiddenElement 判断路径中是否有以 . 开头的部分，例如 .git 或临时文件
+func hasHiddenElement(name string) bool {
+	for _, part := range strings.Split(name, "/") {
+		if strings.HasPrefix(part, ".") {
+			return true
+		}
+	}
+	return false
+}
+
+// statusRecorder 记录响应状态码和大小，用于访问日志
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+	size   int64
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(p []byte) (int, error) {
+	n, err := r.ResponseWriter.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+// MemoryRepository 是保存在内存中的仓库文件系统，实现了 fs.FS，可并发读写
+//
+// 用法示例：
+//
+//	repo := composer.NewMemoryRepository()
+//	repo.Put("packages.json", []byte(`{"packages": {}, "metadata-url": "/p2/%package%.json"}`))
+//	repo.Put("p2/acme/util.json", metadata)
+//	server, _ := composer.NewRepositoryServer(composer.RepositoryServerConfig{FS: repo})
+type MemoryRepository struct {
+	mu    sync.RWMutex
+	files map[string]memoryRepositoryFile
+}
+
+type memoryRepositoryFile struct {
+	data    []byte
+	modTime time.Time
+}
+
+// NewMemoryRepository 创建一个空的内存仓库
+func NewMemoryRepository() *MemoryRepository {
+	return &MemoryRepository{files: make(map[string]memoryRepositoryFile)}
+}
+
+// Put 写入或替换文件，name 为相对于仓库根目录的路径，例如 p2/acme/util.json
+func (m *MemoryRepository) Put(name string, data []byte) error {
+	name = strings.TrimPrefix(name, "/")
+	if !fs.ValidPath(name) || name == "." {
+		return &fs.PathError{Op: "put", Path: name, Err: fs.ErrInvalid}
+	}
+	content := make([]byte, len(data))
+	copy(content, data)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.files[name] = memoryRepositoryFile{data: content, modTime: time.Now()}
+	return nil
+}
+
+// Delete 删除文件，文件不存在时不做任何操作
+func (m *MemoryRepository) Delete(name string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.files, strings.TrimPrefix(name, "/"))
+}
+
+// Files 返回仓库中的全部文件路径，已排序
+func (m *MemoryRepository) Files() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	names := make([]string, 0, len(m.files))
+	for name := range m.files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Open 实现 fs.FS，只能打开文件，不支持目录
+func (m *MemoryRepository) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+
+	m.mu.RLock()
+	file, ok := m.files[name]
+	m.mu.RUnlock()
+	if !ok {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	return &memoryFile{
+		Reader: bytes.NewReader(file.data),
+		info:   memoryFileInfo{name: path.Base(name), size: int64(len(file.data)), modTime: file.modTime},
+	}, nil
+}
+
+// memoryFile 是 MemoryRepository 中打开的文件
+type memoryFile struct {
+	*bytes.Reader
+	info memoryFileInfo
+}
+
+func (f *memoryFile) Stat() (fs.FileInfo, error) { return f.info, nil }
+func (f *memoryFile) Close() error               { return nil }
+
+// memoryFileInfo 实现 fs.FileInfo
+type memoryFileInfo struct {
+	name    string
+	size    int64
+	modTime time.Time
+}
+
+func (i memoryFileInfo) Name() string       { return i.name }
+func (i memoryFileInfo) Size() int64        { return i.size }
+func (i memoryFileInfo) Mode() fs.FileMode  { return 0444 }
+func (i memoryFileInfo) ModTime() time.Time { return i.modTime }
+func (i memoryFileInfo) IsDir() bool        { return false }
+func (i memoryFileInfo) Sys() interface{}   { return nil }
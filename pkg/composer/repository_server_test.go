@@ -0,0 +1,171 @@
+package composer
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/scagogogo/go-composer-sdk/pkg/packagist"
+)
+
+func TestRepositoryServerWithClient(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "packages", "util", "composer.json"), `{"name": "acme/util", "version": "1.0.0"}`)
+
+	var handler http.Handler
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handler.ServeHTTP(w, r)
+	}))
+	defer ts.Close()
+
+	out := filepath.Join(dir, "public")
+	_, err := GenerateStaticRepository(SatisConfig{
+		Name:         "acme/repo",
+		Homepage:     ts.URL,
+		Repositories: []SatisRepository{{Type: "path", URL: filepath.Join(dir, "packages", "*")}},
+		Archive:      &SatisArchive{Directory: "dist"},
+	}, out)
+	if err != nil {
+		t.Fatalf("生成仓库失败: %v", err)
+	}
+
+	var logs bytes.Buffer
+	config := DefaultRepositoryServerConfig(out)
+	config.Username = "user"
+	config.Password = "secret"
+	config.AccessLog = &logs
+	server, err := NewRepositoryServer(config)
+	if err != nil {
+		t.Fatalf("创建仓库服务失败: %v", err)
+	}
+	handler = server
+
+	resp, err := http.Get(ts.URL + "/packages.json")
+	if err != nil {
+		t.Fatalf("请求失败: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("未认证请求应返回 401，实际 %d", resp.StatusCode)
+	}
+	if !strings.Contains(resp.Header.Get("WWW-Authenticate"), "Basic") {
+		t.Errorf("WWW-Authenticate 错误: %q", resp.Header.Get("WWW-Authenticate"))
+	}
+
+	u, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatalf("解析地址失败: %v", err)
+	}
+	clientConfig := packagist.DefaultConfig()
+	clientConfig.BaseURL = ts.URL
+	clientConfig.Auth = &packagist.Auth{HTTPBasic: map[string]packagist.HTTPBasicCredential{
+		u.Host: {Username: "user", Password: "secret"},
+	}}
+	client, err := packagist.NewClient(clientConfig)
+	if err != nil {
+		t.Fatalf("创建客户端失败: %v", err)
+	}
+
+	versions, err := client.GetPackageVersions("acme/util")
+	if err != nil {
+		t.Fatalf("获取包版本失败: %v", err)
+	}
+	if len(versions) != 1 || versions[0].Dist == nil {
+		t.Fatalf("期望 1 个带 dist 的版本，实际 %+v", versions)
+	}
+
+	sum, err := client.DownloadDistFile(versions[0].Dist, filepath.Join(dir, "util.zip"))
+	if err != nil {
+		t.Fatalf("下载归档失败: %v", err)
+	}
+	if sum != versions[0].Dist.Shasum {
+		t.Errorf("校验和错误: 期望 %s，实际 %s", versions[0].Dist.Shasum, sum)
+	}
+
+	for _, line := range []string{`"GET /packages.json HTTP/1.1" 401`, `user [`, `"GET /p2/acme/util.json HTTP/1.1" 200`} {
+		if !strings.Contains(logs.String(), line) {
+			t.Errorf("访问日志中缺少 %s:\n%s", line, logs.String())
+		}
+	}
+}
+
+func TestRepositoryServerMemory(t *testing.T) {
+	repo := NewMemoryRepository()
+	for _, file := range []string{"/packages.json", "p2/acme/util.json", ".secret"} {
+		content := `{"packages": {}}`
+		if file == ".secret" {
+			content = "x"
+		} else if file == "p2/acme/util.json" {
+			content = `{"packages": {"acme/util": []}}`
+		}
+		if err := repo.Put(file, []byte(content)); err != nil {
+			t.Fatalf("写入 %s 失败: %v", file, err)
+		}
+	}
+	if err := repo.Put("../escape", []byte("x")); err == nil {
+		t.Error("仓库外的路径应该返回错误")
+	}
+	if expected := []string{".secret", "p2/acme/util.json", "packages.json"}; !reflect.DeepEqual(repo.Files(), expected) {
+		t.Errorf("文件列表错误: 期望 %v，实际 %v", expected, repo.Files())
+	}
+
+	server, err := NewRepositoryServer(RepositoryServerConfig{FS: repo, Token: "t0ken"})
+	if err != nil {
+		t.Fatalf("创建仓库服务失败: %v", err)
+	}
+
+	get := func(method, path, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		server.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := get(http.MethodGet, "/", "t0ken")
+	if rec.Code != http.StatusOK {
+		t.Errorf("期望状态码 200，实际 %d", rec.Code)
+	}
+	if contentType := rec.Header().Get("Content-Type"); contentType != "application/json" {
+		t.Errorf("Content-Type 错误: %s", contentType)
+	}
+	if body, _ := io.ReadAll(rec.Body); string(body) != `{"packages": {}}` {
+		t.Errorf("响应内容错误: %s", body)
+	}
+
+	tests := []struct {
+		method string
+		path   string
+		token  string
+		code   int
+	}{
+		{http.MethodHead, "/p2/acme/util.json", "t0ken", http.StatusOK},
+		{http.MethodGet, "/packages.json", "wrong", http.StatusUnauthorized},
+		{http.MethodGet, "/.secret", "t0ken", http.StatusNotFound},
+		{http.MethodGet, "/p2/acme", "t0ken", http.StatusNotFound},
+		{http.MethodPost, "/packages.json", "t0ken", http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		if code := get(tt.method, tt.path, tt.token).Code; code != tt.code {
+			t.Errorf("%s %s: 期望状态码 %d，实际 %d", tt.method, tt.path, tt.code, code)
+		}
+	}
+
+	repo.Delete("p2/acme/util.json")
+	if code := get(http.MethodGet, "/p2/acme/util.json", "t0ken").Code; code != http.StatusNotFound {
+		t.Errorf("删除后期望状态码 404，实际 %d", code)
+	}
+
+	if _, err := NewRepositoryServer(RepositoryServerConfig{}); !errors.Is(err, ErrRepositoryServerConfig) {
+		t.Errorf("期望错误 %v，但得到 %v", ErrRepositoryServerConfig, err)
+	}
+}
@@ -0,0 +1,144 @@
+package composer
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/scagogogo/go-composer-sdk/pkg/detector"
+	"github.com/scagogogo/go-composer-sdk/pkg/installer"
+	"github.com/scagogogo/go-composer-sdk/pkg/resolver"
+)
+
+// ErrVersionMismatch 表示找到的Composer版本不满足 Options.RequiredVersion
+var ErrVersionMismatch = errors.New("composer版本不满足要求")
+
+// VersionMismatchError 表示没有找到满足版本约束的Composer，包含找到的安装及其版本
+type VersionMismatchError struct {
+	// Required 要求的版本约束，例如 ^2.5
+	Required string
+	// Found 找到的Composer安装，Version 为空表示无法读取版本
+	Found []detector.Installation
+}
+
+// Error 实现 error 接口
+func (e *VersionMismatchError) Error() string {
+	if len(e.Found) == 0 {
+		return fmt.Sprintf("%s: 需要 %s，未找到任何composer", ErrVersionMismatch, e.Required)
+	}
+	found := make([]string, len(e.Found))
+	for i, inst := range e.Found {
+		version := inst.Version
+		if version == "" {
+			version = "未知版本"
+		}
+		found[i] = fmt.Sprintf("%s (%s)", inst.Path, version)
+	}
+	return fmt.Sprintf("%s: 需要 %s，找到 %s", ErrVersionMismatch, e.Required, strings.Join(found, ", "))
+}
+
+// Unwrap 使 errors.Is(err, ErrVersionMismatch) 成立
+func (e *VersionMismatchError) Unwrap() error {
+	return ErrVersionMismatch
+}
+
+// detectRequiredVersion 查找满足版本约束的Composer，找不到且开启了自动安装时安装满足约束的版本
+func (c *Composer) detectRequiredVersion(required string, constraint *resolver.Constraint, defaultInstaller, preferProjectLocal bool) (string, error) {
+	installations, _ := c.detector.DetectAll()
+	if inst := matchingInstallation(installations, constraint, preferProjectLocal); inst != nil {
+		return inst.Path, nil
+	}
+	if !c.autoInstall {
+		return "", &VersionMismatchError{Required: required, Found: installations}
+	}
+
+	// 安装前确定满足约束的版本，避免覆盖已有的安装后才发现版本不满足
+	config, ok, err := pinRelease(c.installer.GetConfig(), required, constraint)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrComposerInstallation, err)
+	}
+	if !ok {
+		return "", &VersionMismatchError{Required: required, Found: installations}
+	}
+	c.installer = installer.NewInstaller(config)
+	if err := c.installComposer(defaultInstaller); err != nil {
+		return "", fmt.Errorf("%w: %v", ErrComposerInstallation, err)
+	}
+
+	c.detector.AddPossiblePath(c.installer.ExecutablePath())
+	installations, _ = c.detector.DetectAll()
+	if inst := matchingInstallation(installations, constraint, preferProjectLocal); inst != nil {
+		return inst.Path, nil
+	}
+	return "", &VersionMismatchError{Required: required, Found: installations}
+}
+
+// checkRequiredVersion 检查指定的Composer可执行文件是否满足版本约束
+func (c *Composer) checkRequiredVersion(required string, constraint *resolver.Constraint) error {
+	version, err := c.GetVersion()
+	if err != nil {
+		return fmt.Errorf("%w: 无法获取composer版本: %v", ErrVersionMismatch, err)
+	}
+	if v, err := resolver.ParseVersion(version); err == nil && constraint.Matches(v) {
+		return nil
+	}
+	return &VersionMismatchError{
+		Required: required,
+		Found:    []detector.Installation{{Path: c.executablePath, Version: version}},
+	}
+}
+
+// matchingInstallation 返回满足版本约束的安装，preferProjectLocal 为 true 时优先使用项目内的安装，没有时返回 nil
+func matchingInstallation(installations []detector.Installation, constraint *resolver.Constraint, preferProjectLocal bool) *detector.Installation {
+	var matches []detector.Installation
+	for _, inst := range installations {
+		if v, err := resolver.ParseVersion(inst.Version); err == nil && constraint.Matches(v) {
+			matches = append(matches, inst)
+		}
+	}
+	inst, err := detector.SelectInstallation(matches, detector.SelectionPolicy{PreferProjectLocal: preferProjectLocal})
+	if err != nil {
+		return nil
+	}
+	return inst
+}
+
+// pinRelease 根据版本约束设置安装器安装的版本
+//
+// 安装器已经指定了版本或渠道、或使用离线安装时保持不变；约束是完整的版本号时安装该版本，
+// 否则从 installer.FetchReleases 返回的全部条目中选择满足约束的最高版本，
+// 渠道的第一个条目不满足约束时也会继续检查其他条目，例如 stable 中面向旧版 PHP 的 2.2 LTS 版本。
+// 版本列表不包含历史版本，例如 ~2.4.0 这样只匹配旧版本的约束无法自动安装，需要指定完整的版本号。
+// 没有满足约束的版本时返回 false。
+func pinRelease(config installer.Config, required string, constraint *resolver.Constraint) (installer.Config, bool, error) {
+	if config.PinsRelease() || config.IsOffline() {
+		return config, true, nil
+	}
+	version := strings.TrimPrefix(strings.TrimSpace(required), "v")
+	if _, err := resolver.ParseVersion(version); err == nil && strings.Count(version, ".") == 2 {
+		config.Version = version
+		return config, true, nil
+	}
+
+	releases, err := installer.FetchReleases(config)
+	if err != nil {
+		return config, false, err
+	}
+	// 与 composer.json 相同，约束没有用 @RC 等标记时只选择稳定版
+	stability := constraint.Stability()
+	if stability == "" {
+		stability = resolver.StabilityStable
+	}
+	var best *resolver.Version
+	for _, release := range releases {
+		v, err := resolver.ParseVersion(release.Version)
+		if err != nil || !constraint.Matches(v) || !stability.Allows(v.Stability()) {
+			continue
+		}
+		if best == nil || v.Compare(best) > 0 {
+			best = v
+			config.Version = release.Version
+		}
+	}
+	return config, best != nil, nil
+}
@@ -0,0 +1,360 @@
+package composer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/scagogogo/go-composer-sdk/pkg/detector"
+	"github.com/scagogogo/go-composer-sdk/pkg/installer"
+	"github.com/scagogogo/go-composer-sdk/pkg/resolver"
+)
+
+// writeVersionScript 创建一个输出指定 Composer 版本的脚本
+func writeVersionScript(t *testing.T, path, version string) string {
+	t.Helper()
+	script := "#!/bin/sh\necho 'Composer version " + version + " 2024-06-10 22:11:12'\n"
+	if err := os.WriteFile(path, []byte(script), 0755); err != nil {
+		t.Fatalf("创建脚本失败: %v", err)
+	}
+	return path
+}
+
+func sha256Hex(content string) string {
+	digest := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(digest[:])
+}
+
+func TestRequiredVersionWithExecutablePath(t *testing.T) {
+	ClearMockOutputs()
+	defer ClearMockOutputs()
+
+	SetupMockOutput("--version", "Composer version 2.2.24 2024-06-10 22:11:12", nil)
+	_, err := New(Options{ExecutablePath: "/path/to/composer", RequiredVersion: "^2.5"})
+
+	var mismatch *VersionMismatchError
+	if !errors.As(err, &mismatch) || !errors.Is(err, ErrVersionMismatch) {
+		t.Fatalf("期望 *VersionMismatchError，但得到 %v", err)
+	}
+	if mismatch.Required != "^2.5" {
+		t.Errorf("Required 错误: %s", mismatch.Required)
+	}
+	if expected := []detector.Installation{{Path: "/path/to/composer", Version: "2.2.24"}}; !reflect.DeepEqual(mismatch.Found, expected) {
+		t.Errorf("Found 错误: 期望 %+v，实际 %+v", expected, mismatch.Found)
+	}
+	if !strings.Contains(err.Error(), "/path/to/composer (2.2.24)") {
+		t.Errorf("错误信息中缺少找到的版本: %v", err)
+	}
+
+	SetupMockOutput("--version", "Composer version 2.5.8 2023-06-09 17:13:21", nil)
+	comp, err := New(Options{ExecutablePath: "/path/to/composer", RequiredVersion: "^2.5"})
+	if err != nil {
+		t.Fatalf("创建Composer实例失败: %v", err)
+	}
+
+	version, err := comp.GetParsedVersion()
+	if err != nil {
+		t.Fatalf("获取版本失败: %v", err)
+	}
+	expected, err := resolver.ParseVersion("2.5.8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version.Compare(expected) != 0 || version.String() != "2.5.8" {
+		t.Errorf("版本错误: 期望 2.5.8，实际 %s", version)
+	}
+
+	if _, err := New(Options{ExecutablePath: "/path/to/composer", RequiredVersion: "not a constraint"}); !errors.Is(err, ErrInitFailed) {
+		t.Errorf("期望错误 %v，但得到 %v", ErrInitFailed, err)
+	}
+}
+
+func TestRequiredVersionDetection(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("模拟的 composer 脚本只在类 Unix 系统上运行")
+	}
+	ClearMockOutputs()
+	t.Setenv("PATH", t.TempDir())
+	t.Setenv("COMPOSER_PATH", "")
+
+	dir := t.TempDir()
+	old := writeVersionScript(t, filepath.Join(dir, "composer-2.2"), "2.2.24")
+	current := writeVersionScript(t, filepath.Join(dir, "composer-2.7"), "2.7.7")
+
+	newDetector := func() *detector.Detector {
+		d := detector.NewDetector()
+		d.SetPossiblePaths([]string{old, current})
+		d.SetProjectDir(t.TempDir())
+		return d
+	}
+
+	comp, err := New(Options{Detector: newDetector(), RequiredVersion: ">=2.5"})
+	if err != nil {
+		t.Fatalf("创建Composer实例失败: %v", err)
+	}
+	if comp.GetExecutablePath() != current {
+		t.Errorf("期望使用 %s，实际使用 %s", current, comp.GetExecutablePath())
+	}
+
+	_, err = New(Options{Detector: newDetector(), RequiredVersion: "^3.0"})
+	var mismatch *VersionMismatchError
+	if !errors.As(err, &mismatch) {
+		t.Fatalf("期望 *VersionMismatchError，但得到 %v", err)
+	}
+	if len(mismatch.Found) != 2 || mismatch.Found[0].Version != "2.2.24" || mismatch.Found[1].Version != "2.7.7" {
+		t.Errorf("Found 错误: %+v", mismatch.Found)
+	}
+}
+
+func TestRequiredVersionProjectLocal(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("模拟的 composer 脚本只在类 Unix 系统上运行")
+	}
+	ClearMockOutputs()
+	t.Setenv("PATH", t.TempDir())
+
+	system := writeVersionScript(t, filepath.Join(t.TempDir(), "composer"), "2.6.6")
+	t.Setenv("COMPOSER_PATH", system)
+
+	// 项目内的 composer 被运行时留下标记文件
+	projectDir := t.TempDir()
+	marker := filepath.Join(t.TempDir(), "executed")
+	local := filepath.Join(projectDir, "vendor", "bin", "composer")
+	if err := os.MkdirAll(filepath.Dir(local), 0755); err != nil {
+		t.Fatal(err)
+	}
+	script := "#!/bin/sh\ntouch '" + marker + "'\necho 'Composer version 2.7.7 2024-06-10 22:11:12'\n"
+	if err := os.WriteFile(local, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	comp, err := New(Options{WorkingDir: projectDir, RequiredVersion: ">=2.5"})
+	if err != nil {
+		t.Fatalf("创建Composer实例失败: %v", err)
+	}
+	if comp.GetExecutablePath() != system {
+		t.Errorf("默认不应使用项目内的composer，实际使用 %s", comp.GetExecutablePath())
+	}
+	if _, err := os.Stat(marker); err == nil {
+		t.Error("默认不应运行项目内的composer")
+	}
+
+	comp, err = New(Options{WorkingDir: projectDir, RequiredVersion: ">=2.5", PreferProjectLocal: true})
+	if err != nil {
+		t.Fatalf("创建Composer实例失败: %v", err)
+	}
+	if comp.GetExecutablePath() != local {
+		t.Errorf("开启 PreferProjectLocal 后应使用 %s，实际使用 %s", local, comp.GetExecutablePath())
+	}
+}
+
+func TestRequiredVersionAutoInstall(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("模拟的 php 脚本只在类 Unix 系统上运行")
+	}
+	ClearMockOutputs()
+
+	// 安装器创建的包装脚本通过 PATH 中的 php 运行 composer.phar
+	pathDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(pathDir, "php"), []byte("#!/bin/sh\nexec /bin/sh \"$@\"\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", pathDir)
+	t.Setenv("COMPOSER_PATH", "")
+
+	phar := "echo 'Composer version 2.6.6 2023-12-08 18:32:26'\n"
+	src := filepath.Join(t.TempDir(), "composer.phar")
+	if err := os.WriteFile(src, []byte(phar), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	config := installer.DefaultConfig()
+	config.InstallPath = t.TempDir()
+	config.UseSudo = false
+	config.LocalPharPath = src
+	config.PharChecksum = sha256Hex(phar)
+
+	d := detector.NewDetector()
+	d.SetPossiblePaths([]string{writeVersionScript(t, filepath.Join(t.TempDir(), "composer"), "2.2.24")})
+	d.SetProjectDir(t.TempDir())
+
+	comp, err := New(Options{
+		Detector:        d,
+		Installer:       installer.NewInstaller(config),
+		AutoInstall:     true,
+		DefaultTimeout:  DefaultOptions().DefaultTimeout,
+		RequiredVersion: "^2.5",
+	})
+	if err != nil {
+		t.Fatalf("自动安装失败: %v", err)
+	}
+	if expected := filepath.Join(config.InstallPath, "composer"); comp.GetExecutablePath() != expected {
+		t.Errorf("期望使用 %s，实际使用 %s", expected, comp.GetExecutablePath())
+	}
+
+	version, err := comp.GetVersion()
+	if err != nil || version != "2.6.6" {
+		t.Errorf("期望版本 2.6.6，实际 %q, %v", version, err)
+	}
+}
+
+// newVersionsServer 创建提供 /versions 的测试服务器，并记录其他路径的请求
+func newVersionsServer(t *testing.T, versions string, requests *[]string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/versions" {
+			_, _ = w.Write([]byte(versions))
+			return
+		}
+		*requests = append(*requests, r.URL.Path)
+		http.NotFound(w, r)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestPinRelease(t *testing.T) {
+	var requests []string
+	server := newVersionsServer(t, `{
+		"stable": [{"version": "2.7.7"}],
+		"preview": [{"version": "2.8.0-RC1"}],
+		"1": [{"version": "1.10.27"}],
+		"2": [{"version": "2.7.7"}],
+		"2.2": [{"version": "2.2.24"}]
+	}`, &requests)
+
+	tests := []struct {
+		required string
+		version  string
+		ok       bool
+	}{
+		{"2.5.8", "2.5.8", true},
+		{"v2.5.8", "2.5.8", true},
+		{"^2.5", "2.7.7", true},
+		{">=2.2", "2.7.7", true},
+		{"~2.2.0", "2.2.24", true},
+		{"^1.10", "1.10.27", true},
+		{"<2.3", "2.2.24", true},
+		{"~2.4.0", "", false},
+		{"^3.0", "", false},
+		{"^2.8@RC", "2.8.0-RC1", true},
+	}
+	for _, tt := range tests {
+		config := installer.DefaultConfig()
+		config.PharMirrorURL = server.URL
+		config, ok, err := pinRelease(config, tt.required, resolver.MustParseConstraint(tt.required))
+		if err != nil {
+			t.Fatalf("%s: pinRelease 失败: %v", tt.required, err)
+		}
+		if config.Version != tt.version || ok != tt.ok {
+			t.Errorf("%s: 期望 %q, %v，实际 %q, %v", tt.required, tt.version, tt.ok, config.Version, ok)
+		}
+		if config.Channel != "" {
+			t.Errorf("%s: 不应设置渠道，实际 %s", tt.required, config.Channel)
+		}
+	}
+
+	// 安装器已经指定了渠道时保持不变
+	config := installer.DefaultConfig()
+	config.Channel = installer.ChannelPreview
+	config, ok, err := pinRelease(config, "^2.5", resolver.MustParseConstraint("^2.5"))
+	if err != nil || !ok || config.Channel != installer.ChannelPreview || config.Version != "" {
+		t.Errorf("指定了渠道时应保持不变，实际 %+v, %v, %v", config, ok, err)
+	}
+
+	// 无法获取版本列表时返回错误
+	config = installer.DefaultConfig()
+	config.PharMirrorURL = server.URL + "/missing"
+	if _, _, err := pinRelease(config, "^2.5", resolver.MustParseConstraint("^2.5")); !errors.Is(err, installer.ErrDownloadFailed) {
+		t.Errorf("期望错误 %v，但得到 %v", installer.ErrDownloadFailed, err)
+	}
+}
+
+func TestPinReleaseChannelEntries(t *testing.T) {
+	// 与 getcomposer.org/versions 相同，stable 和 2 渠道的第二个条目是面向旧版 PHP 的 LTS 版本
+	var requests []string
+	server := newVersionsServer(t, `{
+		"stable": [
+			{"path": "/download/2.8.3/composer.phar", "version": "2.8.3", "min-php": 70205},
+			{"path": "/download/2.2.24/composer.phar", "version": "2.2.24", "min-php": 50300}
+		],
+		"preview": [
+			{"path": "/download/2.8.3/composer.phar", "version": "2.8.3", "min-php": 70205},
+			{"path": "/download/2.2.24/composer.phar", "version": "2.2.24", "min-php": 50300}
+		],
+		"snapshot": [
+			{"path": "/composer.phar", "version": "2f5a7a9e3a6c14b0e2f3a8d5c9b1e4f7a0d3c6b9", "min-php": 70205}
+		],
+		"1": [{"path": "/download/1.10.27/composer.phar", "version": "1.10.27", "min-php": 50300}],
+		"2": [
+			{"path": "/download/2.8.3/composer.phar", "version": "2.8.3", "min-php": 70205},
+			{"path": "/download/2.2.24/composer.phar", "version": "2.2.24", "min-php": 50300}
+		]
+	}`, &requests)
+
+	for required, expected := range map[string]string{
+		"^2.2 <2.3": "2.2.24",
+		"~2.2.0":    "2.2.24",
+		"^2.2":      "2.8.3",
+	} {
+		config := installer.DefaultConfig()
+		config.PharMirrorURL = server.URL
+		config, ok, err := pinRelease(config, required, resolver.MustParseConstraint(required))
+		if err != nil || !ok {
+			t.Fatalf("%s: pinRelease 失败: %v, %v", required, ok, err)
+		}
+		if config.Version != expected {
+			t.Errorf("%s: 期望 %s，实际 %q", required, expected, config.Version)
+		}
+	}
+	if len(requests) != 0 {
+		t.Errorf("选择版本时只应请求 /versions，实际还请求了 %v", requests)
+	}
+}
+
+func TestRequiredVersionAutoInstallNoMatchingRelease(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("模拟的 composer 脚本只在类 Unix 系统上运行")
+	}
+	ClearMockOutputs()
+	t.Setenv("PATH", t.TempDir())
+	t.Setenv("COMPOSER_PATH", "")
+
+	var requests []string
+	server := newVersionsServer(t, `{"stable": [{"version": "2.8.3"}], "2.2": [{"version": "2.2.24"}]}`, &requests)
+
+	config := installer.DefaultConfig()
+	config.InstallPath = t.TempDir()
+	config.UseSudo = false
+	config.PharMirrorURL = server.URL
+	existing := writeVersionScript(t, filepath.Join(config.InstallPath, "composer"), "2.2.24")
+
+	d := detector.NewDetector()
+	d.SetPossiblePaths([]string{existing})
+
+	_, err := New(Options{
+		Detector:        d,
+		Installer:       installer.NewInstaller(config),
+		AutoInstall:     true,
+		DefaultTimeout:  DefaultOptions().DefaultTimeout,
+		RequiredVersion: "~2.4.0",
+	})
+	var mismatch *VersionMismatchError
+	if !errors.As(err, &mismatch) {
+		t.Fatalf("期望 *VersionMismatchError，但得到 %v", err)
+	}
+	if len(requests) != 0 {
+		t.Errorf("没有满足约束的版本时不应下载，实际请求 %v", requests)
+	}
+	if content, _ := os.ReadFile(existing); !strings.Contains(string(content), "2.2.24") {
+		t.Errorf("已有的安装被覆盖: %s", content)
+	}
+}
@@ -1,90 +1,376 @@
 package composer
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/url"
 	"os"
+	"path"
 	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/scagogogo/go-composer-sdk/pkg/resolver"
 )
 
 // SatisConfig 表示 Satis 配置
+//
+// 通过 LoadSatisConfig 读取、Save 写回时，配置文件中本结构未定义的字段以及没有修改过的字段
+// 会按原样写回，文件中没有的字段也不会被补上，例如缺少的 output-dir 不会被写成 ""，
+// 显式的 "require-all": false 也会被保留。
 type SatisConfig struct {
 	Name                   string                 `json:"name"`
 	Homepage               string                 `json:"homepage"`
-	Repositories           []map[string]string    `json:"repositories"`
+	Repositories           []SatisRepository      `json:"repositories"`
 	OutputDir              string                 `json:"output-dir"`
 	RequireAll             bool                   `json:"require-all,omitempty"`
 	RequireDependencies    bool                   `json:"require-dependencies,omitempty"`
 	RequireDevDependencies bool                   `json:"require-dev-dependencies,omitempty"`
 	Require                map[string]string      `json:"require,omitempty"`
-	Archive                map[string]interface{} `json:"archive,omitempty"`
+	Archive                *SatisArchive          `json:"archive,omitempty"`
 	MinimumStability       string                 `json:"minimum-stability,omitempty"`
 	Providers              bool                   `json:"providers,omitempty"`
 	ProvidersURL           string                 `json:"providers-url,omitempty"`
 	Config                 map[string]interface{} `json:"config,omitempty"`
 	Notify                 map[string]interface{} `json:"notify,omitempty"`
 	TwigTemplate           string                 `json:"twig-template,omitempty"`
+	// Blacklist 不收录的包版本，键为包名，值为版本约束
+	Blacklist map[string]string `json:"blacklist,omitempty"`
+	// Whitelist 设置后只收录其中的包版本，键为包名，值为版本约束
+	Whitelist map[string]string `json:"whitelist,omitempty"`
+	// StripHosts 需要从 dist 和 source 中移除的主机，支持主机名、.example.com 后缀、
+	// IP、CIDR，以及表示本地路径的 /local 和表示私有地址的 /private
+	StripHosts SatisHostList `json:"strip-hosts,omitempty"`
+	// Abandoned 标记为弃用的包，值为 true 或替代包的名称
+	Abandoned map[string]interface{} `json:"abandoned,omitempty"`
+	// IncludeFilename 设置后额外生成 Composer 1 使用的 include 文件，例如 include/all$%hash%.json
+	IncludeFilename string `json:"include-filename,omitempty"`
+
+	raw map[string]json.RawMessage
 }
 
-// CreateSatisConfig 创建一个新的 Satis 配置文件
-func (c *Composer) CreateSatisConfig(configPath string, name string, homepage string) error {
-	// 创建基本配置
-	config := SatisConfig{
-		Name:         name,
-		Homepage:     homepage,
-		Repositories: []map[string]string{},
-		OutputDir:    "public",
-		RequireAll:   true,
-	}
+// SatisRepository 表示 Satis 配置中的一个仓库
+type SatisRepository struct {
+	// Type 仓库类型，例如 vcs、git、path、artifact、package、composer
+	Type string `json:"type"`
+	// URL 仓库地址，path 和 artifact 类型为本地目录
+	URL string `json:"url,omitempty"`
+	// Options 仓库选项，例如 path 仓库的 {"versions": {"acme/util": "1.0.0"}}
+	Options map[string]interface{} `json:"options,omitempty"`
+	// Package package 类型仓库中内联的包定义，可以是单个对象或数组
+	Package json.RawMessage `json:"package,omitempty"`
+	// Exclude 不从该仓库读取的包名，支持 vendor/* 形式的通配符
+	Exclude []string `json:"exclude,omitempty"`
+
+	raw map[string]json.RawMessage
+}
 
-	// 序列化为 JSON
-	content, err := json.MarshalIndent(config, "", "    ")
-	if err != nil {
-		return err
-	}
+// SatisArchive 表示 Satis 配置中的分发包归档设置
+type SatisArchive struct {
+	// Directory 分发包相对于输出目录的存放目录，同时作为下载地址的路径
+	Directory string `json:"directory"`
+	// Format 归档格式，zip 或 tar，默认为 zip
+	Format string `json:"format,omitempty"`
+	// AbsoluteDirectory 设置后分发包写入该目录而不是输出目录下的 Directory，下载地址不变
+	AbsoluteDirectory string `json:"absolute-directory,omitempty"`
+	// SkipDev 不为开发版本生成分发包
+	SkipDev bool `json:"skip-dev,omitempty"`
+	// Whitelist 设置后只为这些包生成分发包，支持通配符
+	Whitelist []string `json:"whitelist,omitempty"`
+	// Blacklist 不为这些包生成分发包，支持通配符
+	Blacklist []string `json:"blacklist,omitempty"`
+	// Checksum 是否在 dist 中写入 shasum，为nil时默认写入
+	Checksum *bool `json:"checksum,omitempty"`
+	// PrefixURL 下载地址的前缀，为空时使用 homepage
+	PrefixURL string `json:"prefix-url,omitempty"`
+
+	raw map[string]json.RawMessage
+}
 
-	// 确保目录存在
-	dir := filepath.Dir(configPath)
-	if err := os.MkdirAll(dir, 0755); err != nil {
+// SatisHostList 是 strip-hosts 的主机列表，兼容配置为 false 的写法
+type SatisHostList []string
+
+// UnmarshalJSON 解析主机列表，false 和 null 表示不移除任何主机
+func (l *SatisHostList) UnmarshalJSON(data []byte) error {
+	var enabled bool
+	if err := json.Unmarshal(data, &enabled); err == nil {
+		if enabled {
+			return fmt.Errorf("%w: strip-hosts 必须是主机列表或 false", ErrSatisConfigInvalid)
+		}
+		*l = nil
+		return nil
+	}
+	var hosts []string
+	if err := json.Unmarshal(data, &hosts); err != nil {
 		return err
 	}
+	*l = hosts
+	return nil
+}
+
+// satisConfigFields 是 SatisConfig 的序列化别名，避免递归调用 MarshalJSON
+type satisConfigFields SatisConfig
 
-	// 写入文件
-	return os.WriteFile(configPath, content, 0644)
+// UnmarshalJSON 解析配置并记录读取到的全部字段
+func (c *SatisConfig) UnmarshalJSON(data []byte) error {
+	raw, err := unmarshalWithRaw(data, (*satisConfigFields)(c))
+	c.raw = raw
+	return err
 }
 
-// AddSatisRepository 向 Satis 配置中添加仓库
-func (c *Composer) AddSatisRepository(configPath string, type_ string, url string) error {
-	// 读取现有配置
+// MarshalJSON 序列化配置，读取时的字段按原样保留，未知字段按名称排序追加在已知字段之后
+func (c SatisConfig) MarshalJSON() ([]byte, error) {
+	return marshalWithRaw(satisConfigFields(c), c.raw)
+}
+
+type satisRepositoryFields SatisRepository
+
+// UnmarshalJSON 解析仓库配置并记录读取到的全部字段
+func (r *SatisRepository) UnmarshalJSON(data []byte) error {
+	raw, err := unmarshalWithRaw(data, (*satisRepositoryFields)(r))
+	r.raw = raw
+	return err
+}
+
+// MarshalJSON 序列化仓库配置，保留未知字段
+func (r SatisRepository) MarshalJSON() ([]byte, error) {
+	return marshalWithRaw(satisRepositoryFields(r), r.raw)
+}
+
+type satisArchiveFields SatisArchive
+
+// UnmarshalJSON 解析归档设置并记录读取到的全部字段
+func (a *SatisArchive) UnmarshalJSON(data []byte) error {
+	raw, err := unmarshalWithRaw(data, (*satisArchiveFields)(a))
+	a.raw = raw
+	return err
+}
+
+// MarshalJSON 序列化归档设置，保留未知字段
+func (a SatisArchive) MarshalJSON() ([]byte, error) {
+	return marshalWithRaw(satisArchiveFields(a), a.raw)
+}
+
+// ChecksumEnabled 返回是否在 dist 中写入 shasum
+func (a *SatisArchive) ChecksumEnabled() bool {
+	return a.Checksum == nil || *a.Checksum
+}
+
+// LoadSatisConfig 读取 Satis 配置文件
+//
+// 参数：
+//   - configPath: satis.json 配置文件路径
+//
+// 返回值：
+//   - *SatisConfig: 配置内容，未知字段会在 Save 时原样写回
+//   - error: 如果读取或解析失败，则返回相应的错误信息
+//
+// 用法示例：
+//
+//	config, err := composer.LoadSatisConfig("satis.json")
+//	if err != nil {
+//	    log.Fatalf("读取 Satis 配置失败: %v", err)
+//	}
+//	config.Blacklist = map[string]string{"acme/legacy": "*"}
+//	err = config.Save("satis.json")
+func LoadSatisConfig(configPath string) (*SatisConfig, error) {
 	content, err := os.ReadFile(configPath)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	var config SatisConfig
 	if err := json.Unmarshal(content, &config); err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrSatisConfigInvalid, configPath, err)
+	}
+	return &config, nil
+}
+
+// Save 将配置写入文件，目录不存在时自动创建
+func (c *SatisConfig) Save(configPath string) error {
+	content, err := json.MarshalIndent(c, "", "    ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(configPath), 0755); err != nil {
 		return err
 	}
+	return os.WriteFile(configPath, append(content, '\n'), 0644)
+}
+
+// Validate 检查配置是否有效
+//
+// 返回值：
+//   - error: 配置无效时返回包含全部问题的错误，每个问题都包装了 ErrSatisConfigInvalid
+func (c *SatisConfig) Validate() error {
+	var errs []error
+	invalid := func(format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf("%w: "+format, append([]interface{}{ErrSatisConfigInvalid}, args...)...))
+	}
+
+	if c.Name == "" {
+		invalid("缺少 name")
+	}
+	if c.Homepage == "" {
+		invalid("缺少 homepage")
+	} else if !isHTTPURL(c.Homepage) {
+		invalid("homepage 不是有效的 HTTP 地址: %s", c.Homepage)
+	}
+	if c.MinimumStability != "" {
+		if _, ok := resolver.ParseStability(c.MinimumStability); !ok {
+			invalid("无效的 minimum-stability: %s", c.MinimumStability)
+		}
+	}
+
+	for i, repo := range c.Repositories {
+		switch repo.Type {
+		case "package":
+			if len(bytes.TrimSpace(repo.Package)) == 0 {
+				invalid("repositories[%d]: package 仓库缺少 package", i)
+			}
+		case "":
+			invalid("repositories[%d]: 缺少 type", i)
+		default:
+			if repo.URL == "" {
+				invalid("repositories[%d]: %s 仓库缺少 url", i, repo.Type)
+			}
+		}
+		for _, pattern := range repo.Exclude {
+			if _, err := path.Match(pattern, ""); err != nil {
+				invalid("repositories[%d]: 无效的 exclude 模式 %q", i, pattern)
+			}
+		}
+	}
+
+	for field, links := range map[string]map[string]string{"require": c.Require, "blacklist": c.Blacklist, "whitelist": c.Whitelist} {
+		for name, constraint := range links {
+			if _, err := resolver.ParseConstraint(constraint); err != nil {
+				invalid("%s.%s: %v", field, name, err)
+			}
+		}
+	}
+
+	for name, value := range c.Abandoned {
+		switch value.(type) {
+		case bool, string:
+		default:
+			invalid("abandoned.%s 必须是 true 或替代包名", name)
+		}
+	}
+
+	for _, host := range c.StripHosts {
+		if host == "" {
+			invalid("strip-hosts 中包含空主机")
+		}
+	}
+
+	if c.IncludeFilename != "" && (path.IsAbs(c.IncludeFilename) || strings.Contains(c.IncludeFilename, "..")) {
+		invalid("include-filename 必须是输出目录中的相对路径: %s", c.IncludeFilename)
+	}
 
-	// 添加仓库
-	repo := map[string]string{
-		"type": type_,
-		"url":  url,
+	if hasParentElement(c.OutputDir) {
+		invalid("output-dir 不能包含 ..: %s", c.OutputDir)
 	}
 
-	config.Repositories = append(config.Repositories, repo)
+	if a := c.Archive; a != nil {
+		if a.Directory == "" {
+			invalid("archive 缺少 directory")
+		} else if path.IsAbs(filepath.ToSlash(a.Directory)) || filepath.IsAbs(a.Directory) || hasParentElement(a.Directory) {
+			invalid("archive.directory 必须是输出目录中的相对路径: %s", a.Directory)
+		}
+		if a.Format != "" && a.Format != "zip" && a.Format != "tar" {
+			invalid("不支持的归档格式: %s", a.Format)
+		}
+		if a.AbsoluteDirectory != "" && !filepath.IsAbs(a.AbsoluteDirectory) {
+			invalid("archive.absolute-directory 必须是绝对路径: %s", a.AbsoluteDirectory)
+		}
+		if a.PrefixURL != "" && !isHTTPURL(a.PrefixURL) {
+			invalid("archive.prefix-url 不是有效的 HTTP 地址: %s", a.PrefixURL)
+		}
+		for _, pattern := range append(append([]string{}, a.Whitelist...), a.Blacklist...) {
+			if _, err := path.Match(pattern, ""); err != nil {
+				invalid("archive 中无效的包名模式 %q", pattern)
+			}
+		}
+	}
+
+	sort.Slice(errs, func(i, j int) bool { return errs[i].Error() < errs[j].Error() })
+	return errors.Join(errs...)
+}
+
+// hasParentElement 判断路径中是否包含 .. 路径段
+func hasParentElement(p string) bool {
+	for _, element := range strings.Split(filepath.ToSlash(p), "/") {
+		if element == ".." {
+			return true
+		}
+	}
+	return false
+}
+
+// isHTTPURL 判断是否为 http 或 https 地址
+func isHTTPURL(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
 
-	// 序列化为 JSON
-	updatedContent, err := json.MarshalIndent(config, "", "    ")
+// CreateSatisConfig 创建一个新的 Satis 配置文件
+func (c *Composer) CreateSatisConfig(configPath string, name string, homepage string) error {
+	// 创建基本配置
+	config := SatisConfig{
+		Name:         name,
+		Homepage:     homepage,
+		Repositories: []SatisRepository{},
+		OutputDir:    "public",
+		RequireAll:   true,
+	}
+	return config.Save(configPath)
+}
+
+// UpdateSatisConfig 读取 Satis 配置，调用 update 修改后写回
+//
+// 参数：
+//   - configPath: satis.json 配置文件路径
+//   - update: 修改配置的函数，返回错误时不写回文件
+//
+// 返回值：
+//   - error: 如果读取、修改或写入失败，则返回相应的错误信息
+//
+// 功能说明：
+//
+//	配置文件中本 SDK 未定义的字段会原样保留。
+func (c *Composer) UpdateSatisConfig(configPath string, update func(config *SatisConfig) error) error {
+	config, err := LoadSatisConfig(configPath)
 	if err != nil {
 		return err
 	}
+	if err := update(config); err != nil {
+		return err
+	}
+	return config.Save(configPath)
+}
+
+// AddSatisRepository 向 Satis 配置中添加仓库
+func (c *Composer) AddSatisRepository(configPath string, type_ string, url string) error {
+	return c.AddSatisRepositoryConfig(configPath, SatisRepository{Type: type_, URL: url})
+}
 
-	// 写入文件
-	return os.WriteFile(configPath, updatedContent, 0644)
+// AddSatisRepositoryConfig 向 Satis 配置中添加包含选项、内联包定义或排除列表的仓库
+func (c *Composer) AddSatisRepositoryConfig(configPath string, repo SatisRepository) error {
+	return c.UpdateSatisConfig(configPath, func(config *SatisConfig) error {
+		config.Repositories = append(config.Repositories, repo)
+		return nil
+	})
 }
 
 // BuildSatis 使用指定的配置文件构建 Satis 仓库
+//
+// 该方法调用外部的 satis 命令；不需要 PHP 环境时可使用 BuildSatisNative。
 func (c *Composer) BuildSatis(configPath string, outputDir string) (string, error) {
 	if outputDir == "" {
 		return c.Run("satis", "build", configPath)
@@ -123,95 +409,168 @@ func (c *Composer) UpdateSatisStability(configPath string, stability string) err
 		return fmt.Errorf("invalid stability: %s", stability)
 	}
 
-	// 读取现有配置
-	content, err := os.ReadFile(configPath)
-	if err != nil {
-		return err
-	}
+	return c.UpdateSatisConfig(configPath, func(config *SatisConfig) error {
+		config.MinimumStability = stability
+		return nil
+	})
+}
 
-	var config SatisConfig
-	if err := json.Unmarshal(content, &config); err != nil {
-		return err
+// EnableSatisArchive 启用 Satis 的归档功能
+func (c *Composer) EnableSatisArchive(configPath string, format string) error {
+	if format == "" {
+		format = "zip"
 	}
 
-	// 更新稳定性
-	config.MinimumStability = stability
-
-	// 序列化为 JSON
-	updatedContent, err := json.MarshalIndent(config, "", "    ")
-	if err != nil {
-		return err
-	}
+	return c.UpdateSatisConfig(configPath, func(config *SatisConfig) error {
+		// 保留已有的归档设置，只更新目录和格式
+		if config.Archive == nil {
+			config.Archive = &SatisArchive{}
+		}
+		if config.Archive.Directory == "" {
+			config.Archive.Directory = "dist"
+		}
+		config.Archive.Format = format
+		config.Archive.SkipDev = false
+		return nil
+	})
+}
 
-	// 写入文件
-	return os.WriteFile(configPath, updatedContent, 0644)
+// AddSatisRequire 向 Satis 配置中添加依赖
+func (c *Composer) AddSatisRequire(configPath string, packageName string, version string) error {
+	return c.UpdateSatisConfig(configPath, func(config *SatisConfig) error {
+		// 设置依赖，并关闭 require-all
+		if config.Require == nil {
+			config.Require = make(map[string]string)
+		}
+		config.Require[packageName] = version
+		config.RequireAll = false
+		return nil
+	})
 }
 
-// EnableSatisArchive 启用 Satis 的归档功能
-func (c *Composer) EnableSatisArchive(configPath string, format string) error {
-	// 读取现有配置
-	content, err := os.ReadFile(configPath)
-	if err != nil {
-		return err
-	}
+// jsonFieldNames 缓存结构体的JSON字段名
+var jsonFieldNames sync.Map
 
-	var config SatisConfig
-	if err := json.Unmarshal(content, &config); err != nil {
-		return err
+// jsonFieldName 返回结构体字段序列化时使用的JSON字段名，不参与序列化的字段返回false
+func jsonFieldName(f reflect.StructField) (string, bool) {
+	if !f.IsExported() {
+		return "", false
 	}
-
-	// 设置归档选项
-	if config.Archive == nil {
-		config.Archive = make(map[string]interface{})
+	name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+	if name == "-" {
+		return "", false
 	}
+	if name == "" {
+		name = f.Name
+	}
+	return name, true
+}
 
-	config.Archive["directory"] = "dist"
-
-	if format != "" {
-		config.Archive["format"] = format
-	} else {
-		config.Archive["format"] = "zip"
+// knownJSONFields 返回结构体类型中通过 json 标签序列化的字段名
+func knownJSONFields(t reflect.Type) map[string]bool {
+	if cached, ok := jsonFieldNames.Load(t); ok {
+		return cached.(map[string]bool)
 	}
 
-	config.Archive["skip-dev"] = false
+	fields := make(map[string]bool, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		if name, ok := jsonFieldName(t.Field(i)); ok {
+			fields[name] = true
+		}
+	}
+	jsonFieldNames.Store(t, fields)
+	return fields
+}
 
-	// 序列化为 JSON
-	updatedContent, err := json.MarshalIndent(config, "", "    ")
-	if err != nil {
-		return err
+// unmarshalWithRaw 将 data 解析到结构体指针 v，并返回 data 中全部字段的原始内容
+func unmarshalWithRaw(data []byte, v interface{}) (map[string]json.RawMessage, error) {
+	if err := json.Unmarshal(data, v); err != nil {
+		return nil, err
 	}
 
-	// 写入文件
-	return os.WriteFile(configPath, updatedContent, 0644)
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	return raw, nil
 }
 
-// AddSatisRequire 向 Satis 配置中添加依赖
-func (c *Composer) AddSatisRequire(configPath string, packageName string, version string) error {
-	// 读取现有配置
-	content, err := os.ReadFile(configPath)
-	if err != nil {
-		return err
+// marshalWithRaw 序列化结构体 v，raw 为读取时记录的原始字段
+//
+// 读取时存在且值没有变化的字段使用原始内容，修改过的字段使用新值，被修改为 nil 时省略；
+// 读取时不存在的字段只在不是零值时写出。结构体中没有定义的字段按名称排序追加在末尾。
+func marshalWithRaw(v interface{}, raw map[string]json.RawMessage) ([]byte, error) {
+	rv := reflect.ValueOf(v)
+	rt := rv.Type()
+
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	write := func(name string, value []byte) {
+		if buf.Len() > 1 {
+			buf.WriteByte(',')
+		}
+		key, _ := json.Marshal(name)
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
 	}
 
-	var config SatisConfig
-	if err := json.Unmarshal(content, &config); err != nil {
-		return err
+	for i := 0; i < rt.NumField(); i++ {
+		name, ok := jsonFieldName(rt.Field(i))
+		if !ok {
+			continue
+		}
+		field := rv.Field(i)
+		original, present := raw[name]
+		if !present && field.IsZero() {
+			continue
+		}
+
+		value, err := json.Marshal(field.Interface())
+		if err != nil {
+			return nil, err
+		}
+		if present {
+			if sameJSONValue(rt.Field(i).Type, original, value) {
+				value = original
+			} else if isNilValue(field) {
+				continue
+			}
+		}
+		write(name, value)
 	}
 
-	// 设置依赖，并关闭 require-all
-	if config.Require == nil {
-		config.Require = make(map[string]string)
+	known := knownJSONFields(rt)
+	names := make([]string, 0, len(raw))
+	for name := range raw {
+		if !known[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		write(name, raw[name])
 	}
 
-	config.Require[packageName] = version
-	config.RequireAll = false
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
 
-	// 序列化为 JSON
-	updatedContent, err := json.MarshalIndent(config, "", "    ")
-	if err != nil {
-		return err
+// sameJSONValue 判断 original 解析为类型 t 后再序列化的结果是否与 current 相同
+func sameJSONValue(t reflect.Type, original json.RawMessage, current []byte) bool {
+	decoded := reflect.New(t)
+	if err := json.Unmarshal(original, decoded.Interface()); err != nil {
+		return false
 	}
+	data, err := json.Marshal(decoded.Elem().Interface())
+	return err == nil && bytes.Equal(data, current)
+}
 
-	// 写入文件
-	return os.WriteFile(configPath, updatedContent, 0644)
+// isNilValue 判断映射、切片、指针或接口类型的值是否为 nil
+func isNilValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Map, reflect.Slice, reflect.Pointer, reflect.Interface:
+		return v.IsNil()
+	}
+	return false
 }
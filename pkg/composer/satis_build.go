@@ -0,0 +1,995 @@
+package composer
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"crypto/sha1"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/scagogogo/go-composer-sdk/pkg/packagist"
+	"github.com/scagogogo/go-composer-sdk/pkg/resolver"
+)
+
+var (
+	// ErrSatisConfigInvalid 表示 Satis 配置无效
+	ErrSatisConfigInvalid = errors.New("Satis 配置无效")
+	// ErrSatisRepositoryUnsupported 表示不支持的 Satis 仓库类型
+	ErrSatisRepositoryUnsupported = errors.New("不支持的 Satis 仓库类型")
+)
+
+// numericBranchPattern 匹配 1.x、2.0 这样按版本命名的分支
+var numericBranchPattern = regexp.MustCompile(`^v?\d+(\.(?:\d+|[xX*]))*$`)
+
+// rootOnlyKeys 是只对根包生效、不写入仓库元数据的 composer.json 字段
+var rootOnlyKeys = []string{"repositories", "config", "minimum-stability", "prefer-stable", "scripts-descriptions"}
+
+// SatisBuildResult 表示静态仓库的生成结果
+type SatisBuildResult struct {
+	// OutputDir 仓库的输出目录
+	OutputDir string `json:"output-dir"`
+	// Packages 写入仓库的包名，已排序
+	Packages []string `json:"packages"`
+	// Versions 写入仓库的版本总数
+	Versions int `json:"versions"`
+	// Archives 生成的分发包文件，相对于输出目录；设置了 archive.absolute-directory 时为下载地址中的路径
+	Archives []string `json:"archives,omitempty"`
+}
+
+// satisPackage 表示从仓库中读取到的一个包版本
+type satisPackage struct {
+	name    string
+	version *resolver.Version
+	data    map[string]interface{}
+	// archive 将该版本打包写入 w，为空表示无法生成分发包
+	archive func(format string, w io.Writer) error
+	// artifact 已有的分发包文件，生成归档时直接复制
+	artifact string
+	// reference 写入 dist 信息的引用，例如 Git 提交
+	reference string
+}
+
+// GenerateStaticRepository 不依赖 PHP 和 satis 命令，直接生成静态 Composer 仓库
+//
+// 参数：
+//   - config: Satis 配置
+//   - outputDir: 输出目录，为空时使用 config.OutputDir
+//
+// 返回值：
+//   - *SatisBuildResult: 生成结果
+//   - error: 如果配置无效、读取仓库或写入文件失败，则返回相应的错误信息
+//
+// 功能说明：
+//
+//	生成前会先调用 SatisConfig.Validate 校验配置，配置无效时不会写入任何文件。
+//	支持以下仓库类型：
+//	  - path: 本地目录，url 支持通配符，例如 packages/*
+//	  - artifact: 包含 zip/tar 分发包的目录
+//	  - vcs/git: 本地磁盘上的 Git 仓库，读取全部标签和分支，需要 git 命令
+//	  - package: 内联的包定义
+//	config.Require 为空且未设置 require-all 时收录全部包；否则只收录满足约束的版本，
+//	require-dependencies 和 require-dev-dependencies 会递归收录仓库中能满足依赖的版本。
+//	输出目录中会写入 packages.json 和 p2/ 下的 Composer v2 压缩格式元数据；
+//	设置了 archive.directory 时会为每个版本生成分发包并写入 dist 信息，
+//	分发包地址以 archive.prefix-url 或 homepage 为前缀。
+//	blacklist、whitelist 和仓库的 exclude 在收录依赖之前生效，abandoned 和 strip-hosts 会写入生成的元数据，
+//	设置了 include-filename 时额外生成 Composer 1 使用的 include 文件。
+//
+// 用法示例：
+//
+//	config := composer.SatisConfig{
+//	    Name:         "acme/repo",
+//	    Homepage:     "https://packages.example.com",
+//	    Repositories: []composer.SatisRepository{{Type: "path", URL: "packages/*"}},
+//	    RequireAll:   true,
+//	    Archive:      &composer.SatisArchive{Directory: "dist"},
+//	}
+//	result, err := composer.GenerateStaticRepository(config, "public")
+func GenerateStaticRepository(config SatisConfig, outputDir string) (*SatisBuildResult, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	if outputDir == "" {
+		outputDir = config.OutputDir
+	}
+	if outputDir == "" {
+		return nil, fmt.Errorf("%w: 未指定输出目录", ErrSatisConfigInvalid)
+	}
+
+	minimum := resolver.StabilityDev
+	if s, ok := resolver.ParseStability(config.MinimumStability); ok {
+		minimum = s
+	}
+
+	blacklist, err := parseSatisLinks(config.Blacklist)
+	if err != nil {
+		return nil, err
+	}
+	whitelist, err := parseSatisLinks(config.Whitelist)
+	if err != nil {
+		return nil, err
+	}
+
+	available := make(map[string][]*satisPackage)
+	for i, repo := range config.Repositories {
+		packages, err := loadSatisRepository(repo)
+		if err != nil {
+			return nil, fmt.Errorf("读取仓库 #%d (%s %s) 失败: %w", i+1, repo.Type, repo.URL, err)
+		}
+		for _, p := range packages {
+			if matchesAnyPattern(p.name, repo.Exclude) || !allowedBySatisLists(p, blacklist, whitelist) {
+				continue
+			}
+			available[p.name] = append(available[p.name], p)
+		}
+	}
+
+	selected, err := selectSatisPackages(config, available, minimum)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range selected {
+		if value, ok := config.Abandoned[p.name]; ok {
+			p.data["abandoned"] = value
+		}
+	}
+
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return nil, err
+	}
+
+	result := &SatisBuildResult{OutputDir: outputDir}
+	if err := writeSatisArchives(config, outputDir, selected, result); err != nil {
+		return nil, err
+	}
+	if len(config.StripHosts) > 0 {
+		for _, p := range selected {
+			stripSatisHosts(p.data, config.StripHosts)
+		}
+	}
+	if err := writeSatisMetadata(config, outputDir, selected, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// BuildSatisNative 读取 Satis 配置文件并使用 GenerateStaticRepository 生成静态仓库
+//
+// 参数：
+//   - configPath: satis.json 配置文件路径
+//   - outputDir: 输出目录，为空时使用配置中的 output-dir
+//
+// 返回值：
+//   - *SatisBuildResult: 生成结果
+//   - error: 如果读取配置或生成失败，则返回相应的错误信息
+//
+// 功能说明：
+//
+//	与 BuildSatis 不同，该方法不调用外部 satis 命令。
+//	配置中 path、artifact 和本地 vcs 仓库的相对路径以及相对的 output-dir 均相对于配置文件所在目录。
+func (c *Composer) BuildSatisNative(configPath string, outputDir string) (*SatisBuildResult, error) {
+	config, err := LoadSatisConfig(configPath)
+	if err != nil {
+		return nil, err
+	}
+	// 在相对路径被转换为绝对路径之前校验，避免 .. 在拼接后被清理掉
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	baseDir := filepath.Dir(configPath)
+	for i, repo := range config.Repositories {
+		switch repo.Type {
+		case "path", "artifact", "vcs", "git":
+			if repo.URL != "" && !filepath.IsAbs(repo.URL) && !strings.Contains(repo.URL, "://") {
+				config.Repositories[i].URL = filepath.Join(baseDir, repo.URL)
+			}
+		}
+	}
+	if outputDir == "" && config.OutputDir != "" && !filepath.IsAbs(config.OutputDir) {
+		config.OutputDir = filepath.Join(baseDir, config.OutputDir)
+	}
+
+	return GenerateStaticRepository(*config, outputDir)
+}
+
+// loadSatisRepository 读取单个仓库中的全部包版本
+func loadSatisRepository(repo SatisRepository) ([]*satisPackage, error) {
+	switch repo.Type {
+	case "path":
+		versions, _ := repo.Options["versions"].(map[string]interface{})
+		return loadSatisPathRepository(repo.URL, versions)
+	case "artifact":
+		return loadSatisArtifactRepository(repo.URL)
+	case "vcs", "git":
+		if strings.Contains(repo.URL, "://") || strings.Contains(repo.URL, "@") {
+			return nil, fmt.Errorf("%w: 只支持本地磁盘上的 Git 仓库", ErrSatisRepositoryUnsupported)
+		}
+		return loadSatisGitRepository(repo.URL)
+	case "package":
+		return loadSatisPackageRepository(repo.Package)
+	}
+	return nil, fmt.Errorf("%w: %s", ErrSatisRepositoryUnsupported, repo.Type)
+}
+
+// loadSatisPathRepository 读取 path 仓库，url 支持通配符，versions 按包名指定版本号
+func loadSatisPathRepository(pattern string, versions map[string]interface{}) ([]*satisPackage, error) {
+	dirs, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(dirs)
+
+	var packages []*satisPackage
+	for _, dir := range dirs {
+		content, err := os.ReadFile(filepath.Join(dir, "composer.json"))
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return nil, err
+		}
+
+		absDir, err := filepath.Abs(dir)
+		if err != nil {
+			return nil, err
+		}
+		p, err := newSatisPackage(content, "dev-main")
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", dir, err)
+		}
+		if version, ok := versions[p.name].(string); ok {
+			if err := p.setVersion(version); err != nil {
+				return nil, fmt.Errorf("%s: %w", dir, err)
+			}
+		}
+		p.data["dist"] = map[string]interface{}{"type": "path", "url": absDir}
+		p.archive = func(format string, w io.Writer) error {
+			return archiveDirectory(absDir, format, w)
+		}
+		packages = append(packages, p)
+	}
+	return packages, nil
+}
+
+// loadSatisArtifactRepository 读取 artifact 仓库目录中的 zip 和 tar 分发包
+func loadSatisArtifactRepository(dir string) ([]*satisPackage, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var packages []*satisPackage
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		file := filepath.Join(dir, entry.Name())
+		format := artifactFormat(entry.Name())
+		if format == "" {
+			continue
+		}
+
+		content, err := readArtifactComposerJSON(file, format)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		p, err := newSatisPackage(content, "")
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+
+		absFile, err := filepath.Abs(file)
+		if err != nil {
+			return nil, err
+		}
+		sum, err := packagist.FileSHA1(absFile)
+		if err != nil {
+			return nil, err
+		}
+		p.data["dist"] = map[string]interface{}{
+			"type":   format,
+			"url":    (&url.URL{Scheme: "file", Path: filepath.ToSlash(absFile)}).String(),
+			"shasum": sum,
+		}
+		p.artifact = absFile
+		packages = append(packages, p)
+	}
+	return packages, nil
+}
+
+// loadSatisGitRepository 读取本地 Git 仓库的全部标签和分支
+func loadSatisGitRepository(dir string) ([]*satisPackage, error) {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := runGit(absDir, "for-each-ref", "--format=%(refname)", "refs/tags", "refs/heads")
+	if err != nil {
+		return nil, err
+	}
+
+	var packages []*satisPackage
+	for _, ref := range strings.Fields(output) {
+		var version string
+		switch {
+		case strings.HasPrefix(ref, "refs/tags/"):
+			version = strings.TrimPrefix(ref, "refs/tags/")
+			if _, err := resolver.ParseVersion(version); err != nil || strings.HasPrefix(strings.ToLower(version), "dev-") {
+				continue
+			}
+		case strings.HasPrefix(ref, "refs/heads/"):
+			version = branchVersion(strings.TrimPrefix(ref, "refs/heads/"))
+		default:
+			continue
+		}
+
+		commit, err := runGit(absDir, "rev-parse", ref+"^{commit}")
+		if err != nil {
+			return nil, err
+		}
+		commit = strings.TrimSpace(commit)
+
+		content, err := runGit(absDir, "show", commit+":composer.json")
+		if err != nil {
+			// 没有 composer.json 的引用不是 Composer 包
+			continue
+		}
+
+		p, err := newSatisPackage([]byte(content), version)
+		if err != nil {
+			continue
+		}
+		// 标签和分支名决定版本，忽略 composer.json 中的 version 字段
+		if err := p.setVersion(version); err != nil {
+			continue
+		}
+		p.data["source"] = map[string]interface{}{"type": "git", "url": absDir, "reference": commit}
+		p.archive = func(format string, w io.Writer) error {
+			cmd := exec.Command("git", "-C", absDir, "archive", "--format="+format, commit)
+			cmd.Stdout = w
+			var stderr bytes.Buffer
+			cmd.Stderr = &stderr
+			if err := cmd.Run(); err != nil {
+				return fmt.Errorf("git archive 失败: %w: %s", err, strings.TrimSpace(stderr.String()))
+			}
+			return nil
+		}
+		p.reference = commit
+		packages = append(packages, p)
+	}
+	return packages, nil
+}
+
+// loadSatisPackageRepository 读取 package 仓库中内联的包定义
+func loadSatisPackageRepository(raw json.RawMessage) ([]*satisPackage, error) {
+	trimmed := bytes.TrimSpace(raw)
+	if len(trimmed) == 0 {
+		return nil, fmt.Errorf("%w: package 仓库缺少 package 字段", ErrSatisConfigInvalid)
+	}
+
+	var items []json.RawMessage
+	if trimmed[0] == '[' {
+		if err := json.Unmarshal(trimmed, &items); err != nil {
+			return nil, err
+		}
+	} else {
+		items = []json.RawMessage{trimmed}
+	}
+
+	packages := make([]*satisPackage, 0, len(items))
+	for _, item := range items {
+		p, err := newSatisPackage(item, "")
+		if err != nil {
+			return nil, err
+		}
+		packages = append(packages, p)
+	}
+	return packages, nil
+}
+
+// newSatisPackage 从 composer.json 内容创建包版本，缺少版本号时使用 defaultVersion
+func newSatisPackage(content []byte, defaultVersion string) (*satisPackage, error) {
+	var data map[string]interface{}
+	if err := json.Unmarshal(content, &data); err != nil {
+		return nil, err
+	}
+
+	name, _ := data["name"].(string)
+	if name == "" {
+		return nil, fmt.Errorf("%w: 包缺少 name 字段", ErrSatisConfigInvalid)
+	}
+	for _, key := range rootOnlyKeys {
+		delete(data, key)
+	}
+
+	p := &satisPackage{name: strings.ToLower(name), data: data}
+	data["name"] = p.name
+
+	version, _ := data["version"].(string)
+	if version == "" {
+		version = defaultVersion
+	}
+	if version == "" {
+		return nil, fmt.Errorf("%w: %s 缺少 version 字段", ErrSatisConfigInvalid, name)
+	}
+	if err := p.setVersion(version); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// setVersion 设置包的版本号和规范化版本号
+func (p *satisPackage) setVersion(version string) error {
+	v, err := resolver.ParseVersion(version)
+	if err != nil {
+		return err
+	}
+	p.version = v
+	p.data["version"] = version
+	p.data["version_normalized"] = v.Normalized()
+	return nil
+}
+
+// branchVersion 将分支名转换为 Composer 的开发版本号，例如 main -> dev-main，2.x -> 2.x-dev
+func branchVersion(branch string) string {
+	if numericBranchPattern.MatchString(branch) {
+		if strings.HasSuffix(strings.ToLower(branch), ".x") {
+			return branch + "-dev"
+		}
+		return branch + ".x-dev"
+	}
+	return "dev-" + branch
+}
+
+// selectSatisPackages 按 require 和依赖选项选出要写入仓库的版本
+func selectSatisPackages(config SatisConfig, available map[string][]*satisPackage, minimum resolver.Stability) ([]*satisPackage, error) {
+	selected := make(map[*satisPackage]bool)
+	var queue []*satisPackage
+	add := func(p *satisPackage) {
+		if !selected[p] {
+			selected[p] = true
+			queue = append(queue, p)
+		}
+	}
+
+	if config.RequireAll || len(config.Require) == 0 {
+		for _, versions := range available {
+			for _, p := range versions {
+				if minimum.Allows(p.version.Stability()) {
+					add(p)
+				}
+			}
+		}
+	} else {
+		for name, raw := range config.Require {
+			con, err := resolver.ParseConstraint(raw)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s: %v", ErrSatisConfigInvalid, name, err)
+			}
+			stability := resolver.LessStable(minimum, con.Stability())
+			for _, p := range available[strings.ToLower(name)] {
+				if stability.Allows(p.version.Stability()) && con.Matches(p.version) {
+					add(p)
+				}
+			}
+		}
+	}
+
+	if config.RequireDependencies || config.RequireDevDependencies {
+		for len(queue) > 0 {
+			p := queue[0]
+			queue = queue[1:]
+
+			links := make(map[string]string)
+			if config.RequireDependencies {
+				mergeLinks(links, p.data["require"])
+			}
+			if config.RequireDevDependencies {
+				mergeLinks(links, p.data["require-dev"])
+			}
+			for name, raw := range links {
+				if resolver.IsPlatformPackage(name) {
+					continue
+				}
+				con, err := resolver.ParseConstraint(raw)
+				if err != nil {
+					// 无法解析的依赖约束不影响其他包的收录
+					continue
+				}
+				stability := resolver.LessStable(minimum, con.Stability())
+				for _, dep := range available[strings.ToLower(name)] {
+					if stability.Allows(dep.version.Stability()) && con.Matches(dep.version) {
+						add(dep)
+					}
+				}
+			}
+		}
+	}
+
+	result := make([]*satisPackage, 0, len(selected))
+	for p := range selected {
+		result = append(result, p)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].name != result[j].name {
+			return result[i].name < result[j].name
+		}
+		// 同名包按版本从新到旧排列
+		return result[i].version.Compare(result[j].version) > 0
+	})
+	return result, nil
+}
+
+// mergeLinks 将 require 一类字段合并到 links 中
+func mergeLinks(links map[string]string, value interface{}) {
+	m, _ := value.(map[string]interface{})
+	for name, constraint := range m {
+		if s, ok := constraint.(string); ok {
+			links[strings.ToLower(name)] = s
+		}
+	}
+}
+
+// writeSatisArchives 按 archive 配置为选中的版本生成分发包
+func writeSatisArchives(config SatisConfig, outputDir string, packages []*satisPackage, result *SatisBuildResult) error {
+	archive := config.Archive
+	if archive == nil || archive.Directory == "" {
+		return nil
+	}
+
+	format := archive.Format
+	if format == "" {
+		format = "zip"
+	}
+	if format != "zip" && format != "tar" {
+		return fmt.Errorf("%w: 不支持的归档格式 %q", ErrSatisConfigInvalid, format)
+	}
+	prefix := archive.PrefixURL
+	if prefix == "" {
+		prefix = config.Homepage
+	}
+
+	for _, p := range packages {
+		if archive.SkipDev && p.version.Stability() == resolver.StabilityDev {
+			continue
+		}
+		if p.archive == nil && p.artifact == "" {
+			continue
+		}
+		if len(archive.Whitelist) > 0 && !matchesAnyPattern(p.name, archive.Whitelist) {
+			continue
+		}
+		if matchesAnyPattern(p.name, archive.Blacklist) {
+			continue
+		}
+
+		fileFormat := format
+		if p.artifact != "" {
+			fileFormat = artifactFormat(p.artifact)
+		}
+		file := path.Join(p.name, mirrorFileName(packagist.Version{
+			Name:    p.name,
+			Version: p.version.Original(),
+			Dist:    &packagist.Dist{Type: fileFormat},
+		}))
+		rel := path.Join(archive.Directory, file)
+		target := filepath.Join(outputDir, filepath.FromSlash(rel))
+		if archive.AbsoluteDirectory != "" {
+			target = filepath.Join(archive.AbsoluteDirectory, filepath.FromSlash(file))
+		}
+
+		if err := writeSatisArchive(p, fileFormat, target); err != nil {
+			return fmt.Errorf("生成 %s %s 的分发包失败: %w", p.name, p.version.Original(), err)
+		}
+		sum, err := packagist.FileSHA1(target)
+		if err != nil {
+			return err
+		}
+
+		dist := map[string]interface{}{
+			"type": fileFormat,
+			"url":  strings.TrimRight(prefix, "/") + "/" + rel,
+		}
+		if archive.ChecksumEnabled() {
+			dist["shasum"] = sum
+		}
+		if p.reference != "" {
+			dist["reference"] = p.reference
+		}
+		p.data["dist"] = dist
+		result.Archives = append(result.Archives, rel)
+	}
+	return nil
+}
+
+// writeSatisArchive 将单个版本的分发包写入 target，先写临时文件再重命名
+func writeSatisArchive(p *satisPackage, format string, target string) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(target), ".archive-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if p.artifact != "" {
+		var src *os.File
+		src, err = os.Open(p.artifact)
+		if err == nil {
+			_, err = io.Copy(tmp, src)
+			src.Close()
+		}
+	} else {
+		err = p.archive(format, tmp)
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), target)
+}
+
+// writeSatisMetadata 写入 packages.json 和 p2/ 元数据文件
+func writeSatisMetadata(config SatisConfig, outputDir string, packages []*satisPackage, result *SatisBuildResult) error {
+	byName := make(map[string][]map[string]interface{})
+	devByName := make(map[string][]map[string]interface{})
+	var names []string
+	for _, p := range packages {
+		if _, ok := byName[p.name]; !ok {
+			if _, ok := devByName[p.name]; !ok {
+				names = append(names, p.name)
+			}
+		}
+		if p.version.Stability() == resolver.StabilityDev {
+			devByName[p.name] = append(devByName[p.name], p.data)
+		} else {
+			byName[p.name] = append(byName[p.name], p.data)
+		}
+		result.Versions++
+	}
+	sort.Strings(names)
+	result.Packages = names
+
+	p2Dir := filepath.Join(outputDir, "p2")
+	if err := os.RemoveAll(p2Dir); err != nil {
+		return err
+	}
+	for _, name := range names {
+		if err := writeP2File(filepath.Join(p2Dir, filepath.FromSlash(name)+".json"), name, byName[name]); err != nil {
+			return err
+		}
+		if err := writeP2File(filepath.Join(p2Dir, filepath.FromSlash(name)+"~dev.json"), name, devByName[name]); err != nil {
+			return err
+		}
+	}
+
+	metadataURL := "/p2/%package%.json"
+	if u, err := url.Parse(config.Homepage); err == nil && strings.Trim(u.Path, "/") != "" {
+		metadataURL = "/" + strings.Trim(u.Path, "/") + metadataURL
+	}
+
+	root := map[string]interface{}{
+		"packages":           map[string]interface{}{},
+		"metadata-url":       metadataURL,
+		"available-packages": names,
+	}
+	if names == nil {
+		root["available-packages"] = []string{}
+	}
+
+	if config.IncludeFilename != "" {
+		includes, err := writeSatisInclude(outputDir, config.IncludeFilename, packages)
+		if err != nil {
+			return err
+		}
+		root["includes"] = includes
+	}
+	return writeJSONFile(filepath.Join(outputDir, "packages.json"), root)
+}
+
+// writeSatisInclude 写入 Composer 1 使用的 include 文件，返回 packages.json 中的 includes 字段
+//
+// 文件名中的 %hash% 会被替换为文件内容的 SHA-1。
+func writeSatisInclude(outputDir string, filename string, packages []*satisPackage) (map[string]interface{}, error) {
+	all := make(map[string]map[string]interface{})
+	for _, p := range packages {
+		if all[p.name] == nil {
+			all[p.name] = make(map[string]interface{})
+		}
+		all[p.name][p.version.Original()] = p.data
+	}
+
+	content, err := json.MarshalIndent(map[string]interface{}{"packages": all}, "", "    ")
+	if err != nil {
+		return nil, err
+	}
+	sum := fmt.Sprintf("%x", sha1.Sum(content))
+	name := strings.ReplaceAll(filename, "%hash%", sum)
+
+	file := filepath.Join(outputDir, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(file, content, 0644); err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{name: map[string]string{"sha1": sum}}, nil
+}
+
+// parseSatisLinks 解析 blacklist、whitelist 中的版本约束
+func parseSatisLinks(links map[string]string) (map[string]*resolver.Constraint, error) {
+	if len(links) == 0 {
+		return nil, nil
+	}
+	result := make(map[string]*resolver.Constraint, len(links))
+	for name, raw := range links {
+		con, err := resolver.ParseConstraint(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", ErrSatisConfigInvalid, name, err)
+		}
+		result[strings.ToLower(name)] = con
+	}
+	return result, nil
+}
+
+// allowedBySatisLists 判断版本是否未被 blacklist 排除，且在设置了 whitelist 时包含在其中
+func allowedBySatisLists(p *satisPackage, blacklist, whitelist map[string]*resolver.Constraint) bool {
+	if con, ok := blacklist[p.name]; ok && con.Matches(p.version) {
+		return false
+	}
+	if len(whitelist) > 0 {
+		con, ok := whitelist[p.name]
+		return ok && con.Matches(p.version)
+	}
+	return true
+}
+
+// matchesAnyPattern 判断包名是否匹配任一通配符模式
+func matchesAnyPattern(name string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(strings.ToLower(pattern), name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// stripSatisHosts 移除地址匹配 strip-hosts 的 dist 和 source
+func stripSatisHosts(data map[string]interface{}, hosts []string) {
+	for _, key := range []string{"dist", "source"} {
+		ref, _ := data[key].(map[string]interface{})
+		address, _ := ref["url"].(string)
+		if address != "" && matchesStripHost(address, hosts) {
+			delete(data, key)
+		}
+	}
+}
+
+// matchesStripHost 判断地址是否匹配 strip-hosts 中的任一规则
+func matchesStripHost(address string, hosts []string) bool {
+	var host string
+	local := false
+	if u, err := url.Parse(address); err == nil && u.Scheme != "" && len(u.Scheme) > 1 {
+		host = u.Hostname()
+		local = u.Scheme == "file"
+	} else if i := strings.Index(address, "@"); i >= 0 && strings.Contains(address[i:], ":") {
+		// git@example.com:vendor/repo.git 形式的 SSH 地址
+		host = address[i+1 : i+strings.Index(address[i:], ":")]
+	} else {
+		local = true
+	}
+
+	ip := net.ParseIP(host)
+	for _, rule := range hosts {
+		switch {
+		case rule == "/local":
+			if local || host == "localhost" || (ip != nil && ip.IsLoopback()) {
+				return true
+			}
+		case rule == "/private":
+			if ip != nil && (ip.IsPrivate() || ip.IsLoopback() || ip.IsLinkLocalUnicast()) {
+				return true
+			}
+		case strings.Contains(rule, "/"):
+			if _, network, err := net.ParseCIDR(rule); err == nil && ip != nil && network.Contains(ip) {
+				return true
+			}
+		case strings.HasPrefix(rule, "."):
+			if host != "" && strings.HasSuffix(strings.ToLower(host), strings.ToLower(rule)) {
+				return true
+			}
+		default:
+			if host != "" && strings.EqualFold(host, rule) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// writeP2File 以 Composer v2 压缩格式写入单个包的元数据文件
+func writeP2File(file string, name string, versions []map[string]interface{}) error {
+	if versions == nil {
+		versions = []map[string]interface{}{}
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return err
+	}
+	return writeJSONFile(file, map[string]interface{}{
+		"minified": "composer/2.0",
+		"packages": map[string]interface{}{name: packagist.MinifyVersions(versions)},
+	})
+}
+
+// writeJSONFile 将值序列化后写入文件
+func writeJSONFile(file string, value interface{}) error {
+	content, err := json.MarshalIndent(value, "", "    ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(file, content, 0644)
+}
+
+// artifactFormat 根据文件名返回分发包格式，不支持的文件返回空字符串
+func artifactFormat(name string) string {
+	lower := strings.ToLower(name)
+	switch {
+	case strings.HasSuffix(lower, ".zip"):
+		return "zip"
+	case strings.HasSuffix(lower, ".tar"):
+		return "tar"
+	}
+	return ""
+}
+
+// readArtifactComposerJSON 读取分发包根目录或第一层子目录中的 composer.json
+func readArtifactComposerJSON(file string, format string) ([]byte, error) {
+	if format == "zip" {
+		r, err := zip.OpenReader(file)
+		if err != nil {
+			return nil, err
+		}
+		defer r.Close()
+		for _, f := range r.File {
+			if isArtifactComposerJSON(f.Name) {
+				rc, err := f.Open()
+				if err != nil {
+					return nil, err
+				}
+				defer rc.Close()
+				return io.ReadAll(rc)
+			}
+		}
+		return nil, fmt.Errorf("分发包中没有 composer.json")
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	tr := tar.NewReader(f)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil, fmt.Errorf("分发包中没有 composer.json")
+		}
+		if err != nil {
+			return nil, err
+		}
+		if isArtifactComposerJSON(header.Name) {
+			return io.ReadAll(tr)
+		}
+	}
+}
+
+// isArtifactComposerJSON 判断归档中的文件是否为包的 composer.json
+func isArtifactComposerJSON(name string) bool {
+	name = strings.TrimPrefix(name, "./")
+	if name == "composer.json" {
+		return true
+	}
+	dir, base := path.Split(name)
+	return base == "composer.json" && strings.Count(strings.Trim(dir, "/"), "/") == 0 && dir != ""
+}
+
+// archiveDirectory 将目录打包为 zip 或 tar，忽略 .git 等版本控制目录
+func archiveDirectory(dir string, format string, w io.Writer) error {
+	var zw *zip.Writer
+	var tw *tar.Writer
+	if format == "zip" {
+		zw = zip.NewWriter(w)
+	} else {
+		tw = tar.NewWriter(w)
+	}
+
+	err := filepath.Walk(dir, func(file string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, file)
+		if err != nil || rel == "." {
+			return err
+		}
+		if info.IsDir() {
+			switch info.Name() {
+			case ".git", ".svn", ".hg":
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		name := filepath.ToSlash(rel)
+
+		src, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+		defer src.Close()
+
+		if zw != nil {
+			header, err := zip.FileInfoHeader(info)
+			if err != nil {
+				return err
+			}
+			header.Name = name
+			header.Method = zip.Deflate
+			dst, err := zw.CreateHeader(header)
+			if err != nil {
+				return err
+			}
+			_, err = io.Copy(dst, src)
+			return err
+		}
+
+		header, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		header.Name = name
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		_, err = io.Copy(tw, src)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	if zw != nil {
+		return zw.Close()
+	}
+	return tw.Close()
+}
+
+// runGit 在指定目录执行 git 命令并返回标准输出
+func runGit(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("git %s 失败: %w: %s", strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
@@ -0,0 +1,291 @@
+package composer
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/scagogogo/go-composer-sdk/pkg/packagist"
+	"github.com/scagogogo/go-composer-sdk/pkg/resolver"
+)
+
+func writeTestFile(t *testing.T, file string, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		t.Fatalf("创建目录失败: %v", err)
+	}
+	if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatalf("写入文件失败: %v", err)
+	}
+}
+
+// writeTestZip 创建只包含一个文件的zip归档
+func writeTestZip(t *testing.T, file, name, content string) {
+	t.Helper()
+	zf, err := os.Create(file)
+	if err != nil {
+		t.Fatalf("创建归档失败: %v", err)
+	}
+	defer zf.Close()
+	zw := zip.NewWriter(zf)
+	w, err := zw.Create(name)
+	if err != nil {
+		t.Fatalf("创建归档文件失败: %v", err)
+	}
+	if _, err := w.Write([]byte(content)); err != nil {
+		t.Fatalf("写入归档文件失败: %v", err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("关闭归档失败: %v", err)
+	}
+}
+
+func TestGenerateStaticRepository(t *testing.T) {
+	dir := t.TempDir()
+
+	writeTestFile(t, filepath.Join(dir, "packages", "app", "composer.json"),
+		`{"name": "Acme/App", "version": "1.2.0", "require": {"php": ">=8.1", "acme/util": "^2.0"}, "config": {"sort-packages": true}}`)
+	writeTestFile(t, filepath.Join(dir, "packages", "app", "src", "App.php"), "<?php\n")
+
+	artifactDir := filepath.Join(dir, "artifacts")
+	if err := os.MkdirAll(artifactDir, 0755); err != nil {
+		t.Fatalf("创建目录失败: %v", err)
+	}
+	writeTestZip(t, filepath.Join(artifactDir, "util-2.1.0.zip"), "util/composer.json", `{"name": "acme/util", "version": "2.1.0"}`)
+
+	config := SatisConfig{
+		Name:     "acme/repo",
+		Homepage: "https://packages.example.com/private",
+		Repositories: []SatisRepository{
+			{Type: "path", URL: filepath.Join(dir, "packages", "*")},
+			{Type: "artifact", URL: artifactDir},
+			{Type: "package", Package: json.RawMessage(`[
+				{"name": "acme/util", "version": "1.0.0"},
+				{"name": "acme/unused", "version": "1.0.0"},
+				{"name": "acme/util", "version": "dev-main"}
+			]`)},
+		},
+		Require:             map[string]string{"acme/app": "^1.0"},
+		RequireDependencies: true,
+		Archive:             &SatisArchive{Directory: "dist"},
+	}
+
+	out := filepath.Join(dir, "public")
+	result, err := GenerateStaticRepository(config, out)
+	if err != nil {
+		t.Fatalf("生成仓库失败: %v", err)
+	}
+	if expected := []string{"acme/app", "acme/util"}; !reflect.DeepEqual(result.Packages, expected) {
+		t.Errorf("包列表错误: 期望 %v，实际 %v", expected, result.Packages)
+	}
+	if result.Versions != 2 {
+		t.Errorf("期望 2 个版本，实际 %d 个", result.Versions)
+	}
+	if len(result.Archives) != 2 {
+		t.Errorf("期望 2 个归档，实际 %v", result.Archives)
+	}
+
+	var root map[string]interface{}
+	data, err := os.ReadFile(filepath.Join(out, "packages.json"))
+	if err != nil {
+		t.Fatalf("读取 packages.json 失败: %v", err)
+	}
+	if err := json.Unmarshal(data, &root); err != nil {
+		t.Fatalf("解析 packages.json 失败: %v", err)
+	}
+	if root["metadata-url"] != "/private/p2/%package%.json" {
+		t.Errorf("metadata-url 错误: %v", root["metadata-url"])
+	}
+
+	// 生成的仓库可以被解析器直接读取
+	repo, err := resolver.LoadRepository(out)
+	if err != nil {
+		t.Fatalf("加载仓库失败: %v", err)
+	}
+
+	apps, err := repo.FindPackages("acme/app")
+	if err != nil || len(apps) != 1 {
+		t.Fatalf("期望找到 1 个 acme/app 版本，实际 %d 个，错误: %v", len(apps), err)
+	}
+	if apps[0].Version != "1.2.0" || apps[0].VersionNormalized != "1.2.0.0" {
+		t.Errorf("版本错误: %s (%s)", apps[0].Version, apps[0].VersionNormalized)
+	}
+	if apps[0].Dist == nil {
+		t.Fatal("缺少 dist 信息")
+	}
+	if expected := "https://packages.example.com/private/dist/acme/app/acme-app-1.2.0.zip"; apps[0].Dist.URL != expected {
+		t.Errorf("dist 地址错误: 期望 %s，实际 %s", expected, apps[0].Dist.URL)
+	}
+
+	sum, err := packagist.FileSHA1(filepath.Join(out, "dist", "acme", "app", "acme-app-1.2.0.zip"))
+	if err != nil {
+		t.Fatalf("计算归档校验和失败: %v", err)
+	}
+	if apps[0].Dist.Shasum != sum {
+		t.Errorf("shasum 错误: 期望 %s，实际 %s", sum, apps[0].Dist.Shasum)
+	}
+
+	utils, err := repo.FindPackages("acme/util")
+	if err != nil || len(utils) != 1 {
+		t.Fatalf("期望找到 1 个 acme/util 版本，实际 %d 个，错误: %v", len(utils), err)
+	}
+	if utils[0].Version != "2.1.0" {
+		t.Errorf("acme/util 版本错误: %s", utils[0].Version)
+	}
+
+	// 只对根包生效的字段不会写入仓库
+	content, err := os.ReadFile(filepath.Join(out, "p2", "acme", "app.json"))
+	if err != nil {
+		t.Fatalf("读取包元数据失败: %v", err)
+	}
+	if strings.Contains(string(content), "sort-packages") {
+		t.Error("仓库中不应包含 config 字段")
+	}
+	if _, err := os.Stat(filepath.Join(out, "p2", "acme", "app~dev.json")); err != nil {
+		t.Errorf("缺少开发版本元数据: %v", err)
+	}
+}
+
+func TestGenerateStaticRepositoryGit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git 不可用")
+	}
+
+	dir := t.TempDir()
+	src := filepath.Join(dir, "lib")
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", src, "-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v 失败: %v\n%s", args, err, output)
+		}
+	}
+
+	writeTestFile(t, filepath.Join(src, "composer.json"), `{"name": "acme/lib"}`)
+	git("init", "-q", "-b", "main")
+	git("add", "-A")
+	git("commit", "-q", "-m", "initial")
+	git("tag", "v1.0.0")
+	git("branch", "2.x")
+
+	config := SatisConfig{
+		Name:             "acme/repo",
+		Homepage:         "https://packages.example.com",
+		Repositories:     []SatisRepository{{Type: "vcs", URL: src}},
+		RequireAll:       true,
+		MinimumStability: "dev",
+		Archive:          &SatisArchive{Directory: "dist", SkipDev: true},
+	}
+	out := filepath.Join(dir, "public")
+	result, err := GenerateStaticRepository(config, out)
+	if err != nil {
+		t.Fatalf("生成仓库失败: %v", err)
+	}
+	if result.Versions != 3 {
+		t.Errorf("期望 3 个版本，实际 %d 个", result.Versions)
+	}
+	if expected := []string{"dist/acme/lib/acme-lib-v1.0.0.zip"}; !reflect.DeepEqual(result.Archives, expected) {
+		t.Errorf("归档错误: 期望 %v，实际 %v", expected, result.Archives)
+	}
+
+	repo, err := resolver.LoadRepository(out)
+	if err != nil {
+		t.Fatalf("加载仓库失败: %v", err)
+	}
+	versions, err := repo.FindPackages("acme/lib")
+	if err != nil {
+		t.Fatalf("查找包失败: %v", err)
+	}
+
+	found := make(map[string]packagist.Version)
+	for _, v := range versions {
+		found[v.Version] = v
+	}
+	for _, version := range []string{"dev-main", "2.x-dev"} {
+		if _, ok := found[version]; !ok {
+			t.Errorf("缺少版本 %s", version)
+		}
+	}
+	tagged, ok := found["v1.0.0"]
+	if !ok || tagged.Source == nil || tagged.Dist == nil {
+		t.Fatalf("版本 v1.0.0 缺少 source 或 dist: %+v", tagged)
+	}
+	if tagged.Source.Reference != tagged.Dist.Reference {
+		t.Errorf("dist 引用 %s 与 source 引用 %s 不一致", tagged.Dist.Reference, tagged.Source.Reference)
+	}
+
+	// 跳过归档的开发版本不会带出内部使用的字段
+	for _, file := range []string{"lib.json", "lib~dev.json"} {
+		content, err := os.ReadFile(filepath.Join(out, "p2", "acme", file))
+		if err != nil {
+			t.Fatalf("读取包元数据失败: %v", err)
+		}
+		if strings.Contains(string(content), "dist-reference") {
+			t.Errorf("%s 中不应包含 dist-reference: %s", file, content)
+		}
+	}
+}
+
+func TestBuildSatisNative(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "packages", "a", "composer.json"), `{"name": "acme/a", "version": "1.0.0-beta1"}`)
+	writeTestFile(t, filepath.Join(dir, "satis.json"), `{
+		"name": "acme/repo",
+		"homepage": "https://packages.example.com",
+		"repositories": [{"type": "path", "url": "packages/*"}],
+		"output-dir": "public",
+		"minimum-stability": "stable"
+	}`)
+
+	c := &Composer{}
+	result, err := c.BuildSatisNative(filepath.Join(dir, "satis.json"), "")
+	if err != nil {
+		t.Fatalf("构建仓库失败: %v", err)
+	}
+	if result.OutputDir != filepath.Join(dir, "public") {
+		t.Errorf("输出目录错误: %s", result.OutputDir)
+	}
+	if len(result.Packages) != 0 {
+		t.Errorf("不稳定版本不应被收录: %v", result.Packages)
+	}
+
+	if _, err := c.BuildSatisNative(filepath.Join(dir, "satis.json"), ""); err != nil {
+		t.Errorf("重复构建失败: %v", err)
+	}
+
+	writeTestFile(t, filepath.Join(dir, "bad.json"), `{
+		"name": "acme/repo",
+		"homepage": "https://packages.example.com",
+		"repositories": [{"type": "svn", "url": "x"}],
+		"output-dir": "out"
+	}`)
+	if _, err := c.BuildSatisNative(filepath.Join(dir, "bad.json"), ""); !errors.Is(err, ErrSatisRepositoryUnsupported) {
+		t.Errorf("期望错误 %v，但得到 %v", ErrSatisRepositoryUnsupported, err)
+	}
+
+	// 指向输出目录之外的路径在生成之前就被拒绝
+	for name, content := range map[string]string{
+		"escape-output.json":    `"output-dir": "../.."`,
+		"escape-archive.json":   `"output-dir": "out", "archive": {"directory": "../../dist"}`,
+		"absolute-archive.json": `"output-dir": "out", "archive": {"directory": "/tmp/dist"}`,
+	} {
+		writeTestFile(t, filepath.Join(dir, name), `{
+			"name": "acme/repo",
+			"homepage": "https://packages.example.com",
+			"repositories": [{"type": "path", "url": "packages/*"}],
+			`+content+`
+		}`)
+		if _, err := c.BuildSatisNative(filepath.Join(dir, name), ""); !errors.Is(err, ErrSatisConfigInvalid) {
+			t.Errorf("%s: 期望错误 %v，但得到 %v", name, ErrSatisConfigInvalid, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "out")); err == nil {
+		t.Error("配置无效时不应创建输出目录")
+	}
+}
@@ -0,0 +1,304 @@
+package composer
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/scagogogo/go-composer-sdk/pkg/resolver"
+)
+
+func TestSatisConfigRoundTrip(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "satis.json")
+	writeTestFile(t, configPath, `{
+		"name": "acme/repo",
+		"homepage": "https://packages.example.com",
+		"description": "内部仓库",
+		"repositories": [
+			{"type": "vcs", "url": "https://git.example.com/acme/app.git", "options": {"ssh2": {"username": "git"}}, "canonical": false},
+			{"type": "package", "package": {"name": "acme/util", "version": "1.0.0"}, "exclude": ["acme/legacy"]}
+		],
+		"output-dir": "public",
+		"archive": {"directory": "dist", "absolute-directory": "/srv/dist", "checksum": false, "override-dist-type": true},
+		"strip-hosts": false,
+		"abandoned": {"acme/old": "acme/new", "acme/dead": true},
+		"require-dependency-filter": false
+	}`)
+
+	c := &Composer{}
+	if err := c.AddSatisRequire(configPath, "acme/app", "^1.0"); err != nil {
+		t.Fatalf("添加依赖失败: %v", err)
+	}
+	if err := c.AddSatisRepository(configPath, "path", "packages/*"); err != nil {
+		t.Fatalf("添加仓库失败: %v", err)
+	}
+	if err := c.EnableSatisArchive(configPath, "tar"); err != nil {
+		t.Fatalf("启用归档失败: %v", err)
+	}
+
+	content, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatalf("读取配置失败: %v", err)
+	}
+	var raw map[string]interface{}
+	if err := json.Unmarshal(content, &raw); err != nil {
+		t.Fatalf("解析配置失败: %v", err)
+	}
+
+	// 未定义的字段原样保留
+	if raw["description"] != "内部仓库" {
+		t.Errorf("description 错误: %v", raw["description"])
+	}
+	if raw["require-dependency-filter"] != false {
+		t.Errorf("require-dependency-filter 错误: %v", raw["require-dependency-filter"])
+	}
+	repos := raw["repositories"].([]interface{})
+	if len(repos) != 3 {
+		t.Fatalf("期望 3 个仓库，实际 %d 个", len(repos))
+	}
+	first := repos[0].(map[string]interface{})
+	if first["canonical"] != false {
+		t.Errorf("canonical 错误: %v", first["canonical"])
+	}
+	if ssh2 := first["options"].(map[string]interface{})["ssh2"]; !reflect.DeepEqual(ssh2, map[string]interface{}{"username": "git"}) {
+		t.Errorf("options.ssh2 错误: %v", ssh2)
+	}
+	archive := raw["archive"].(map[string]interface{})
+	if archive["override-dist-type"] != true || archive["format"] != "tar" || archive["checksum"] != false {
+		t.Errorf("archive 错误: %v", archive)
+	}
+
+	config, err := LoadSatisConfig(configPath)
+	if err != nil {
+		t.Fatalf("加载配置失败: %v", err)
+	}
+	if !reflect.DeepEqual(config.Require, map[string]string{"acme/app": "^1.0"}) {
+		t.Errorf("require 错误: %v", config.Require)
+	}
+	if config.RequireAll {
+		t.Error("添加依赖后 require-all 应为 false")
+	}
+	if !reflect.DeepEqual(config.Repositories[1].Exclude, []string{"acme/legacy"}) {
+		t.Errorf("exclude 错误: %v", config.Repositories[1].Exclude)
+	}
+	if config.Archive.AbsoluteDirectory != "/srv/dist" {
+		t.Errorf("absolute-directory 错误: %s", config.Archive.AbsoluteDirectory)
+	}
+	if config.Archive.ChecksumEnabled() {
+		t.Error("checksum 应为 false")
+	}
+	if config.Abandoned["acme/old"] != "acme/new" {
+		t.Errorf("abandoned 错误: %v", config.Abandoned)
+	}
+	if config.StripHosts != nil {
+		t.Errorf("strip-hosts 为 false 时应为 nil，实际 %v", config.StripHosts)
+	}
+	if err := config.Validate(); err != nil {
+		t.Errorf("配置验证失败: %v", err)
+	}
+}
+
+func TestSatisConfigValidate(t *testing.T) {
+	config := SatisConfig{
+		Homepage: "packages.example.com",
+		Repositories: []SatisRepository{
+			{Type: "vcs"},
+			{Type: "package"},
+		},
+		Require:          map[string]string{"acme/app": "^^1"},
+		MinimumStability: "unstable",
+		Abandoned:        map[string]interface{}{"acme/old": 1},
+		Archive:          &SatisArchive{Format: "rar", AbsoluteDirectory: "dist"},
+		IncludeFilename:  "../all.json",
+	}
+
+	err := config.Validate()
+	if !errors.Is(err, ErrSatisConfigInvalid) {
+		t.Fatalf("期望错误 %v，但得到 %v", ErrSatisConfigInvalid, err)
+	}
+	for _, message := range []string{"缺少 name", "homepage", "repositories[0]", "repositories[1]", "require.acme/app",
+		"minimum-stability", "abandoned.acme/old", "archive 缺少 directory", "rar", "absolute-directory", "include-filename"} {
+		if !strings.Contains(err.Error(), message) {
+			t.Errorf("错误信息中缺少 %s: %v", message, err)
+		}
+	}
+
+	var hosts SatisHostList
+	if err := json.Unmarshal([]byte(`true`), &hosts); err == nil {
+		t.Error("strip-hosts 为 true 时应该返回错误")
+	}
+}
+
+func TestGenerateStaticRepositoryFilters(t *testing.T) {
+	dir := t.TempDir()
+	distDir := filepath.Join(dir, "files")
+	checksum := false
+
+	config := SatisConfig{
+		Name:     "acme/repo",
+		Homepage: "https://packages.example.com",
+		Repositories: []SatisRepository{
+			{Type: "package", Exclude: []string{"acme/internal-*"}, Package: json.RawMessage(`[
+				{"name": "acme/app", "version": "1.0.0", "source": {"type": "git", "url": "https://git.internal.example.com/app.git", "reference": "abc"}},
+				{"name": "acme/app", "version": "2.0.0", "source": {"type": "git", "url": "https://github.com/acme/app.git", "reference": "def"}},
+				{"name": "acme/internal-tool", "version": "1.0.0"},
+				{"name": "acme/old", "version": "1.0.0", "dist": {"type": "zip", "url": "http://10.0.0.5/old.zip"}}
+			]`)},
+			{Type: "path", URL: filepath.Join(dir, "packages", "*"), Options: map[string]interface{}{
+				"versions": map[string]interface{}{"acme/lib": "3.1.0"},
+			}},
+		},
+		Blacklist:       map[string]string{"acme/app": "<2.0"},
+		Abandoned:       map[string]interface{}{"acme/old": "acme/app"},
+		StripHosts:      SatisHostList{".internal.example.com", "/private"},
+		IncludeFilename: "include/all$%hash%.json",
+		Archive:         &SatisArchive{Directory: "dist", AbsoluteDirectory: distDir, Checksum: &checksum, Blacklist: []string{"acme/app"}},
+	}
+	writeTestFile(t, filepath.Join(dir, "packages", "lib", "composer.json"), `{"name": "acme/lib"}`)
+
+	out := filepath.Join(dir, "public")
+	result, err := GenerateStaticRepository(config, out)
+	if err != nil {
+		t.Fatalf("生成仓库失败: %v", err)
+	}
+	if expected := []string{"acme/app", "acme/lib", "acme/old"}; !reflect.DeepEqual(result.Packages, expected) {
+		t.Errorf("包列表错误: 期望 %v，实际 %v", expected, result.Packages)
+	}
+	if expected := []string{"dist/acme/lib/acme-lib-3.1.0.zip"}; !reflect.DeepEqual(result.Archives, expected) {
+		t.Errorf("归档错误: 期望 %v，实际 %v", expected, result.Archives)
+	}
+	if _, err := os.Stat(filepath.Join(distDir, "acme", "lib", "acme-lib-3.1.0.zip")); err != nil {
+		t.Errorf("归档应写入 absolute-directory: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(out, "dist", "acme", "lib", "acme-lib-3.1.0.zip")); err == nil {
+		t.Error("设置 absolute-directory 时不应在输出目录中写入归档")
+	}
+
+	repo, err := resolver.LoadRepository(out)
+	if err != nil {
+		t.Fatalf("加载仓库失败: %v", err)
+	}
+
+	apps, _ := repo.FindPackages("acme/app")
+	if len(apps) != 1 {
+		t.Fatalf("期望 1 个 acme/app 版本，实际 %d 个", len(apps))
+	}
+	if apps[0].Version != "2.0.0" || apps[0].Source == nil {
+		t.Errorf("acme/app 版本错误: %+v", apps[0])
+	}
+
+	libs, _ := repo.FindPackages("acme/lib")
+	if len(libs) != 1 || libs[0].Dist == nil {
+		t.Fatalf("期望 1 个带 dist 的 acme/lib 版本，实际 %+v", libs)
+	}
+	if libs[0].Dist.Shasum != "" {
+		t.Errorf("关闭 checksum 时 shasum 应为空，实际 %s", libs[0].Dist.Shasum)
+	}
+
+	old, _ := repo.FindPackages("acme/old")
+	if len(old) != 1 {
+		t.Fatalf("期望 1 个 acme/old 版本，实际 %d 个", len(old))
+	}
+	if old[0].Dist != nil {
+		t.Errorf("指向私有地址的 dist 应被移除: %+v", old[0].Dist)
+	}
+
+	content, err := os.ReadFile(filepath.Join(out, "p2", "acme", "old.json"))
+	if err != nil {
+		t.Fatalf("读取包元数据失败: %v", err)
+	}
+	if !strings.Contains(string(content), `"abandoned": "acme/app"`) {
+		t.Errorf("缺少 abandoned 字段: %s", content)
+	}
+
+	var root struct {
+		Includes map[string]struct {
+			SHA1 string `json:"sha1"`
+		} `json:"includes"`
+	}
+	content, err = os.ReadFile(filepath.Join(out, "packages.json"))
+	if err != nil {
+		t.Fatalf("读取 packages.json 失败: %v", err)
+	}
+	if err := json.Unmarshal(content, &root); err != nil {
+		t.Fatalf("解析 packages.json 失败: %v", err)
+	}
+	if len(root.Includes) != 1 {
+		t.Fatalf("期望 1 个 include 文件，实际 %d 个", len(root.Includes))
+	}
+	for name, include := range root.Includes {
+		if expected := "include/all$" + include.SHA1 + ".json"; name != expected {
+			t.Errorf("include 文件名错误: 期望 %s，实际 %s", expected, name)
+		}
+		if _, err := os.Stat(filepath.Join(out, name)); err != nil {
+			t.Errorf("include 文件不存在: %v", err)
+		}
+	}
+}
+
+func TestSatisConfigLosslessRoundTrip(t *testing.T) {
+	original := readTestdata(t, "satis.json")
+	configPath := filepath.Join(t.TempDir(), "satis.json")
+	saved := func() []byte {
+		content, err := os.ReadFile(configPath)
+		if err != nil {
+			t.Fatalf("读取保存的配置失败: %v", err)
+		}
+		return content
+	}
+
+	config, err := LoadSatisConfig(filepath.Join("testdata", "satis.json"))
+	if err != nil {
+		t.Fatalf("加载配置失败: %v", err)
+	}
+	if err := config.Save(configPath); err != nil {
+		t.Fatalf("保存配置失败: %v", err)
+	}
+
+	var expected, actual interface{}
+	if err := json.Unmarshal([]byte(original), &expected); err != nil {
+		t.Fatalf("解析原始配置失败: %v", err)
+	}
+	if err := json.Unmarshal(saved(), &actual); err != nil {
+		t.Fatalf("解析保存的配置失败: %v", err)
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("读取后直接保存应与原始配置相同:\n期望 %v\n实际 %v", expected, actual)
+	}
+
+	// 只写回修改过的字段，缺少的 output-dir 不会被补上
+	config.MinimumStability = "beta"
+	config.Archive.SkipDev = false
+	if err := config.Save(configPath); err != nil {
+		t.Fatalf("保存配置失败: %v", err)
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(saved(), &fields); err != nil {
+		t.Fatalf("解析保存的配置失败: %v", err)
+	}
+	if _, ok := fields["output-dir"]; ok {
+		t.Error("配置中没有 output-dir 时不应写入")
+	}
+	if fields["require-all"] != false || fields["strip-hosts"] != false {
+		t.Errorf("显式的 false 应被保留: require-all=%v strip-hosts=%v", fields["require-all"], fields["strip-hosts"])
+	}
+	if fields["minimum-stability"] != "beta" {
+		t.Errorf("minimum-stability 错误: %v", fields["minimum-stability"])
+	}
+	if archive := fields["archive"].(map[string]interface{}); archive["skip-dev"] != false || archive["rearchive"] != false {
+		t.Errorf("archive 错误: %v", archive)
+	}
+
+	// 新建的配置只写出非零值字段
+	content, err := json.Marshal(SatisConfig{Name: "acme/repo", Homepage: "https://packages.example.com"})
+	if err != nil {
+		t.Fatalf("序列化配置失败: %v", err)
+	}
+	if string(content) != `{"name":"acme/repo","homepage":"https://packages.example.com"}` {
+		t.Errorf("序列化结果错误: %s", content)
+	}
+}
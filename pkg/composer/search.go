@@ -0,0 +1,128 @@
+package composer
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// SearchOptions 定义 composer search 的选项
+type SearchOptions struct {
+	// OnlyName 只在包名中搜索（--only-name）
+	OnlyName bool
+	// OnlyVendor 只搜索厂商名，结果中只包含厂商（--only-vendor）
+	OnlyVendor bool
+	// Type 按包类型过滤，例如"library"、"composer-plugin"（--type）
+	Type string
+	// ExcludeAbandoned 从结果中移除已放弃维护的包
+	ExcludeAbandoned bool
+}
+
+// SearchResult 表示 composer search 的一条结果
+type SearchResult struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	URL         string `json:"url"`
+	// Downloads 下载次数，仅部分仓库提供，未提供时为nil
+	Downloads *int `json:"downloads,omitempty"`
+	// Abandoned 表示包是否已被放弃维护
+	Abandoned bool `json:"-"`
+	// Replacement 已放弃的包推荐使用的替代包，可能为空
+	Replacement string `json:"-"`
+}
+
+// UnmarshalJSON 解析 composer search 的结果
+func (r *SearchResult) UnmarshalJSON(data []byte) error {
+	type plain SearchResult
+	aux := struct {
+		*plain
+		Abandoned interface{} `json:"abandoned"`
+	}{plain: (*plain)(r)}
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	r.Abandoned, r.Replacement = parseAbandoned(aux.Abandoned)
+
+	return nil
+}
+
+// Vendor 返回包名中的厂商部分
+func (r SearchResult) Vendor() string {
+	if i := strings.Index(r.Name, "/"); i >= 0 {
+		return r.Name[:i]
+	}
+	return r.Name
+}
+
+// SearchPackages 搜索包并返回结构化结果
+//
+// 参数：
+//   - query: 搜索关键词，多个关键词以空格分隔
+//   - opts: 搜索选项
+//
+// 返回值：
+//   - []SearchResult: 搜索结果列表
+//   - error: 如果执行命令或解析输出失败，则返回相应的错误信息
+//
+// 功能说明：
+//
+//	该方法执行`composer search --format=json [--only-name] [--only-vendor] [--type=TYPE] query`
+//	并将输出解析为结构体。
+//
+// 用法示例：
+//
+//	results, err := comp.SearchPackages("logger", composer.SearchOptions{
+//	    OnlyName:         true,
+//	    Type:             "library",
+//	    ExcludeAbandoned: true,
+//	})
+//	if err != nil {
+//	    log.Fatalf("搜索包失败: %v", err)
+//	}
+//	for _, r := range results {
+//	    fmt.Printf("%s - %s\n", r.Name, r.Description)
+//	}
+func (c *Composer) SearchPackages(query string, opts SearchOptions) ([]SearchResult, error) {
+	terms := strings.Fields(query)
+	if len(terms) == 0 {
+		return nil, fmt.Errorf("%w: 搜索关键词不能为空", ErrSearchFailed)
+	}
+
+	args := []string{"search", "--format=json"}
+	if opts.OnlyName {
+		args = append(args, "--only-name")
+	}
+	if opts.OnlyVendor {
+		args = append(args, "--only-vendor")
+	}
+	if opts.Type != "" {
+		args = append(args, "--type="+opts.Type)
+	}
+	args = append(args, terms...)
+
+	output, err := c.Run(args...)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []SearchResult
+	if strings.TrimSpace(output) != "" {
+		if err := json.Unmarshal([]byte(output), &results); err != nil {
+			return nil, fmt.Errorf("%w: 解析搜索结果失败: %v", ErrSearchFailed, err)
+		}
+	}
+
+	if opts.ExcludeAbandoned {
+		filtered := results[:0]
+		for _, r := range results {
+			if !r.Abandoned {
+				filtered = append(filtered, r)
+			}
+		}
+		results = filtered
+	}
+
+	return results, nil
+}
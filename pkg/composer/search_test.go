@@ -0,0 +1,82 @@
+package composer
+
+import (
+	"errors"
+	"testing"
+)
+
+const searchTestOutput = `[
+	{"name": "monolog/monolog", "description": "Sends your logs to files, sockets, inboxes, databases and various web services", "url": "https://packagist.org/packages/monolog/monolog", "downloads": 812345678},
+	{"name": "psr/log", "description": "Common interface for logging libraries", "url": "https://packagist.org/packages/psr/log"},
+	{"name": "old/logger", "description": "Legacy logger", "url": "https://packagist.org/packages/old/logger", "abandoned": "monolog/monolog"}
+]`
+
+func TestSearchPackages(t *testing.T) {
+	ClearMockOutputs()
+	defer ClearMockOutputs()
+
+	SetupMockOutput("search --format=json --only-name --type=library log", searchTestOutput, nil)
+
+	composer, err := New(Options{ExecutablePath: "/path/to/composer"})
+	if err != nil {
+		t.Fatalf("创建Composer实例失败: %v", err)
+	}
+
+	results, err := composer.SearchPackages("log", SearchOptions{OnlyName: true, Type: "library"})
+	if err != nil {
+		t.Fatalf("SearchPackages执行失败: %v", err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("应返回3个结果，实际为%d", len(results))
+	}
+
+	if results[0].Downloads == nil || *results[0].Downloads != 812345678 {
+		t.Errorf("monolog的下载量解析不正确: %+v", results[0].Downloads)
+	}
+	if results[1].Downloads != nil {
+		t.Errorf("未提供下载量时应为nil")
+	}
+	if results[0].Vendor() != "monolog" {
+		t.Errorf("厂商名应为monolog，实际为%s", results[0].Vendor())
+	}
+	if !results[2].Abandoned || results[2].Replacement != "monolog/monolog" {
+		t.Errorf("old/logger应被标记为放弃，实际为%+v", results[2])
+	}
+
+	results, err = composer.SearchPackages("log", SearchOptions{OnlyName: true, Type: "library", ExcludeAbandoned: true})
+	if err != nil {
+		t.Fatalf("SearchPackages执行失败: %v", err)
+	}
+	if len(results) != 2 {
+		t.Errorf("排除已放弃的包后应返回2个结果，实际为%d", len(results))
+	}
+}
+
+func TestSearchPackagesVendorAndErrors(t *testing.T) {
+	ClearMockOutputs()
+	defer ClearMockOutputs()
+
+	SetupMockOutput("search --format=json --only-vendor symfony", `[{"name": "symfony", "description": "", "url": "https://packagist.org/packages/symfony/"}]`, nil)
+
+	composer, err := New(Options{ExecutablePath: "/path/to/composer"})
+	if err != nil {
+		t.Fatalf("创建Composer实例失败: %v", err)
+	}
+
+	results, err := composer.SearchPackages("symfony", SearchOptions{OnlyVendor: true})
+	if err != nil {
+		t.Fatalf("SearchPackages执行失败: %v", err)
+	}
+	if len(results) != 1 || results[0].Vendor() != "symfony" {
+		t.Errorf("厂商搜索结果不正确: %+v", results)
+	}
+
+	if _, err := composer.SearchPackages("  ", SearchOptions{}); !errors.Is(err, ErrSearchFailed) {
+		t.Errorf("空关键词应返回ErrSearchFailed，实际为%v", err)
+	}
+
+	SetupMockOutput("search --format=json broken", "not json", nil)
+	if _, err := composer.SearchPackages("broken", SearchOptions{}); !errors.Is(err, ErrSearchFailed) {
+		t.Errorf("无法解析的输出应返回ErrSearchFailed，实际为%v", err)
+	}
+}
@@ -0,0 +1,227 @@
+package composer
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// PackageSource 表示包的源码或分发包信息
+type PackageSource struct {
+	Type      string `json:"type"`
+	URL       string `json:"url"`
+	Reference string `json:"reference,omitempty"`
+}
+
+// UnmarshalJSON 解析包的来源信息
+//
+// composer show 的列表输出中 source 只是一个URL字符串，
+// 而单个包的输出中是包含 type/url/reference 的对象，这里两种格式都支持。
+func (s *PackageSource) UnmarshalJSON(data []byte) error {
+	var url string
+	if err := json.Unmarshal(data, &url); err == nil {
+		s.URL = url
+		return nil
+	}
+
+	type plain PackageSource
+	return json.Unmarshal(data, (*plain)(s))
+}
+
+// PackageLicense 表示包的许可证信息
+type PackageLicense struct {
+	Name string `json:"name"`
+	OSI  string `json:"osi,omitempty"`
+	URL  string `json:"url,omitempty"`
+}
+
+// UnmarshalJSON 解析许可证信息，兼容仅包含许可证标识的字符串
+func (l *PackageLicense) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		l.Name = name
+		return nil
+	}
+
+	type plain PackageLicense
+	return json.Unmarshal(data, (*plain)(l))
+}
+
+// PackageInfo 表示 composer show 输出的包信息
+type PackageInfo struct {
+	Name        string            `json:"name"`
+	Version     string            `json:"version"`
+	Versions    []string          `json:"versions,omitempty"`
+	Description string            `json:"description"`
+	Type        string            `json:"type,omitempty"`
+	Homepage    string            `json:"homepage,omitempty"`
+	Keywords    []string          `json:"keywords,omitempty"`
+	Licenses    []PackageLicense  `json:"licenses,omitempty"`
+	Source      *PackageSource    `json:"source,omitempty"`
+	Dist        *PackageSource    `json:"dist,omitempty"`
+	Path        string            `json:"path,omitempty"`
+	Released    string            `json:"released,omitempty"`
+	Requires    map[string]string `json:"requires,omitempty"`
+	DevRequires map[string]string `json:"devRequires,omitempty"`
+	Direct      bool              `json:"direct-dependency,omitempty"`
+	// Abandoned 表示包是否已被放弃维护
+	Abandoned bool `json:"-"`
+	// Replacement 已放弃的包推荐使用的替代包，可能为空
+	Replacement string `json:"-"`
+}
+
+// UnmarshalJSON 解析 composer show 的包信息
+//
+// 单个包的输出中没有 version 字段，此时取 versions 中的第一个版本。
+func (p *PackageInfo) UnmarshalJSON(data []byte) error {
+	type plain PackageInfo
+	aux := struct {
+		*plain
+		Abandoned interface{} `json:"abandoned"`
+	}{plain: (*plain)(p)}
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	p.Abandoned, p.Replacement = parseAbandoned(aux.Abandoned)
+
+	if p.Version == "" && len(p.Versions) > 0 {
+		p.Version = strings.TrimSpace(strings.TrimPrefix(p.Versions[0], "*"))
+	}
+
+	return nil
+}
+
+// PlatformPackage 表示平台包（PHP、扩展、库等）
+type PlatformPackage struct {
+	Name        string `json:"name"`
+	Version     string `json:"version"`
+	Description string `json:"description"`
+}
+
+// showListResult 表示 composer show --format=json 的列表输出
+type showListResult struct {
+	Installed []PackageInfo     `json:"installed"`
+	Platform  []PlatformPackage `json:"platform"`
+}
+
+// ShowInstalled 获取结构化的已安装包列表
+//
+// 返回值：
+//   - []PackageInfo: 已安装包列表
+//   - error: 如果执行命令或解析输出失败，则返回相应的错误信息
+//
+// 功能说明：
+//
+//	该方法执行`composer show --format=json`并将输出解析为结构体。
+//	列表输出只包含名称、版本、描述、主页、源码地址等基本信息，
+//	需要许可证、依赖等详细信息时请使用 ShowPackageInfo。
+//
+// 用法示例：
+//
+//	packages, err := comp.ShowInstalled()
+//	if err != nil {
+//	    log.Fatalf("获取已安装包失败: %v", err)
+//	}
+//	for _, pkg := range packages {
+//	    fmt.Printf("%s %s\n", pkg.Name, pkg.Version)
+//	}
+func (c *Composer) ShowInstalled() ([]PackageInfo, error) {
+	output, err := c.Run("show", "--format=json")
+	if err != nil {
+		return nil, err
+	}
+
+	var result showListResult
+	if err := json.Unmarshal([]byte(output), &result); err != nil {
+		return nil, fmt.Errorf("解析show输出失败: %w", err)
+	}
+
+	return result.Installed, nil
+}
+
+// ShowPackageInfo 获取单个包的结构化详细信息
+//
+// 参数：
+//   - packageName: 包名
+//
+// 返回值：
+//   - *PackageInfo: 包的详细信息
+//   - error: 如果执行命令或解析输出失败，则返回相应的错误信息
+//
+// 功能说明：
+//
+//	该方法执行`composer show package/name --format=json`并将输出解析为结构体，
+//	包含源码、分发包、许可证、依赖、安装路径和关键字等信息。
+//
+// 用法示例：
+//
+//	info, err := comp.ShowPackageInfo("monolog/monolog")
+//	if err != nil {
+//	    log.Fatalf("获取包信息失败: %v", err)
+//	}
+//	fmt.Printf("%s %s 安装于 %s\n", info.Name, info.Version, info.Path)
+func (c *Composer) ShowPackageInfo(packageName string) (*PackageInfo, error) {
+	if packageName == "" {
+		return nil, fmt.Errorf("%w: 包名不能为空", ErrShowPackageFailed)
+	}
+
+	output, err := c.Run("show", packageName, "--format=json")
+	if err != nil {
+		return nil, err
+	}
+
+	var info PackageInfo
+	if err := json.Unmarshal([]byte(output), &info); err != nil {
+		return nil, fmt.Errorf("解析show输出失败: %w", err)
+	}
+
+	return &info, nil
+}
+
+// ShowPlatform 获取结构化的平台包列表
+//
+// 返回值：
+//   - []PlatformPackage: 平台包列表，包括PHP版本、扩展和系统库
+//   - error: 如果执行命令或解析输出失败，则返回相应的错误信息
+//
+// 功能说明：
+//
+//	该方法执行`composer show --platform --format=json`并将输出解析为结构体。
+//
+// 用法示例：
+//
+//	platform, err := comp.ShowPlatform()
+//	if err != nil {
+//	    log.Fatalf("获取平台包失败: %v", err)
+//	}
+//	for _, p := range platform {
+//	    fmt.Printf("%s %s\n", p.Name, p.Version)
+//	}
+func (c *Composer) ShowPlatform() ([]PlatformPackage, error) {
+	output, err := c.Run("show", "--platform", "--format=json")
+	if err != nil {
+		return nil, err
+	}
+
+	var result showListResult
+	if err := json.Unmarshal([]byte(output), &result); err != nil {
+		return nil, fmt.Errorf("解析show输出失败: %w", err)
+	}
+
+	return result.Platform, nil
+}
+
+// parseAbandoned 解析 Composer 输出中的 abandoned 字段
+//
+// 该字段可能为 false、true 或替代包名。
+func parseAbandoned(v interface{}) (bool, string) {
+	switch v := v.(type) {
+	case bool:
+		return v, ""
+	case string:
+		return true, v
+	}
+	return false, ""
+}
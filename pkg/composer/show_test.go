@@ -0,0 +1,129 @@
+package composer
+
+import (
+	"testing"
+)
+
+func TestShowInstalled(t *testing.T) {
+	ClearMockOutputs()
+	defer ClearMockOutputs()
+
+	SetupMockOutput("show --format=json", `{
+		"installed": [
+			{"name": "monolog/monolog", "direct-dependency": true, "homepage": "https://github.com/Seldaek/monolog", "source": "https://github.com/Seldaek/monolog/tree/3.5.0", "version": "3.5.0", "description": "Sends your logs to files", "abandoned": false},
+			{"name": "swiftmailer/swiftmailer", "direct-dependency": false, "homepage": null, "source": null, "version": "v6.3.0", "description": "Swiftmailer", "abandoned": "symfony/mailer"}
+		]
+	}`, nil)
+
+	composer, err := New(Options{ExecutablePath: "/path/to/composer"})
+	if err != nil {
+		t.Fatalf("创建Composer实例失败: %v", err)
+	}
+
+	packages, err := composer.ShowInstalled()
+	if err != nil {
+		t.Fatalf("ShowInstalled执行失败: %v", err)
+	}
+	if len(packages) != 2 {
+		t.Fatalf("应解析出2个包，实际为%d", len(packages))
+	}
+
+	monolog := packages[0]
+	if monolog.Version != "3.5.0" || !monolog.Direct || monolog.Homepage != "https://github.com/Seldaek/monolog" {
+		t.Errorf("monolog解析不正确: %+v", monolog)
+	}
+	if monolog.Source == nil || monolog.Source.URL != "https://github.com/Seldaek/monolog/tree/3.5.0" {
+		t.Errorf("字符串形式的source应解析为URL，实际为%+v", monolog.Source)
+	}
+
+	swift := packages[1]
+	if swift.Source != nil {
+		t.Errorf("source为null时应为nil，实际为%+v", swift.Source)
+	}
+	if !swift.Abandoned || swift.Replacement != "symfony/mailer" {
+		t.Errorf("swiftmailer应被标记为放弃，实际为%+v", swift)
+	}
+}
+
+func TestShowPackageInfo(t *testing.T) {
+	ClearMockOutputs()
+	defer ClearMockOutputs()
+
+	SetupMockOutput("show monolog/monolog --format=json", `{
+		"name": "monolog/monolog",
+		"description": "Sends your logs to files, sockets, inboxes, databases and various web services",
+		"keywords": ["log", "logging", "psr-3"],
+		"type": "library",
+		"homepage": "https://github.com/Seldaek/monolog",
+		"names": ["monolog/monolog", "psr/log-implementation"],
+		"versions": ["* 3.5.0"],
+		"licenses": [{"name": "MIT License", "osi": "MIT", "url": "https://spdx.org/licenses/MIT.html#licenseText"}],
+		"source": {"type": "git", "url": "https://github.com/Seldaek/monolog.git", "reference": "c915e2634718dbc8a4a15c61b0e62e7a44e14448"},
+		"dist": {"type": "zip", "url": "https://api.github.com/repos/Seldaek/monolog/zipball/c915e26", "reference": "c915e2634718dbc8a4a15c61b0e62e7a44e14448"},
+		"path": "/app/vendor/monolog/monolog",
+		"released": "2023-10-27, 2 months ago",
+		"requires": {"php": ">=8.1", "psr/log": "^2.0 || ^3.0"},
+		"devRequires": {"phpunit/phpunit": "^10.1"}
+	}`, nil)
+
+	composer, err := New(Options{ExecutablePath: "/path/to/composer"})
+	if err != nil {
+		t.Fatalf("创建Composer实例失败: %v", err)
+	}
+
+	info, err := composer.ShowPackageInfo("monolog/monolog")
+	if err != nil {
+		t.Fatalf("ShowPackageInfo执行失败: %v", err)
+	}
+
+	if info.Version != "3.5.0" {
+		t.Errorf("版本应从versions中获取为3.5.0，实际为%q", info.Version)
+	}
+	if info.Path != "/app/vendor/monolog/monolog" {
+		t.Errorf("路径解析不正确: %q", info.Path)
+	}
+	if len(info.Licenses) != 1 || info.Licenses[0].OSI != "MIT" {
+		t.Errorf("许可证解析不正确: %+v", info.Licenses)
+	}
+	if info.Source == nil || info.Source.Type != "git" || info.Source.Reference == "" {
+		t.Errorf("source解析不正确: %+v", info.Source)
+	}
+	if info.Dist == nil || info.Dist.Type != "zip" {
+		t.Errorf("dist解析不正确: %+v", info.Dist)
+	}
+	if info.Requires["psr/log"] != "^2.0 || ^3.0" || info.DevRequires["phpunit/phpunit"] != "^10.1" {
+		t.Errorf("依赖解析不正确: %+v %+v", info.Requires, info.DevRequires)
+	}
+	if len(info.Keywords) != 3 {
+		t.Errorf("关键字解析不正确: %+v", info.Keywords)
+	}
+
+	if _, err := composer.ShowPackageInfo(""); err == nil {
+		t.Error("包名为空时应返回错误")
+	}
+}
+
+func TestShowPlatform(t *testing.T) {
+	ClearMockOutputs()
+	defer ClearMockOutputs()
+
+	SetupMockOutput("show --platform --format=json", `{
+		"platform": [
+			{"name": "ext-json", "version": "8.2.10", "description": "The json PHP extension"},
+			{"name": "php", "version": "8.2.10", "description": "The PHP interpreter"}
+		]
+	}`, nil)
+
+	composer, err := New(Options{ExecutablePath: "/path/to/composer"})
+	if err != nil {
+		t.Fatalf("创建Composer实例失败: %v", err)
+	}
+
+	platform, err := composer.ShowPlatform()
+	if err != nil {
+		t.Fatalf("ShowPlatform执行失败: %v", err)
+	}
+	if len(platform) != 2 || platform[1].Name != "php" || platform[1].Version != "8.2.10" {
+		t.Errorf("平台包解析不正确: %+v", platform)
+	}
+}
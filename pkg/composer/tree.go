@@ -0,0 +1,246 @@
+package composer
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// TreeNode 表示依赖树中的一个节点
+type TreeNode struct {
+	// Name 包名
+	Name string `json:"name"`
+	// Version 已安装的版本，对于未出现在顶层的子节点可能为空
+	Version string `json:"version,omitempty"`
+	// Constraint 父节点对该包的版本约束，顶层节点为空
+	Constraint string `json:"constraint,omitempty"`
+	// Description 包描述，仅顶层节点包含
+	Description string `json:"description,omitempty"`
+	// Children 该包的依赖
+	Children []*TreeNode `json:"children,omitempty"`
+}
+
+// DependencyTree 表示由多个顶层包组成的依赖树
+type DependencyTree []*TreeNode
+
+// treeNodeJSON 表示 composer show --tree --format=json 输出中的节点
+//
+// 顶层节点的 version 是已安装的版本，子节点的 version 则是版本约束。
+type treeNodeJSON struct {
+	Name        string          `json:"name"`
+	Version     string          `json:"version"`
+	Description string          `json:"description"`
+	Requires    []*treeNodeJSON `json:"requires"`
+}
+
+// ShowTree 获取整个项目的结构化依赖树
+//
+// 返回值：
+//   - DependencyTree: 以直接依赖为根的依赖树
+//   - error: 如果执行命令或解析输出失败，则返回相应的错误信息
+//
+// 功能说明：
+//
+//	该方法执行`composer show --tree --format=json`并将输出解析为树结构。
+//	子节点的已安装版本会根据顶层节点的版本补全。
+//
+// 用法示例：
+//
+//	tree, err := comp.ShowTree()
+//	if err != nil {
+//	    log.Fatalf("获取依赖树失败: %v", err)
+//	}
+//	for _, path := range tree.FindPaths("psr/log") {
+//	    fmt.Println(strings.Join(path, " -> "))
+//	}
+func (c *Composer) ShowTree() (DependencyTree, error) {
+	output, err := c.Run("show", "--tree", "--format=json")
+	if err != nil {
+		return nil, err
+	}
+	return parseDependencyTree(output)
+}
+
+// ShowPackageTree 获取单个包的结构化依赖树
+//
+// 参数：
+//   - packageName: 包名
+//
+// 返回值：
+//   - *TreeNode: 以该包为根的依赖树
+//   - error: 如果执行命令或解析输出失败，则返回相应的错误信息
+//
+// 功能说明：
+//
+//	该方法执行`composer show --tree package/name --format=json`并将输出解析为树结构。
+//
+// 用法示例：
+//
+//	node, err := comp.ShowPackageTree("monolog/monolog")
+//	if err != nil {
+//	    log.Fatalf("获取依赖树失败: %v", err)
+//	}
+//	fmt.Printf("依赖树最大深度: %d\n", node.MaxDepth())
+func (c *Composer) ShowPackageTree(packageName string) (*TreeNode, error) {
+	if packageName == "" {
+		return nil, fmt.Errorf("%w: 包名不能为空", ErrShowPackageFailed)
+	}
+
+	output, err := c.Run("show", "--tree", packageName, "--format=json")
+	if err != nil {
+		return nil, err
+	}
+
+	tree, err := parseDependencyTree(output)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, node := range tree {
+		if strings.EqualFold(node.Name, packageName) {
+			return node, nil
+		}
+	}
+	if len(tree) > 0 {
+		return tree[0], nil
+	}
+
+	return nil, fmt.Errorf("%w: 未找到包 %s 的依赖树", ErrShowPackageFailed, packageName)
+}
+
+// parseDependencyTree 解析 composer show --tree --format=json 的输出
+func parseDependencyTree(output string) (DependencyTree, error) {
+	var result struct {
+		Installed []*treeNodeJSON `json:"installed"`
+	}
+	if err := json.Unmarshal([]byte(output), &result); err != nil {
+		return nil, fmt.Errorf("解析依赖树输出失败: %w", err)
+	}
+
+	versions := make(map[string]string, len(result.Installed))
+	for _, n := range result.Installed {
+		versions[n.Name] = n.Version
+	}
+
+	tree := make(DependencyTree, 0, len(result.Installed))
+	for _, n := range result.Installed {
+		root := &TreeNode{
+			Name:        n.Name,
+			Version:     n.Version,
+			Description: n.Description,
+			Children:    convertTreeChildren(n.Requires, versions),
+		}
+		tree = append(tree, root)
+	}
+
+	return tree, nil
+}
+
+// convertTreeChildren 递归转换子节点
+func convertTreeChildren(requires []*treeNodeJSON, versions map[string]string) []*TreeNode {
+	if len(requires) == 0 {
+		return nil
+	}
+
+	children := make([]*TreeNode, 0, len(requires))
+	for _, r := range requires {
+		children = append(children, &TreeNode{
+			Name:       r.Name,
+			Version:    versions[r.Name],
+			Constraint: r.Version,
+			Children:   convertTreeChildren(r.Requires, versions),
+		})
+	}
+	return children
+}
+
+// Walk 深度优先遍历以该节点为根的子树
+//
+// 回调函数的 ancestors 参数为从根到当前节点父节点的路径，
+// 回调返回false时不再遍历当前节点的子节点。
+func (n *TreeNode) Walk(fn func(node *TreeNode, ancestors []*TreeNode) bool) {
+	n.walk(nil, fn)
+}
+
+func (n *TreeNode) walk(ancestors []*TreeNode, fn func(node *TreeNode, ancestors []*TreeNode) bool) {
+	if !fn(n, ancestors) {
+		return
+	}
+
+	path := make([]*TreeNode, len(ancestors)+1)
+	copy(path, ancestors)
+	path[len(ancestors)] = n
+
+	for _, child := range n.Children {
+		child.walk(path, fn)
+	}
+}
+
+// MaxDepth 返回子树的最大深度，只有根节点时深度为1
+func (n *TreeNode) MaxDepth() int {
+	depth := 0
+	n.Walk(func(_ *TreeNode, ancestors []*TreeNode) bool {
+		if len(ancestors)+1 > depth {
+			depth = len(ancestors) + 1
+		}
+		return true
+	})
+	return depth
+}
+
+// FindPaths 返回从根到所有名为 packageName 的节点的路径
+//
+// 每条路径都是从根节点开始、以目标包结束的包名列表。
+func (n *TreeNode) FindPaths(packageName string) [][]string {
+	var paths [][]string
+	n.Walk(func(node *TreeNode, ancestors []*TreeNode) bool {
+		if strings.EqualFold(node.Name, packageName) {
+			path := make([]string, 0, len(ancestors)+1)
+			for _, a := range ancestors {
+				path = append(path, a.Name)
+			}
+			paths = append(paths, append(path, node.Name))
+		}
+		return true
+	})
+	return paths
+}
+
+// Walk 依次深度优先遍历每个顶层包的子树
+func (t DependencyTree) Walk(fn func(node *TreeNode, ancestors []*TreeNode) bool) {
+	for _, root := range t {
+		root.Walk(fn)
+	}
+}
+
+// MaxDepth 返回整个依赖树的最大深度
+func (t DependencyTree) MaxDepth() int {
+	depth := 0
+	for _, root := range t {
+		if d := root.MaxDepth(); d > depth {
+			depth = d
+		}
+	}
+	return depth
+}
+
+// FindPaths 返回从各顶层包到所有名为 packageName 的节点的路径
+func (t DependencyTree) FindPaths(packageName string) [][]string {
+	var paths [][]string
+	for _, root := range t {
+		paths = append(paths, root.FindPaths(packageName)...)
+	}
+	return paths
+}
+
+// Find 返回依赖树中第一个名为 packageName 的节点，未找到时返回nil
+func (t DependencyTree) Find(packageName string) *TreeNode {
+	var found *TreeNode
+	t.Walk(func(node *TreeNode, _ []*TreeNode) bool {
+		if found == nil && strings.EqualFold(node.Name, packageName) {
+			found = node
+		}
+		return found == nil
+	})
+	return found
+}
@@ -0,0 +1,120 @@
+package composer
+
+import (
+	"reflect"
+	"testing"
+)
+
+const treeTestOutput = `{
+	"installed": [
+		{
+			"name": "monolog/monolog",
+			"version": "3.5.0",
+			"description": "Sends your logs to files",
+			"requires": [
+				{"name": "php", "version": ">=8.1"},
+				{"name": "psr/log", "version": "^2.0 || ^3.0", "requires": [{"name": "php", "version": ">=8.0.0"}]}
+			]
+		},
+		{
+			"name": "symfony/console",
+			"version": "v6.4.2",
+			"description": "Eases the creation of beautiful and testable command line interfaces",
+			"requires": [
+				{"name": "symfony/service-contracts", "version": "^2.5|^3", "requires": [
+					{"name": "psr/container", "version": "^1.1|^2.0", "requires": [{"name": "php", "version": ">=7.4.0"}]}
+				]}
+			]
+		},
+		{"name": "psr/log", "version": "3.0.0", "description": "Common interface for logging libraries", "requires": []}
+	]
+}`
+
+func TestShowTree(t *testing.T) {
+	ClearMockOutputs()
+	defer ClearMockOutputs()
+
+	SetupMockOutput("show --tree --format=json", treeTestOutput, nil)
+
+	composer, err := New(Options{ExecutablePath: "/path/to/composer"})
+	if err != nil {
+		t.Fatalf("创建Composer实例失败: %v", err)
+	}
+
+	tree, err := composer.ShowTree()
+	if err != nil {
+		t.Fatalf("ShowTree执行失败: %v", err)
+	}
+	if len(tree) != 3 {
+		t.Fatalf("应有3个顶层包，实际为%d", len(tree))
+	}
+
+	monolog := tree[0]
+	if monolog.Version != "3.5.0" || monolog.Constraint != "" {
+		t.Errorf("顶层节点应只有版本没有约束: %+v", monolog)
+	}
+
+	psrLog := monolog.Children[1]
+	if psrLog.Constraint != "^2.0 || ^3.0" || psrLog.Version != "3.0.0" {
+		t.Errorf("子节点应包含约束并从顶层补全版本: %+v", psrLog)
+	}
+
+	if depth := tree.MaxDepth(); depth != 4 {
+		t.Errorf("最大深度应为4，实际为%d", depth)
+	}
+
+	paths := tree.FindPaths("php")
+	expected := [][]string{
+		{"monolog/monolog", "php"},
+		{"monolog/monolog", "psr/log", "php"},
+		{"symfony/console", "symfony/service-contracts", "psr/container", "php"},
+	}
+	if !reflect.DeepEqual(paths, expected) {
+		t.Errorf("php的路径不正确:\n期望 %v\n实际 %v", expected, paths)
+	}
+
+	if node := tree.Find("psr/container"); node == nil || node.Constraint != "^1.1|^2.0" {
+		t.Errorf("Find应找到psr/container，实际为%+v", node)
+	}
+	if node := tree.Find("vendor/missing"); node != nil {
+		t.Errorf("不存在的包应返回nil，实际为%+v", node)
+	}
+
+	visited := 0
+	tree.Walk(func(node *TreeNode, ancestors []*TreeNode) bool {
+		visited++
+		// 不进入symfony/console的子树
+		return node.Name != "symfony/console"
+	})
+	if visited != 6 {
+		t.Errorf("跳过子树后应访问6个节点，实际为%d", visited)
+	}
+}
+
+func TestShowPackageTree(t *testing.T) {
+	ClearMockOutputs()
+	defer ClearMockOutputs()
+
+	SetupMockOutput("show --tree psr/log --format=json", `{
+		"installed": [
+			{"name": "psr/log", "version": "3.0.0", "description": "Common interface for logging libraries", "requires": [{"name": "php", "version": ">=8.0.0"}]}
+		]
+	}`, nil)
+
+	composer, err := New(Options{ExecutablePath: "/path/to/composer"})
+	if err != nil {
+		t.Fatalf("创建Composer实例失败: %v", err)
+	}
+
+	node, err := composer.ShowPackageTree("psr/log")
+	if err != nil {
+		t.Fatalf("ShowPackageTree执行失败: %v", err)
+	}
+	if node.Name != "psr/log" || len(node.Children) != 1 || node.MaxDepth() != 2 {
+		t.Errorf("单个包的依赖树解析不正确: %+v", node)
+	}
+
+	if _, err := composer.ShowPackageTree(""); err == nil {
+		t.Error("包名为空时应返回错误")
+	}
+}
@@ -0,0 +1,73 @@
+package composer
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/scagogogo/go-composer-sdk/pkg/resolver"
+)
+
+// PlanUpdate 在不运行 Composer 的情况下预览 composer update 将要执行的操作
+//
+// 参数：
+//   - repo: 包元数据来源，例如 resolver.LoadRepository 加载的本地仓库，
+//     或 resolver.NewClientRepository(client) 包装的 Packagist 客户端
+//   - options: 解析选项，Options.Packages 对应 composer update 的包参数
+//
+// 返回值：
+//   - *resolver.Plan: 解析得到的包和相对于 composer.lock 的操作
+//   - error: 依赖无法解析时返回 *resolver.UnresolvableError，其中说明了每个无法满足的约束
+//
+// 功能说明：
+//
+//	该方法读取工作目录下的 composer.json 和 composer.lock（不存在时视为首次安装），
+//	composer.json 中 config.platform 配置的平台版本会覆盖 options.Platform 中的值。
+//	解析在纯 Go 中完成，不需要安装 PHP，适合在执行真正的 Update 之前评估升级方案。
+//
+// 用法示例：
+//
+//	repo := resolver.NewClientRepository(client)
+//	plan, err := comp.PlanUpdate(repo, resolver.Options{
+//	    Platform: map[string]string{"php": "8.2.10"},
+//	    Packages: []string{"monolog/monolog"},
+//	})
+//	if err != nil {
+//	    log.Fatalf("无法更新: %v", err)
+//	}
+//	for _, op := range plan.Operations {
+//	    fmt.Println(op)
+//	}
+func (c *Composer) PlanUpdate(repo resolver.Repository, options resolver.Options) (*resolver.Plan, error) {
+	composerJSON, err := c.ReadComposerJSON()
+	if err != nil {
+		return nil, err
+	}
+
+	root := &resolver.Root{
+		Name:             composerJSON.Name,
+		Require:          composerJSON.Require,
+		RequireDev:       composerJSON.RequireDev,
+		Replace:          composerJSON.Replace,
+		Provide:          composerJSON.Provide,
+		Conflict:         composerJSON.Conflict,
+		MinimumStability: composerJSON.MinimumStability,
+		PreferStable:     composerJSON.PreferStable,
+	}
+	if platform, ok := composerJSON.Config["platform"].(map[string]interface{}); ok {
+		root.Platform = resolver.PlatformOverrides(platform)
+	}
+
+	workDir := c.workingDir
+	if workDir == "" {
+		if workDir, err = os.Getwd(); err != nil {
+			return nil, err
+		}
+	}
+
+	lock, err := resolver.LoadLockFile(filepath.Join(workDir, "composer.lock"))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	return resolver.NewResolver(repo).Plan(root, lock, options)
+}
@@ -0,0 +1,45 @@
+package composer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/scagogogo/go-composer-sdk/pkg/packagist"
+	"github.com/scagogogo/go-composer-sdk/pkg/resolver"
+)
+
+func TestPlanUpdate(t *testing.T) {
+	dir := t.TempDir()
+	_ = os.WriteFile(filepath.Join(dir, "composer.json"), []byte(`{
+		"name": "acme/app",
+		"require": {"php": "^8.1", "psr/log": "^3.0"},
+		"config": {"platform": {"php": "8.2.0"}}
+	}`), 0644)
+	_ = os.WriteFile(filepath.Join(dir, "composer.lock"), []byte(`{
+		"packages": [{"name": "psr/log", "version": "2.0.0"}],
+		"packages-dev": [],
+		"stability-flags": [],
+		"platform": [],
+		"platform-dev": []
+	}`), 0644)
+
+	composer, err := New(Options{ExecutablePath: "/path/to/composer", WorkingDir: dir})
+	if err != nil {
+		t.Fatalf("创建Composer实例失败: %v", err)
+	}
+
+	repo := resolver.NewStaticRepository(
+		packagist.Version{Name: "psr/log", Version: "2.0.0"},
+		packagist.Version{Name: "psr/log", Version: "3.0.1"},
+	)
+
+	// options 中的 php 版本会被 config.platform 覆盖
+	plan, err := composer.PlanUpdate(repo, resolver.Options{Platform: map[string]string{"php": "7.4.0"}})
+	if err != nil {
+		t.Fatalf("PlanUpdate执行失败: %v", err)
+	}
+	if len(plan.Operations) != 1 || plan.Operations[0].Type != resolver.OperationUpgrade || plan.Operations[0].To != "3.0.1" {
+		t.Errorf("操作计划不正确: %+v", plan.Operations)
+	}
+}
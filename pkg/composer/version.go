@@ -2,7 +2,9 @@ package composer
 
 import (
 	"fmt"
-	"strings"
+
+	"github.com/scagogogo/go-composer-sdk/pkg/detector"
+	"github.com/scagogogo/go-composer-sdk/pkg/resolver"
 )
 
 // GetVersion 获取composer版本
@@ -14,7 +16,11 @@ import (
 // 功能说明：
 //
 //	该方法执行`composer --version`命令，并解析输出以获取Composer的版本号。
-//	它会从类似"Composer version 2.1.6 2021-08-19 17:11:08"的输出中提取版本号。
+//	它会从类似"Composer version 2.1.6 2021-08-19 17:11:08"的输出中提取版本号，
+//	解析规则与 detector.ParseComposerVersion 相同。
+//
+//	兼容性说明：早期版本直接返回输出中以空格分隔的第三个字段，输出前有 PHP 警告或
+//	格式不同时会返回错误的内容；现在只返回匹配到的版本号，输出中没有版本号时返回错误。
 //
 // 用法示例：
 //
@@ -29,16 +35,37 @@ func (c *Composer) GetVersion() (string, error) {
 		return "", err
 	}
 
-	// 解析版本信息
 	// 示例输出: "Composer version 2.1.6 2021-08-19 17:11:08"
-	parts := strings.Split(output, " ")
-	if len(parts) >= 3 {
-		return parts[2], nil
+	if version, ok := detector.ParseComposerVersion(output); ok {
+		return version, nil
 	}
-
 	return "", fmt.Errorf("无法解析版本信息: %s", output)
 }
 
+// GetParsedVersion 获取解析后的composer版本
+//
+// 返回值：
+//   - *resolver.Version: 解析后的版本，可以与版本约束比较
+//   - error: 如果获取或解析版本信息失败，则返回相应的错误信息
+//
+// 用法示例：
+//
+//	version, err := comp.GetParsedVersion()
+//	if err == nil && resolver.MustParseConstraint("^2.5").Matches(version) {
+//	    output, _ := comp.Run("bump")
+//	}
+func (c *Composer) GetParsedVersion() (*resolver.Version, error) {
+	version, err := c.GetVersion()
+	if err != nil {
+		return nil, err
+	}
+	v, err := resolver.ParseVersion(version)
+	if err != nil {
+		return nil, fmt.Errorf("无法解析版本信息: %w", err)
+	}
+	return v, nil
+}
+
 // SelfUpdate 执行composer self-update命令
 //
 // 返回值：
@@ -6,15 +6,41 @@ package detector
 import (
 	"os"
 	"path/filepath"
+
+	"github.com/scagogogo/go-composer-sdk/pkg/installer"
 )
 
 // getPlatformSpecificPaths 返回 macOS (Darwin) 平台上可能的 Composer 路径
 func getPlatformSpecificPaths() []string {
-	return []string{
+	paths := []string{
 		"/usr/local/bin/composer",
 		"/usr/bin/composer",
 		"/opt/homebrew/bin/composer",
 		filepath.Join(os.Getenv("HOME"), ".composer/vendor/bin/composer"),
 		filepath.Join(os.Getenv("HOME"), "composer.phar"),
 	}
+	// 无法确定用户主目录时跳过用户范围的安装路径，避免得到相对路径
+	if dir := installer.UserBinDir(); dir != "" {
+		paths = append(paths, filepath.Join(dir, "composer"))
+	}
+	return paths
+}
+
+// getPHPSearchPatterns 返回 macOS (Darwin) 平台上 PHP 可执行文件的查找模式
+func getPHPSearchPatterns() []string {
+	home := os.Getenv("HOME")
+	return []string{
+		"/opt/homebrew/bin/php*",
+		"/opt/homebrew/opt/php*/bin/php",
+		"/usr/local/bin/php*",
+		"/usr/local/opt/php*/bin/php",
+		"/usr/bin/php",
+		filepath.Join(home, ".phpenv", "versions", "*", "bin", "php"),
+		filepath.Join(home, ".phpbrew", "php", "*", "bin", "php"),
+	}
+}
+
+// getExecutableNames 返回 macOS上在 PATH 中查找的 Composer 文件名
+func getExecutableNames() []string {
+	return []string{"composer", "composer.phar"}
 }
@@ -3,9 +3,8 @@ package detector
 import (
 	"errors"
 	"os"
-	"os/exec"
+	"path/filepath"
 	"runtime"
-	"strings"
 )
 
 // 常见错误
@@ -17,6 +16,8 @@ var (
 type Detector struct {
 	// 可能的composer可执行文件路径
 	possiblePaths []string
+	// 项目目录，用于查找项目内的composer.phar和vendor/bin/composer，为空时不查找
+	projectDir string
 }
 
 // NewDetector 创建一个新的Composer检测器
@@ -36,6 +37,11 @@ func (d *Detector) AddPossiblePath(path string) {
 	d.possiblePaths = append(d.possiblePaths, path)
 }
 
+// SetProjectDir 设置DetectAll查找项目内Composer使用的项目目录，为空时不查找项目内的Composer
+func (d *Detector) SetProjectDir(dir string) {
+	d.projectDir = dir
+}
+
 // Detect 尝试在系统中检测Composer可执行文件
 // 返回Composer可执行文件的完整路径，如果未找到则返回错误
 func (d *Detector) Detect() (string, error) {
@@ -53,18 +59,8 @@ func (d *Detector) Detect() (string, error) {
 		}
 	}
 
-	// 最后尝试使用which/where命令查找
-	executable := "composer"
-	var cmd *exec.Cmd
-	if runtime.GOOS == "windows" {
-		cmd = exec.Command("where", executable)
-	} else {
-		cmd = exec.Command("which", executable)
-	}
-
-	output, err := cmd.Output()
-	if err == nil {
-		path := strings.TrimSpace(string(output))
+	// 最后在PATH中查找
+	for _, path := range findInPath() {
 		if isExecutable(path) {
 			return path, nil
 		}
@@ -110,3 +106,20 @@ func defaultPossiblePaths() []string {
 
 	return paths
 }
+
+// findInPath 按PATH中的目录顺序返回所有存在的Composer文件
+func findInPath() []string {
+	var paths []string
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		if dir == "" {
+			continue
+		}
+		for _, name := range getExecutableNames() {
+			path := filepath.Join(dir, name)
+			if info, err := os.Stat(path); err == nil && info.Mode().IsRegular() {
+				paths = append(paths, path)
+			}
+		}
+	}
+	return paths
+}
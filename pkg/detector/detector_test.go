@@ -142,3 +142,26 @@ func TestDetectWithEnvironmentVariable(t *testing.T) {
 		t.Errorf("Detect应返回环境变量中的路径 %s，实际返回 %s", fakePath, detectedPath)
 	}
 }
+
+func TestParseComposerVersion(t *testing.T) {
+	tests := []struct {
+		output  string
+		version string
+		ok      bool
+	}{
+		{"Composer version 2.7.7 2024-06-10 22:11:12", "2.7.7", true},
+		{"Composer version 2.4.4", "2.4.4", true},
+		{"Composer 1.10.1 2020-03-13 20:34:27", "1.10.1", true},
+		{"Composer version v2.2.24", "2.2.24", true},
+		{"PHP Deprecated:  something in foo.php\nComposer version 2.5.8 2023-06-09 17:13:21\n", "2.5.8", true},
+		{"Composer version 2.8.0-RC1 2024-09-20 10:00:00", "2.8.0-RC1", true},
+		{"Invalid version output", "", false},
+		{"", "", false},
+	}
+	for _, tt := range tests {
+		version, ok := ParseComposerVersion(tt.output)
+		if version != tt.version || ok != tt.ok {
+			t.Errorf("ParseComposerVersion(%q) = %q, %v，期望 %q, %v", tt.output, version, ok, tt.version, tt.ok)
+		}
+	}
+}
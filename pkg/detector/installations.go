@@ -0,0 +1,441 @@
+package detector
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/scagogogo/go-composer-sdk/pkg/resolver"
+)
+
+// Installation 的来源
+const (
+	// SourceEnv 来自 COMPOSER_PATH 环境变量
+	SourceEnv = "env"
+	// SourcePossiblePath 来自检测器的可能路径列表
+	SourcePossiblePath = "possible-path"
+	// SourcePath 来自 PATH 中的目录
+	SourcePath = "path"
+	// SourceProject 来自项目目录中的 composer.phar 或 vendor/bin/composer
+	SourceProject = "project"
+)
+
+// Installation 的类型
+const (
+	// KindPhar composer.phar 归档，官方发布的 composer 文件本身也是 phar
+	KindPhar = "phar"
+	// KindWrapper 调用 composer.phar 的 shell 或批处理包装脚本
+	KindWrapper = "wrapper"
+	// KindScript 其他 PHP 脚本，例如 vendor/bin/composer 代理脚本
+	KindScript = "script"
+)
+
+// composerVersionTimeout 是运行 composer --version 的超时时间
+const composerVersionTimeout = 10 * time.Second
+
+// wrapperPharPattern 匹配包装脚本中被引号包围的 .phar 路径，
+// 例如 exec php "/usr/local/bin/composer.phar" "$@" 或 @php "%~dp0composer.phar" %*
+var wrapperPharPattern = regexp.MustCompile(`"([^"]+\.phar)"`)
+
+// composerVersionPattern 匹配 composer --version 输出中的版本号，
+// 例如 Composer version 2.7.7 2024-06-10 22:11:12
+var composerVersionPattern = regexp.MustCompile(`Composer (?:version )?v?(\d\S*)`)
+
+// Installation 描述系统中找到的一个 Composer 安装
+type Installation struct {
+	// Path 找到的路径
+	Path string
+	// ResolvedPath 解析符号链接后的绝对路径
+	ResolvedPath string
+	// Source 找到该安装的位置，取值为 Source* 常量
+	Source string
+	// Kind 安装的类型，取值为 Kind* 常量
+	Kind string
+	// PharPath 实际运行的 composer.phar 或 PHP 脚本，包装脚本时为其引用的 .phar
+	PharPath string
+	// Version Composer 版本号，无法运行时为空
+	Version string
+	// PHPBinary 运行该安装使用的 PHP 可执行文件，无法确定时为空
+	PHPBinary string
+	// ProjectLocal 是否位于项目目录中
+	ProjectLocal bool
+}
+
+// SelectionPolicy 描述从多个 Composer 安装中选择一个的策略
+type SelectionPolicy struct {
+	// MinVersion 最低 Composer 版本，例如 2.2.0，版本未知的安装视为不满足
+	MinVersion string
+	// PreferProjectLocal 存在满足条件的项目内安装时优先使用
+	PreferProjectLocal bool
+	// PreferNewest 选择版本最高的安装，否则按检测顺序选择第一个
+	PreferNewest bool
+}
+
+// candidate 是尚未检查的 Composer 路径及其来源
+type candidate struct {
+	path   string
+	source string
+}
+
+// DetectAll 检测系统中所有的 Composer 安装
+//
+// 返回值：
+//   - []Installation: 找到的安装，按检测顺序排列，指向同一文件的路径只保留第一个
+//   - error: 如果没有找到任何安装，则返回 ErrExecutableNotFound
+//
+// 功能说明：
+//
+//	依次检查 COMPOSER_PATH 环境变量、可能的路径列表、PATH 中的目录，以及项目目录中的
+//	composer.phar 和 vendor/bin/composer。对每个安装判断其类型和使用的 PHP，
+//	并运行 composer --version 读取版本号；无法运行的安装仍会返回，Version 为空。
+//	只有通过 SetProjectDir 设置了项目目录时才查找项目内的安装；项目内的文件来自检出的代码，
+//	运行它们之前应确认代码可信。
+//
+// 用法示例：
+//
+//	installations, err := detector.NewDetector().DetectAll()
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	for _, inst := range installations {
+//	    fmt.Printf("%s (%s, %s): Composer %s, PHP %s\n", inst.Path, inst.Source, inst.Kind, inst.Version, inst.PHPBinary)
+//	}
+func (d *Detector) DetectAll() ([]Installation, error) {
+	projectDir := d.projectDir
+	if projectDir != "" {
+		if abs, err := filepath.Abs(projectDir); err == nil {
+			projectDir = abs
+		}
+	}
+
+	var installations []Installation
+	seen := map[string]bool{}
+	for _, c := range d.candidates(projectDir) {
+		if !isRunnable(c.path) {
+			continue
+		}
+		resolved, err := filepath.Abs(c.path)
+		if err != nil {
+			continue
+		}
+		if real, err := filepath.EvalSymlinks(resolved); err == nil {
+			resolved = real
+		}
+		if seen[resolved] {
+			continue
+		}
+		seen[resolved] = true
+
+		inst := inspectInstallation(c.path)
+		inst.ResolvedPath = resolved
+		inst.Source = c.source
+		inst.ProjectLocal = c.source == SourceProject || (projectDir != "" && isWithin(projectDir, c.path))
+		installations = append(installations, inst)
+	}
+
+	if len(installations) == 0 {
+		return nil, ErrExecutableNotFound
+	}
+	return installations, nil
+}
+
+// Select 检测所有 Composer 安装并按策略选择一个
+//
+// 参数：
+//   - policy: 选择策略
+//
+// 返回值：
+//   - *Installation: 选中的安装
+//   - error: 如果没有找到满足条件的安装，则返回包装了 ErrExecutableNotFound 的错误
+//
+// 用法示例：
+//
+//	d := detector.NewDetector()
+//	d.SetProjectDir("/path/to/project")
+//	inst, err := d.Select(detector.SelectionPolicy{MinVersion: "2.2.0", PreferProjectLocal: true, PreferNewest: true})
+//	if err == nil {
+//	    comp, _ := composer.New(composer.Options{ExecutablePath: inst.Path})
+//	}
+func (d *Detector) Select(policy SelectionPolicy) (*Installation, error) {
+	installations, err := d.DetectAll()
+	if err != nil {
+		return nil, err
+	}
+	return SelectInstallation(installations, policy)
+}
+
+// SelectInstallation 按策略从 DetectAll 返回的安装中选择一个
+//
+// 参数：
+//   - installations: 候选安装
+//   - policy: 选择策略
+//
+// 返回值：
+//   - *Installation: 选中的安装
+//   - error: 如果 MinVersion 无效，或者没有满足条件的安装，则返回相应的错误信息
+//
+// 功能说明：
+//
+//	先排除版本低于 MinVersion 的安装；PreferProjectLocal 为 true 且剩余的安装中有项目内的安装时，
+//	只在项目内的安装中选择；PreferNewest 为 true 时选择版本最高的安装，版本相同时按原顺序，
+//	否则选择第一个。
+func SelectInstallation(installations []Installation, policy SelectionPolicy) (*Installation, error) {
+	var minVersion *resolver.Version
+	if policy.MinVersion != "" {
+		v, err := resolver.ParseVersion(policy.MinVersion)
+		if err != nil {
+			return nil, fmt.Errorf("无效的最低版本 %q: %w", policy.MinVersion, err)
+		}
+		minVersion = v
+	}
+
+	type versioned struct {
+		inst    *Installation
+		version *resolver.Version
+	}
+	var matches []versioned
+	for i := range installations {
+		v, _ := resolver.ParseVersion(installations[i].Version)
+		if minVersion != nil && (v == nil || v.Compare(minVersion) < 0) {
+			continue
+		}
+		matches = append(matches, versioned{inst: &installations[i], version: v})
+	}
+	if len(matches) == 0 {
+		if minVersion != nil {
+			return nil, fmt.Errorf("%w: 没有版本不低于 %s 的安装", ErrExecutableNotFound, policy.MinVersion)
+		}
+		return nil, ErrExecutableNotFound
+	}
+
+	if policy.PreferProjectLocal {
+		var local []versioned
+		for _, m := range matches {
+			if m.inst.ProjectLocal {
+				local = append(local, m)
+			}
+		}
+		if len(local) > 0 {
+			matches = local
+		}
+	}
+
+	best := matches[0]
+	if policy.PreferNewest {
+		for _, m := range matches[1:] {
+			if m.version != nil && (best.version == nil || m.version.Compare(best.version) > 0) {
+				best = m
+			}
+		}
+	}
+	return best.inst, nil
+}
+
+// ResolvePhar 返回 Composer 可执行文件实际运行的 composer.phar 或 PHP 脚本
+//
+// 参数：
+//   - executablePath: Composer 可执行文件的路径
+//
+// 返回值：
+//   - string: 可执行文件以 .phar 结尾或者是 PHP 脚本（以 #!/usr/bin/env php 或 <?php 开头）时
+//     返回其本身，是包装脚本时返回其中引用的 .phar 路径，%~dp0 替换为包装脚本所在目录
+//   - error: 如果无法读取文件或无法确定对应的 .phar，则返回相应的错误信息
+func ResolvePhar(executablePath string) (string, error) {
+	if strings.HasSuffix(strings.ToLower(executablePath), ".phar") {
+		return executablePath, nil
+	}
+
+	content, err := readHead(executablePath)
+	if err != nil {
+		return "", err
+	}
+	if isPHPScript(content) {
+		return executablePath, nil
+	}
+	if phar, _ := wrapperPhar(executablePath, content); phar != "" {
+		return phar, nil
+	}
+	return "", fmt.Errorf("无法确定 %s 对应的 composer.phar", executablePath)
+}
+
+// candidates 按检测顺序返回所有候选路径
+func (d *Detector) candidates(projectDir string) []candidate {
+	var list []candidate
+	if envPath := os.Getenv("COMPOSER_PATH"); envPath != "" {
+		list = append(list, candidate{envPath, SourceEnv})
+	}
+	for _, path := range d.possiblePaths {
+		list = append(list, candidate{path, SourcePossiblePath})
+	}
+	for _, path := range findInPath() {
+		list = append(list, candidate{path, SourcePath})
+	}
+	if projectDir != "" {
+		list = append(list,
+			candidate{filepath.Join(projectDir, "composer.phar"), SourceProject},
+			candidate{filepath.Join(projectDir, "vendor", "bin", "composer"), SourceProject},
+		)
+	}
+	return list
+}
+
+// inspectInstallation 判断安装的类型、使用的 PHP 和版本
+func inspectInstallation(path string) Installation {
+	inst := Installation{Path: path, Kind: KindScript, PharPath: path}
+
+	content, _ := readHead(path)
+	phpToken := ""
+	switch {
+	case strings.HasSuffix(strings.ToLower(path), ".phar") || strings.Contains(content, "Phar::mapPhar"):
+		inst.Kind = KindPhar
+		phpToken = shebangPHP(content)
+	case isPHPScript(content):
+		phpToken = shebangPHP(content)
+	default:
+		if phar, token := wrapperPhar(path, content); phar != "" {
+			inst.Kind = KindWrapper
+			inst.PharPath = phar
+			phpToken = token
+		}
+	}
+	inst.PHPBinary = lookupPHP(phpToken)
+	inst.Version = composerVersion(inst)
+	return inst
+}
+
+// composerVersion 运行 composer --version 读取版本号，失败时返回空字符串
+func composerVersion(inst Installation) string {
+	name, args := inst.Path, []string{"--version", "--no-ansi"}
+	if !isExecutable(inst.Path) {
+		if inst.PHPBinary == "" {
+			return ""
+		}
+		name, args = inst.PHPBinary, append([]string{inst.PharPath}, args...)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), composerVersionTimeout)
+	defer cancel()
+	output, err := exec.CommandContext(ctx, name, args...).Output()
+	if err != nil {
+		return ""
+	}
+	version, _ := ParseComposerVersion(string(output))
+	return version
+}
+
+// ParseComposerVersion 从 composer --version 的输出中提取版本号
+//
+// 参数：
+//   - output: composer --version 的输出，可以包含 PHP 警告等其他行
+//
+// 返回值：
+//   - string: 版本号，例如 "2.7.7"
+//   - bool: 输出中是否包含版本号
+//
+// 功能说明：
+//
+//	支持 "Composer version 2.7.7 2024-06-10 22:11:12" 以及旧版本的 "Composer 1.10.1" 等格式，
+//	版本号前的 v 会被去掉。
+func ParseComposerVersion(output string) (string, bool) {
+	match := composerVersionPattern.FindStringSubmatch(output)
+	if match == nil {
+		return "", false
+	}
+	return match[1], true
+}
+
+// readHead 读取文件开头的 4096 字节
+func readHead(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	head := make([]byte, 4096)
+	n, _ := f.Read(head)
+	return string(head[:n]), nil
+}
+
+// isPHPScript 判断文件内容是否为 PHP 脚本
+func isPHPScript(content string) bool {
+	firstLine, _, _ := strings.Cut(content, "\n")
+	return strings.HasPrefix(content, "<?php") || (strings.HasPrefix(firstLine, "#!") && strings.Contains(firstLine, "php"))
+}
+
+// shebangPHP 返回 shebang 中的 PHP，例如 #!/usr/bin/env php 返回 php，没有时返回空字符串
+func shebangPHP(content string) string {
+	firstLine, _, _ := strings.Cut(content, "\n")
+	if !strings.HasPrefix(firstLine, "#!") {
+		return ""
+	}
+	fields := strings.Fields(strings.TrimPrefix(strings.TrimSpace(firstLine), "#!"))
+	for i := len(fields) - 1; i >= 0; i-- {
+		if strings.Contains(filepath.Base(fields[i]), "php") {
+			return fields[i]
+		}
+	}
+	return ""
+}
+
+// wrapperPhar 返回包装脚本引用的 .phar 及其前面调用的 PHP，不是包装脚本时返回空字符串
+func wrapperPhar(path, content string) (phar, php string) {
+	loc := wrapperPharPattern.FindStringSubmatchIndex(content)
+	if loc == nil {
+		return "", ""
+	}
+
+	dir := filepath.Dir(path) + string(filepath.Separator)
+	phar = strings.ReplaceAll(content[loc[2]:loc[3]], "%~dp0", dir)
+	if !filepath.IsAbs(phar) {
+		phar = filepath.Join(filepath.Dir(path), phar)
+	}
+
+	lineStart := strings.LastIndex(content[:loc[0]], "\n") + 1
+	if fields := strings.Fields(content[lineStart:loc[0]]); len(fields) > 0 {
+		php = strings.Trim(strings.TrimPrefix(fields[len(fields)-1], "@"), `"`)
+		php = strings.ReplaceAll(php, "%~dp0", dir)
+	}
+	return phar, php
+}
+
+// lookupPHP 将 PHP 名称或路径解析为可执行文件路径，为空时使用 PATH 中的 php
+func lookupPHP(php string) string {
+	if php == "" {
+		php = "php"
+	}
+	if filepath.IsAbs(php) {
+		if isExecutable(php) {
+			return php
+		}
+		return ""
+	}
+	if path, err := exec.LookPath(php); err == nil {
+		return path
+	}
+	return ""
+}
+
+// isRunnable 判断路径是否为可以运行的 Composer：可执行文件，或者可以通过 PHP 运行的 .phar
+func isRunnable(path string) bool {
+	if isExecutable(path) {
+		return true
+	}
+	info, err := os.Stat(path)
+	return err == nil && info.Mode().IsRegular() && strings.HasSuffix(strings.ToLower(path), ".phar")
+}
+
+// isWithin 判断路径是否位于目录中
+func isWithin(dir, path string) bool {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+	rel, err := filepath.Rel(dir, abs)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
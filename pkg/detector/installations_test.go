@@ -0,0 +1,179 @@
+//go:build !windows
+
+package detector
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeFakeComposer 创建一个输出指定版本的 composer 脚本
+func writeFakeComposer(t *testing.T, path, content string, perm os.FileMode) string {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), perm); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestResolvePhar(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		return writeFakeComposer(t, filepath.Join(dir, name), content, 0755)
+	}
+
+	phar := write("composer.phar", "phar")
+	if got, err := ResolvePhar(phar); err != nil || got != phar {
+		t.Errorf("ResolvePhar(phar) = %q, %v", got, err)
+	}
+
+	// 官方发布的 composer 本身就是 PHP 脚本
+	script := write("composer", "#!/usr/bin/env php\n<?php\n")
+	if got, err := ResolvePhar(script); err != nil || got != script {
+		t.Errorf("ResolvePhar(script) = %q, %v", got, err)
+	}
+
+	wrapper := write("composer-wrapper", "#!/bin/sh\nexec php \"/opt/composer/composer.phar\" \"$@\"\n")
+	if got, err := ResolvePhar(wrapper); err != nil || got != "/opt/composer/composer.phar" {
+		t.Errorf("ResolvePhar(wrapper) = %q, %v", got, err)
+	}
+
+	bat := write("composer.bat", "@php \"%~dp0composer.phar\" %*")
+	if got, err := ResolvePhar(bat); err != nil || got != filepath.Join(dir, "composer.phar") {
+		t.Errorf("ResolvePhar(bat) = %q, %v", got, err)
+	}
+
+	if _, err := ResolvePhar(write("unknown", "#!/bin/sh\necho composer\n")); err == nil {
+		t.Error("ResolvePhar() 应该在无法确定 .phar 时返回错误")
+	}
+}
+
+func TestDetectAll(t *testing.T) {
+	binDir := t.TempDir()
+	pathDir := t.TempDir()
+	projectDir := t.TempDir()
+
+	// PATH 中的 php 执行传入的 composer 脚本，用于运行不可执行的 composer.phar
+	writeFakeComposer(t, filepath.Join(pathDir, "php"), "#!/bin/sh\nexec /bin/sh \"$@\"\n", 0755)
+	t.Setenv("PATH", pathDir)
+	t.Setenv("COMPOSER_PATH", "")
+
+	wrapperPhar := writeFakeComposer(t, filepath.Join(binDir, "composer.phar"), "echo 'Composer version 2.2.24 2024-06-10 22:11:12'\n", 0644)
+	wrapper := writeFakeComposer(t, filepath.Join(binDir, "composer"),
+		"#!/bin/sh\nexec php \""+wrapperPhar+"\" \"$@\"\n", 0755)
+	pathComposer := writeFakeComposer(t, filepath.Join(pathDir, "composer"),
+		"#!/bin/sh\n# Phar::mapPhar\necho 'Composer version 2.7.7 2024-06-10 22:11:12'\n", 0755)
+	localPhar := writeFakeComposer(t, filepath.Join(projectDir, "composer.phar"),
+		"echo 'Composer version 2.5.8 2023-06-09 17:13:21'\n", 0644)
+	// 指向 PATH 中 composer 的符号链接只保留第一个
+	if err := os.Symlink(pathComposer, filepath.Join(binDir, "composer-link")); err != nil {
+		t.Fatal(err)
+	}
+
+	d := NewDetector()
+	d.SetPossiblePaths([]string{wrapper, filepath.Join(binDir, "composer-link"), filepath.Join(binDir, "missing")})
+	d.SetProjectDir(projectDir)
+
+	installations, err := d.DetectAll()
+	if err != nil {
+		t.Fatalf("DetectAll() error = %v", err)
+	}
+	if len(installations) != 3 {
+		t.Fatalf("期望找到 3 个安装，实际为 %+v", installations)
+	}
+
+	first := installations[0]
+	if first.Path != wrapper || first.Source != SourcePossiblePath || first.Kind != KindWrapper ||
+		first.PharPath != wrapperPhar || first.Version != "2.2.24" || first.PHPBinary != filepath.Join(pathDir, "php") {
+		t.Errorf("包装脚本的检测结果不正确: %+v", first)
+	}
+
+	link := installations[1]
+	resolved, _ := filepath.EvalSymlinks(pathComposer)
+	if link.Kind != KindPhar || link.Version != "2.7.7" || link.ResolvedPath != resolved || link.ProjectLocal {
+		t.Errorf("符号链接的检测结果不正确: %+v", link)
+	}
+
+	local := installations[2]
+	if local.Path != localPhar || local.Source != SourceProject || !local.ProjectLocal || local.Kind != KindPhar || local.Version != "2.5.8" {
+		t.Errorf("项目内 composer.phar 的检测结果不正确: %+v", local)
+	}
+
+	tests := []struct {
+		name   string
+		policy SelectionPolicy
+		want   string
+	}{
+		{"默认选择第一个", SelectionPolicy{}, wrapper},
+		{"选择最新版本", SelectionPolicy{PreferNewest: true}, link.Path},
+		{"优先项目内安装", SelectionPolicy{PreferProjectLocal: true, PreferNewest: true}, localPhar},
+		{"最低版本", SelectionPolicy{MinVersion: "2.3"}, link.Path},
+		{"项目内安装不满足最低版本", SelectionPolicy{MinVersion: "2.6", PreferProjectLocal: true}, link.Path},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SelectInstallation(installations, tt.policy)
+			if err != nil {
+				t.Fatalf("SelectInstallation() error = %v", err)
+			}
+			if got.Path != tt.want {
+				t.Errorf("SelectInstallation() = %s，期望 %s", got.Path, tt.want)
+			}
+		})
+	}
+
+	if _, err := d.Select(SelectionPolicy{MinVersion: "3.0"}); !errors.Is(err, ErrExecutableNotFound) {
+		t.Errorf("Select() error = %v，期望 ErrExecutableNotFound", err)
+	}
+	if _, err := SelectInstallation(installations, SelectionPolicy{MinVersion: "not a version"}); err == nil {
+		t.Error("SelectInstallation() 应该在最低版本无效时返回错误")
+	}
+}
+
+func TestDetectAllNotFound(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+	t.Setenv("COMPOSER_PATH", "")
+
+	d := NewDetector()
+	d.SetPossiblePaths(nil)
+	d.SetProjectDir(t.TempDir())
+	if _, err := d.DetectAll(); !errors.Is(err, ErrExecutableNotFound) {
+		t.Errorf("DetectAll() error = %v，期望 ErrExecutableNotFound", err)
+	}
+
+	// 未设置项目目录时不查找当前目录中的 composer.phar
+	cwd := t.TempDir()
+	writeFakeComposer(t, filepath.Join(cwd, "composer.phar"), "<?php\n", 0644)
+	oldwd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(cwd); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(oldwd)
+
+	d.SetProjectDir("")
+	if installs, err := d.DetectAll(); !errors.Is(err, ErrExecutableNotFound) {
+		t.Errorf("未设置项目目录时 DetectAll() = %v, %v，期望 ErrExecutableNotFound", installs, err)
+	}
+}
+
+func TestDetectSearchesPath(t *testing.T) {
+	dir := t.TempDir()
+	path := writeFakeComposer(t, filepath.Join(dir, "composer"), "#!/bin/sh\n", 0755)
+	t.Setenv("PATH", dir)
+	t.Setenv("COMPOSER_PATH", "")
+
+	d := NewDetector()
+	d.SetPossiblePaths(nil)
+	got, err := d.Detect()
+	if err != nil || got != path {
+		t.Errorf("Detect() = %q, %v，期望 %q", got, err, path)
+	}
+}
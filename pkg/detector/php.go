@@ -0,0 +1,174 @@
+package detector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ErrPHPNotFound 表示未找到可用的 PHP 可执行文件
+var ErrPHPNotFound = errors.New("未找到PHP可执行文件")
+
+// phpProbeScript 输出 PHP 版本、SAPI、加载的 php.ini 和扩展列表，每项一行
+const phpProbeScript = `echo PHP_VERSION, "\n", PHP_SAPI, "\n", (string) php_ini_loaded_file(), "\n", implode(",", get_loaded_extensions()), "\n";`
+
+// phpInspectTimeout 是运行 php -r 读取信息的超时时间
+const phpInspectTimeout = 10 * time.Second
+
+// phpBinaryPattern 匹配 PHP CLI 可执行文件名，例如 php、php8.2、php82、php.exe
+var phpBinaryPattern = regexp.MustCompile(`^php(\d+(\.\d+)?)?(\.exe)?$`)
+
+// PHPRuntime 描述一个 PHP 可执行文件及其运行时信息
+type PHPRuntime struct {
+	// Path PHP 可执行文件的路径
+	Path string
+	// Version PHP 版本号，例如 8.3.1
+	Version string
+	// SAPI 服务器 API，命令行版本为 cli
+	SAPI string
+	// IniFile 加载的 php.ini 路径，没有加载时为空
+	IniFile string
+	// Extensions 已加载的扩展名，小写并按字母排序
+	Extensions []string
+}
+
+// HasExtension 判断是否加载了指定的扩展，不区分大小写
+func (r *PHPRuntime) HasExtension(name string) bool {
+	name = strings.ToLower(name)
+	i := sort.SearchStrings(r.Extensions, name)
+	return i < len(r.Extensions) && r.Extensions[i] == name
+}
+
+// FindPHPBinaries 查找系统中所有的 PHP 可执行文件
+//
+// 返回值：
+//   - []string: PHP 可执行文件路径，按优先级排序，指向同一文件的符号链接只保留第一个
+//
+// 功能说明：
+//
+//	依次检查 PHP_BINARY 环境变量、PATH 中的 php，以及平台特定的位置，
+//	例如 /usr/bin/php8.*、Homebrew、phpenv 和 phpbrew 的版本目录。
+//	该方法只查找文件，不会运行 PHP。
+//
+// 用法示例：
+//
+//	for _, path := range detector.FindPHPBinaries() {
+//	    fmt.Println(path)
+//	}
+func FindPHPBinaries() []string {
+	var candidates []string
+	if env := os.Getenv("PHP_BINARY"); env != "" {
+		candidates = append(candidates, env)
+	}
+	if path, err := exec.LookPath("php"); err == nil {
+		candidates = append(candidates, path)
+	}
+	for _, pattern := range getPHPSearchPatterns() {
+		matches, _ := filepath.Glob(pattern)
+		sort.Strings(matches)
+		for _, match := range matches {
+			if phpBinaryPattern.MatchString(strings.ToLower(filepath.Base(match))) {
+				candidates = append(candidates, match)
+			}
+		}
+	}
+
+	var binaries []string
+	seen := map[string]bool{}
+	for _, candidate := range candidates {
+		if !isExecutable(candidate) {
+			continue
+		}
+		key := candidate
+		if resolved, err := filepath.EvalSymlinks(candidate); err == nil {
+			key = resolved
+		}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		binaries = append(binaries, candidate)
+	}
+	return binaries
+}
+
+// InspectPHP 运行 php -r 读取 PHP 可执行文件的版本、SAPI、php.ini 和扩展
+//
+// 参数：
+//   - path: PHP 可执行文件的路径
+//
+// 返回值：
+//   - *PHPRuntime: PHP 运行时信息
+//   - error: 如果无法运行或输出无法解析，则返回相应的错误信息
+//
+// 用法示例：
+//
+//	php, err := detector.InspectPHP("/usr/bin/php8.2")
+//	if err == nil && !php.HasExtension("mbstring") {
+//	    fmt.Println("缺少 mbstring 扩展")
+//	}
+func InspectPHP(path string) (*PHPRuntime, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), phpInspectTimeout)
+	defer cancel()
+
+	output, err := exec.CommandContext(ctx, path, "-r", phpProbeScript).Output()
+	if err != nil {
+		return nil, fmt.Errorf("运行 %s 失败: %w", path, err)
+	}
+
+	lines := strings.Split(strings.ReplaceAll(string(output), "\r\n", "\n"), "\n")
+	if len(lines) < 4 || strings.TrimSpace(lines[0]) == "" {
+		return nil, fmt.Errorf("无法解析 %s 的输出: %q", path, string(output))
+	}
+
+	php := &PHPRuntime{
+		Path:    path,
+		Version: strings.TrimSpace(lines[0]),
+		SAPI:    strings.TrimSpace(lines[1]),
+		IniFile: strings.TrimSpace(lines[2]),
+	}
+	for _, ext := range strings.Split(lines[3], ",") {
+		if ext = strings.ToLower(strings.TrimSpace(ext)); ext != "" {
+			php.Extensions = append(php.Extensions, ext)
+		}
+	}
+	sort.Strings(php.Extensions)
+	return php, nil
+}
+
+// DetectPHP 查找并检查系统中所有可用的 PHP
+//
+// 返回值：
+//   - []PHPRuntime: 可以运行的 PHP，顺序与 FindPHPBinaries 相同
+//   - error: 如果没有找到可以运行的 PHP，则返回 ErrPHPNotFound
+//
+// 用法示例：
+//
+//	runtimes, err := detector.DetectPHP()
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	for _, php := range runtimes {
+//	    fmt.Printf("%s: PHP %s (%s)\n", php.Path, php.Version, php.IniFile)
+//	}
+func DetectPHP() ([]PHPRuntime, error) {
+	var runtimes []PHPRuntime
+	for _, path := range FindPHPBinaries() {
+		php, err := InspectPHP(path)
+		if err != nil {
+			continue
+		}
+		runtimes = append(runtimes, *php)
+	}
+	if len(runtimes) == 0 {
+		return nil, ErrPHPNotFound
+	}
+	return runtimes, nil
+}
@@ -0,0 +1,78 @@
+//go:build !windows
+
+package detector
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeFakePHP 创建一个输出固定探测结果的 php 脚本
+func writeFakePHP(t *testing.T, path, version string) {
+	t.Helper()
+	script := "#!/bin/sh\nprintf '" + version + "\\ncli\\n/etc/php/php.ini\\nCore,json,Phar,openssl\\n'\n"
+	if err := os.WriteFile(path, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestInspectPHP(t *testing.T) {
+	php := filepath.Join(t.TempDir(), "php")
+	writeFakePHP(t, php, "8.2.10")
+
+	runtime, err := InspectPHP(php)
+	if err != nil {
+		t.Fatalf("InspectPHP() error = %v", err)
+	}
+	if runtime.Version != "8.2.10" || runtime.SAPI != "cli" || runtime.IniFile != "/etc/php/php.ini" {
+		t.Errorf("InspectPHP() = %+v", runtime)
+	}
+	if !runtime.HasExtension("phar") || !runtime.HasExtension("JSON") || runtime.HasExtension("mbstring") {
+		t.Errorf("扩展列表不正确: %v", runtime.Extensions)
+	}
+
+	broken := filepath.Join(t.TempDir(), "php")
+	if err := os.WriteFile(broken, []byte("#!/bin/sh\nexit 1\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := InspectPHP(broken); err == nil {
+		t.Error("InspectPHP() 应该在 PHP 无法运行时返回错误")
+	}
+}
+
+func TestFindPHPBinaries(t *testing.T) {
+	pathDir := t.TempDir()
+	php := filepath.Join(pathDir, "php")
+	writeFakePHP(t, php, "8.3.1")
+
+	// PHP_BINARY 优先，指向同一文件的符号链接只保留一个
+	custom := filepath.Join(t.TempDir(), "php7.4")
+	writeFakePHP(t, custom, "7.4.33")
+	link := filepath.Join(t.TempDir(), "php")
+	if err := os.Symlink(php, link); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("PHP_BINARY", custom)
+	t.Setenv("PATH", pathDir+string(os.PathListSeparator)+filepath.Dir(link))
+	t.Setenv("HOME", t.TempDir())
+
+	binaries := FindPHPBinaries()
+	if len(binaries) < 2 || binaries[0] != custom || binaries[1] != php {
+		t.Fatalf("FindPHPBinaries() = %v", binaries)
+	}
+	for _, binary := range binaries[2:] {
+		if binary == link {
+			t.Errorf("符号链接 %s 应该被去重", link)
+		}
+	}
+
+	runtimes, err := DetectPHP()
+	if err != nil {
+		t.Fatalf("DetectPHP() error = %v", err)
+	}
+	if runtimes[0].Version != "7.4.33" || runtimes[1].Version != "8.3.1" {
+		t.Errorf("DetectPHP() 顺序不正确: %v, %v", runtimes[0].Version, runtimes[1].Version)
+	}
+}
@@ -115,3 +115,28 @@ func TestUnixSpecificEnvironment(t *testing.T) {
 		t.Logf("检测到的路径与预期不符，预期: %s，实际: %s", composerPath, detectedPath)
 	}
 }
+
+func TestUnixUserBinPath(t *testing.T) {
+	t.Setenv("XDG_BIN_HOME", "/opt/user/bin")
+	assert := func(expected string) {
+		t.Helper()
+		for _, path := range getPlatformSpecificPaths() {
+			if path == expected {
+				return
+			}
+		}
+		t.Errorf("Unix/Linux路径中应包含用户安装路径 %s", expected)
+	}
+	assert("/opt/user/bin/composer")
+
+	t.Setenv("XDG_BIN_HOME", "")
+	assert(filepath.Join(os.Getenv("HOME"), ".local", "bin", "composer"))
+
+	// 无法确定用户主目录时不返回相对路径
+	t.Setenv("HOME", "")
+	for _, path := range getPlatformSpecificPaths() {
+		if path == filepath.Join(".local", "bin", "composer") {
+			t.Errorf("不应包含相对的用户安装路径 %s", path)
+		}
+	}
+}
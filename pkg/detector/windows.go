@@ -12,6 +12,7 @@ import (
 func getPlatformSpecificPaths() []string {
 	return []string{
 		filepath.Join(os.Getenv("APPDATA"), "Composer", "composer.phar"),
+		filepath.Join(os.Getenv("LOCALAPPDATA"), "Composer", "composer.bat"),
 		filepath.Join(os.Getenv("ProgramFiles"), "Composer", "composer.phar"),
 		filepath.Join(os.Getenv("ProgramFiles(x86)"), "Composer", "composer.phar"),
 		"composer.phar",
@@ -19,3 +20,18 @@ func getPlatformSpecificPaths() []string {
 		"composer",
 	}
 }
+
+// getPHPSearchPatterns 返回 Windows 平台上 PHP 可执行文件的查找模式
+func getPHPSearchPatterns() []string {
+	return []string{
+		`C:\php*\php.exe`,
+		`C:\tools\php*\php.exe`,
+		filepath.Join(os.Getenv("USERPROFILE"), "scoop", "apps", "php*", "current", "php.exe"),
+		filepath.Join(os.Getenv("ProgramFiles"), "PHP", "*", "php.exe"),
+	}
+}
+
+// getExecutableNames 返回 Windows 上在 PATH 中查找的 Composer 文件名
+func getExecutableNames() []string {
+	return []string{"composer.bat", "composer.cmd", "composer.exe", "composer.phar"}
+}
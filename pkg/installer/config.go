@@ -1,6 +1,8 @@
 package installer
 
 import (
+	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"runtime"
@@ -10,8 +12,33 @@ import (
 type Config struct {
 	// 下载URL
 	DownloadURL string
+	// 安装脚本签名地址，内容为安装脚本的 SHA-384 十六进制值
+	SignatureURL string
+	// 固定的安装脚本 SHA-384 校验和，设置后不再请求 SignatureURL
+	InstallerChecksum string
+	// 安装的 Composer 版本，例如 2.7.7；设置后直接下载 composer.phar 而不运行安装脚本
+	Version string
+	// 安装的发布渠道，例如 stable、preview、snapshot、1.x、2.x、2.2.x，Version 为空时生效
+	Channel string
+	// 下载 composer.phar 的镜像地址，为空时使用 DefaultPharMirrorURL
+	PharMirrorURL string
+	// 固定的 composer.phar SHA-256 校验和，设置后不再读取 .sha256sum 文件
+	PharChecksum string
+	// 离线安装使用的本地 composer.phar 路径，设置后不再访问网络
+	LocalPharPath string
+	// 离线安装使用的 composer.phar 内容，只能读取一次，必须同时设置 PharChecksum
+	PharReader io.Reader
+	// 离线安装使用的文件系统，例如 embed.FS，composer.phar 在其中的路径由 PharFSPath 指定
+	PharFS fs.FS
+	// composer.phar 在 PharFS 中的路径，为空时使用 composer.phar
+	PharFSPath string
+	// 安装范围，ScopeUser 表示安装到用户目录，为空时与 ScopeSystem 相同
+	Scope string
 	// 安装路径
 	InstallPath string
+	// 用户范围安装时 composer.phar 的存放目录，为空时使用 UserDataDir()；
+	// 只有 UserInstaller 和用户范围的 Plan 使用该字段，其他安装器总是将 composer.phar 放在 InstallPath 中
+	PharDir string
 	// 是否使用代理
 	UseProxy bool
 	// 代理地址
@@ -28,6 +55,8 @@ type Config struct {
 func DefaultConfig() Config {
 	config := Config{
 		DownloadURL:     "https://getcomposer.org/installer",
+		SignatureURL:    "https://composer.github.io/installer.sig",
+		PharMirrorURL:   DefaultPharMirrorURL,
 		TimeoutSeconds:  300,
 		UseProxy:        false,
 		PreferBrewOnMac: true, // 默认优先使用brew安装
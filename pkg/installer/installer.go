@@ -3,6 +3,7 @@ package installer
 
 import (
 	"errors"
+	"path/filepath"
 )
 
 var (
@@ -14,6 +15,12 @@ var (
 	ErrUnsupportedPlatform = errors.New("不支持的操作系统平台")
 	// ErrDownloadFailed 表示下载失败
 	ErrDownloadFailed = errors.New("下载失败")
+	// ErrChecksumMismatch 表示下载文件的校验和与期望值不一致
+	ErrChecksumMismatch = errors.New("校验和不匹配")
+	// ErrNotInstalled 表示目标路径中没有安装 Composer
+	ErrNotInstalled = errors.New("Composer 未安装")
+	// ErrNoBackup 表示没有可以回滚的备份
+	ErrNoBackup = errors.New("没有可回滚的备份")
 )
 
 // Installer 负责安装Composer
@@ -56,3 +63,41 @@ func (i *Installer) Install() error {
 	// 执行安装
 	return platformInstaller.Install()
 }
+
+// Uninstall 卸载Composer
+func (i *Installer) Uninstall() error {
+	platformInstaller, err := GetPlatformInstaller(i.config)
+	if err != nil {
+		return err
+	}
+	return platformInstaller.Uninstall()
+}
+
+// Upgrade 将Composer升级到指定版本，targetVersion 为空时升级到配置的渠道或最新稳定版
+func (i *Installer) Upgrade(targetVersion string) error {
+	platformInstaller, err := GetPlatformInstaller(i.config)
+	if err != nil {
+		return err
+	}
+	return platformInstaller.Upgrade(targetVersion)
+}
+
+// Rollback 回滚到上一次升级前的Composer版本
+func (i *Installer) Rollback() error {
+	platformInstaller, err := GetPlatformInstaller(i.config)
+	if err != nil {
+		return err
+	}
+	return platformInstaller.Rollback()
+}
+
+// ExecutablePath 返回安装完成后 Composer 可执行文件的路径
+//
+// 用户范围安装且未设置 InstallPath 时使用 UserBinDir()。
+func (i *Installer) ExecutablePath() string {
+	dir := i.config.InstallPath
+	if dir == "" && i.config.Scope == ScopeUser {
+		dir = UserBinDir()
+	}
+	return filepath.Join(dir, executableName())
+}
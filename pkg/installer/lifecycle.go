@@ -0,0 +1,375 @@
+package installer
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/scagogogo/go-composer-sdk/pkg/utils"
+)
+
+// InstallManifest 记录一次 Composer 安装的元数据
+//
+// 清单保存在 composer.phar 旁边的 composer.phar.json 中，由 Install、Upgrade 和 Rollback 维护。
+type InstallManifest struct {
+	// Version 安装的 Composer 版本，通过渠道或安装脚本安装时可能为空
+	Version string `json:"version,omitempty"`
+	// Channel 安装的发布渠道，指定了 Version 时为空
+	Channel string `json:"channel,omitempty"`
+	// SourceURL composer.phar 或安装脚本的下载地址
+	SourceURL string `json:"source_url,omitempty"`
+	// Checksum composer.phar 的 SHA-256 十六进制值
+	Checksum string `json:"checksum"`
+	// InstalledAt 安装时间（UTC）
+	InstalledAt time.Time `json:"installed_at"`
+	// Previous 备份文件 composer.phar.bak 对应的安装记录，没有备份时为 nil
+	Previous *InstallManifest `json:"previous,omitempty"`
+}
+
+// ManifestPath 返回 composer.phar 对应的安装清单路径
+func ManifestPath(pharPath string) string {
+	return pharPath + ".json"
+}
+
+// BackupPath 返回 composer.phar 升级前的备份路径
+func BackupPath(pharPath string) string {
+	return pharPath + ".bak"
+}
+
+// ReadManifest 读取 composer.phar 对应的安装清单
+//
+// 参数：
+//   - pharPath: composer.phar 的路径
+//
+// 返回值：
+//   - *InstallManifest: 安装清单
+//   - error: 清单不存在时返回的错误满足 errors.Is(err, os.ErrNotExist)
+//
+// 用法示例：
+//
+//	manifest, err := installer.ReadManifest("/usr/local/bin/composer.phar")
+//	if err == nil {
+//	    fmt.Printf("Composer %s，安装于 %s\n", manifest.Version, manifest.InstalledAt)
+//	}
+func ReadManifest(pharPath string) (*InstallManifest, error) {
+	data, err := os.ReadFile(ManifestPath(pharPath))
+	if err != nil {
+		return nil, err
+	}
+
+	var manifest InstallManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("解析安装清单失败: %w", err)
+	}
+	return &manifest, nil
+}
+
+// lifecycle 实现各平台安装器共用的卸载、升级和回滚逻辑
+type lifecycle struct {
+	config    Config
+	pharPath  string
+	binPath   string
+	allowSudo bool
+}
+
+// uninstall 删除包装脚本、composer.phar、备份和安装清单，文件不存在时忽略
+func (l lifecycle) uninstall() error {
+	for _, path := range []string{l.binPath, l.pharPath, BackupPath(l.pharPath), ManifestPath(l.pharPath)} {
+		if _, err := os.Lstat(path); err != nil {
+			continue
+		}
+		ops, err := newFileOps(filepath.Dir(path), l.allowSudo)
+		if err != nil {
+			return err
+		}
+		if err := ops.remove(path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// upgrade 下载并校验目标版本，将当前的 composer.phar 保留为备份后原子地替换
+//
+// 离线配置时使用本地来源的 composer.phar，targetVersion 只记录在安装清单中。
+func (l lifecycle) upgrade(targetVersion string) error {
+	if _, err := os.Stat(l.pharPath); err != nil {
+		return fmt.Errorf("%w: %s", ErrNotInstalled, l.pharPath)
+	}
+
+	config := l.config
+	config.Version = targetVersion
+	if config.Version == "" && config.Channel == "" && !config.IsOffline() {
+		config.Channel = ChannelStable
+	}
+
+	dir := filepath.Dir(l.pharPath)
+	ops, err := newFileOps(dir, l.allowSudo)
+	if err != nil {
+		return err
+	}
+
+	tmpPath, manifest, err := stagePhar(config, ops.stagingDir(dir))
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpPath)
+
+	previous, err := currentManifest(l.pharPath)
+	if err != nil {
+		return err
+	}
+	previous.Previous = nil
+	manifest.Previous = previous
+
+	if err := ops.link(l.pharPath, BackupPath(l.pharPath)); err != nil {
+		return err
+	}
+	if err := ops.place(tmpPath, l.pharPath, 0755); err != nil {
+		return err
+	}
+	return ops.writeManifest(l.pharPath, manifest)
+}
+
+// rollback 将备份的 composer.phar 与当前版本交换，再次调用会恢复到回滚前的版本
+func (l lifecycle) rollback() error {
+	backup := BackupPath(l.pharPath)
+	if _, err := os.Stat(backup); err != nil {
+		return fmt.Errorf("%w: %s", ErrNoBackup, backup)
+	}
+	if _, err := os.Stat(l.pharPath); err != nil {
+		return fmt.Errorf("%w: %s", ErrNotInstalled, l.pharPath)
+	}
+
+	current, err := currentManifest(l.pharPath)
+	if err != nil {
+		return err
+	}
+	previous := current.Previous
+	if previous == nil {
+		previous = &InstallManifest{}
+	}
+
+	// 确认备份文件没有被修改
+	checksum, err := fileSHA256(backup)
+	if err != nil {
+		return err
+	}
+	if previous.Checksum != "" && previous.Checksum != checksum {
+		return fmt.Errorf("%w: 备份文件期望 %s，实际 %s", ErrChecksumMismatch, previous.Checksum, checksum)
+	}
+	previous.Checksum = checksum
+
+	ops, err := newFileOps(filepath.Dir(l.pharPath), l.allowSudo)
+	if err != nil {
+		return err
+	}
+
+	// 先为当前版本保留一个链接，再用 rename 原子地换上备份
+	held := l.pharPath + ".rollback"
+	if err := ops.link(l.pharPath, held); err != nil {
+		return err
+	}
+	if err := ops.rename(backup, l.pharPath); err != nil {
+		ops.remove(held)
+		return err
+	}
+	if err := ops.rename(held, backup); err != nil {
+		return err
+	}
+
+	current.Previous = nil
+	previous.Previous = current
+	return ops.writeManifest(l.pharPath, previous)
+}
+
+// currentManifest 读取当前安装的清单，旧版本安装没有清单时根据 composer.phar 生成
+func currentManifest(pharPath string) (*InstallManifest, error) {
+	manifest, err := ReadManifest(pharPath)
+	if err == nil {
+		return manifest, nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	checksum, err := fileSHA256(pharPath)
+	if err != nil {
+		return nil, err
+	}
+	manifest = &InstallManifest{Checksum: checksum}
+	if info, err := os.Stat(pharPath); err == nil {
+		manifest.InstalledAt = info.ModTime().UTC()
+	}
+	return manifest, nil
+}
+
+// fileOps 在安装目录中放置、链接和删除文件，目录不可写时通过 sudo 执行
+type fileOps struct {
+	sudo bool
+}
+
+// newFileOps 检查目录是否可写，不可写且允许使用 sudo 时返回通过 sudo 执行的 fileOps
+func newFileOps(dir string, allowSudo bool) (fileOps, error) {
+	if err := utils.CheckWritePermission(dir); err != nil {
+		if !allowSudo {
+			return fileOps{}, fmt.Errorf("%w, 目标路径: %s", ErrInsufficientRights, dir)
+		}
+		return fileOps{sudo: true}, nil
+	}
+	return fileOps{}, nil
+}
+
+// stagingDir 返回存放临时文件的目录
+//
+// 直接写入时使用目标目录，保证 rename 是原子的；通过 sudo 写入时使用系统临时目录。
+func (o fileOps) stagingDir(dir string) string {
+	if o.sudo {
+		return ""
+	}
+	return dir
+}
+
+// place 以指定权限将临时文件原子地放到 dest
+func (o fileOps) place(tmpPath, dest string, perm os.FileMode) error {
+	if o.sudo {
+		staged := dest + ".new"
+		if err := runSudo("install", "-m", fmt.Sprintf("%o", perm), tmpPath, staged); err != nil {
+			return err
+		}
+		return runSudo("mv", "-f", staged, dest)
+	}
+
+	if err := os.Chmod(tmpPath, perm); err != nil {
+		return fmt.Errorf("%w: %v", ErrInstallationFailed, err)
+	}
+	return o.rename(tmpPath, dest)
+}
+
+// rename 将 src 重命名为 dst，dst 已存在时被替换
+func (o fileOps) rename(src, dst string) error {
+	if o.sudo {
+		return runSudo("mv", "-f", src, dst)
+	}
+	if err := os.Rename(src, dst); err != nil {
+		return fmt.Errorf("%w: %v", ErrInstallationFailed, err)
+	}
+	return nil
+}
+
+// link 为 src 创建硬链接 dst 并替换已有的 dst，文件系统不支持硬链接时复制文件
+func (o fileOps) link(src, dst string) error {
+	if o.sudo {
+		if runSudo("ln", "-f", src, dst) == nil {
+			return nil
+		}
+		return runSudo("cp", "-p", src, dst)
+	}
+
+	if err := os.Remove(dst); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("%w: %v", ErrInstallationFailed, err)
+	}
+	if os.Link(src, dst) == nil {
+		return nil
+	}
+	return copyFile(src, dst)
+}
+
+// remove 删除文件，文件不存在时忽略
+func (o fileOps) remove(path string) error {
+	if o.sudo {
+		return runSudo("rm", "-f", path)
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("%w: %v", ErrInstallationFailed, err)
+	}
+	return nil
+}
+
+// writeManifest 原子地写入 composer.phar 对应的安装清单
+func (o fileOps) writeManifest(pharPath string, manifest *InstallManifest) error {
+	data, err := json.MarshalIndent(manifest, "", "    ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(o.stagingDir(filepath.Dir(pharPath)), ".composer.phar.json-*")
+	if err != nil {
+		return fmt.Errorf("%w: 无法创建临时文件: %v", ErrInstallationFailed, err)
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath)
+
+	_, err = tmp.Write(append(data, '\n'))
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("%w: 写入安装清单失败: %v", ErrInstallationFailed, err)
+	}
+	return o.place(tmpPath, ManifestPath(pharPath), 0644)
+}
+
+// runSudo 通过 sudo 执行文件操作命令
+func runSudo(name string, args ...string) error {
+	cmd := exec.Command("sudo", append([]string{name}, args...)...)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%w: %s, 错误: %v", ErrInstallationFailed, strings.TrimSpace(string(output)), err)
+	}
+	return nil
+}
+
+// writeWithSudo 通过 sudo tee 写入文件，内容从标准输入传入，不经过 shell
+func writeWithSudo(path string, content []byte) error {
+	cmd := exec.Command("sudo", "tee", "--", path)
+	cmd.Stdin = bytes.NewReader(content)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%w: %s, 错误: %v", ErrInstallationFailed, strings.TrimSpace(stderr.String()), err)
+	}
+	return nil
+}
+
+// copyFile 复制文件内容和权限，先写入临时文件再重命名
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInstallationFailed, err)
+	}
+	defer in.Close()
+
+	info, err := in.Stat()
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInstallationFailed, err)
+	}
+
+	out, err := os.CreateTemp(filepath.Dir(dst), ".composer.phar-*")
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInstallationFailed, err)
+	}
+	tmpPath := out.Name()
+	defer os.Remove(tmpPath)
+
+	_, err = io.Copy(out, in)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInstallationFailed, err)
+	}
+	if err := os.Chmod(tmpPath, info.Mode().Perm()); err != nil {
+		return fmt.Errorf("%w: %v", ErrInstallationFailed, err)
+	}
+	if err := os.Rename(tmpPath, dst); err != nil {
+		return fmt.Errorf("%w: %v", ErrInstallationFailed, err)
+	}
+	return nil
+}
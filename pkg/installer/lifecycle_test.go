@@ -0,0 +1,139 @@
+package installer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readTestFile(t *testing.T, path string) string {
+	t.Helper()
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	return string(content)
+}
+
+func TestInstallerLifecycle(t *testing.T) {
+	server := newPharServer(t, map[string]string{
+		"2.7.7": "phar 2.7.7",
+		"2.8.0": "phar 2.8.0",
+		"2.8.1": "tampered",
+	}, map[string]string{
+		"2.8.1": strings.Repeat("0", 64),
+	})
+
+	root := t.TempDir()
+	config := DefaultUserConfig()
+	config.InstallPath = filepath.Join(root, "bin")
+	config.PharDir = filepath.Join(root, "share")
+	config.PharMirrorURL = server.URL
+	config.Version = "2.7.7"
+
+	inst := NewUserInstaller(config)
+	require.NoError(t, inst.Install())
+
+	pharPath := filepath.Join(config.PharDir, "composer.phar")
+	manifest, err := ReadManifest(pharPath)
+	require.NoError(t, err)
+	digest := sha256.Sum256([]byte("phar 2.7.7"))
+	assert.Equal(t, "2.7.7", manifest.Version)
+	assert.Equal(t, server.URL+"/download/2.7.7/composer.phar", manifest.SourceURL)
+	assert.Equal(t, hex.EncodeToString(digest[:]), manifest.Checksum)
+	assert.False(t, manifest.InstalledAt.IsZero())
+	assert.Nil(t, manifest.Previous)
+
+	// 没有备份时无法回滚
+	assert.ErrorIs(t, inst.Rollback(), ErrNoBackup)
+
+	require.NoError(t, inst.Upgrade("2.8.0"))
+	assert.Equal(t, "phar 2.8.0", readTestFile(t, pharPath))
+	assert.Equal(t, "phar 2.7.7", readTestFile(t, BackupPath(pharPath)))
+	manifest, err = ReadManifest(pharPath)
+	require.NoError(t, err)
+	assert.Equal(t, "2.8.0", manifest.Version)
+	require.NotNil(t, manifest.Previous)
+	assert.Equal(t, "2.7.7", manifest.Previous.Version)
+
+	// 校验失败时当前版本和备份都保持不变
+	assert.ErrorIs(t, inst.Upgrade("2.8.1"), ErrChecksumMismatch)
+	assert.Equal(t, "phar 2.8.0", readTestFile(t, pharPath))
+	assert.Equal(t, "phar 2.7.7", readTestFile(t, BackupPath(pharPath)))
+
+	require.NoError(t, inst.Rollback())
+	assert.Equal(t, "phar 2.7.7", readTestFile(t, pharPath))
+	assert.Equal(t, "phar 2.8.0", readTestFile(t, BackupPath(pharPath)))
+	manifest, err = ReadManifest(pharPath)
+	require.NoError(t, err)
+	assert.Equal(t, "2.7.7", manifest.Version)
+	require.NotNil(t, manifest.Previous)
+	assert.Equal(t, "2.8.0", manifest.Previous.Version)
+	assert.NoFileExists(t, pharPath+".rollback")
+
+	// 再次回滚恢复到回滚前的版本
+	require.NoError(t, inst.Rollback())
+	assert.Equal(t, "phar 2.8.0", readTestFile(t, pharPath))
+
+	require.NoError(t, inst.Uninstall())
+	for _, path := range []string{pharPath, BackupPath(pharPath), ManifestPath(pharPath), filepath.Join(config.InstallPath, executableName())} {
+		assert.NoFileExists(t, path)
+	}
+	assert.NoError(t, inst.Uninstall())
+	assert.ErrorIs(t, inst.Upgrade("2.8.0"), ErrNotInstalled)
+	assert.ErrorIs(t, inst.Rollback(), ErrNoBackup)
+}
+
+func TestUpgradeWithoutManifest(t *testing.T) {
+	server := newPharServer(t, map[string]string{"latest-stable": "phar stable"}, nil)
+
+	config := DefaultConfig()
+	config.InstallPath = t.TempDir()
+	config.PharMirrorURL = server.URL
+	config.UseSudo = false
+
+	// 旧版本安装器安装的 composer.phar 没有安装清单
+	pharPath := filepath.Join(config.InstallPath, "composer.phar")
+	require.NoError(t, os.WriteFile(pharPath, []byte("legacy"), 0755))
+
+	inst := NewLinuxInstaller(config)
+	require.NoError(t, inst.Upgrade(""))
+	manifest, err := ReadManifest(pharPath)
+	require.NoError(t, err)
+	assert.Equal(t, ChannelStable, manifest.Channel)
+	require.NotNil(t, manifest.Previous)
+	digest := sha256.Sum256([]byte("legacy"))
+	assert.Equal(t, hex.EncodeToString(digest[:]), manifest.Previous.Checksum)
+
+	// 备份被修改后拒绝回滚
+	require.NoError(t, os.WriteFile(BackupPath(pharPath), []byte("modified"), 0755))
+	assert.ErrorIs(t, inst.Rollback(), ErrChecksumMismatch)
+	assert.Equal(t, "phar stable", readTestFile(t, pharPath))
+}
+
+func TestWriteWithSudo(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("sudo 只在类 Unix 系统上使用")
+	}
+
+	// 用直接执行参数的脚本代替 sudo
+	binDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(binDir, "sudo"), []byte("#!/bin/sh\nexec \"$@\"\n"), 0755))
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	// 路径和内容中的引号、命令替换都不会被 shell 解释
+	dir := filepath.Join(t.TempDir(), "it's $(id)")
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	target := filepath.Join(dir, "composer")
+	content := "#!/bin/sh\nphp '" + dir + "/composer.phar' \"$@\"\n"
+
+	require.NoError(t, writeWithSudo(target, []byte(content)))
+	assert.Equal(t, content, readTestFile(t, target))
+
+	assert.ErrorIs(t, writeWithSudo(filepath.Join(dir, "missing", "composer"), []byte(content)), ErrInstallationFailed)
+}
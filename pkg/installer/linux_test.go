@@ -68,7 +68,7 @@ func TestLinuxInstaller_Install_WithSudo(t *testing.T) {
 	mockEnv.cmdExecutor.SetCommandResult("sudo", []string{"php", "-", "--install-dir=/usr/local/bin", "--filename=composer.phar"}, []byte("安装成功"), nil)
 
 	// 模拟sudo tee创建文件成功
-	mockEnv.cmdExecutor.SetCommandResult("sh", []string{"-c", "echo '#!/bin/sh...' | sudo tee /usr/local/bin/composer > /dev/null"}, []byte(""), nil)
+	mockEnv.cmdExecutor.SetCommandResult("sudo", []string{"tee", "--", "/usr/local/bin/composer"}, []byte(""), nil)
 
 	// 模拟sudo chmod设置权限成功
 	mockEnv.cmdExecutor.SetCommandResult("sudo", []string{"chmod", "755", "/usr/local/bin/composer"}, []byte(""), nil)
@@ -2,7 +2,6 @@ package installer
 
 import (
 	"fmt"
-	"os"
 	"os/exec"
 	"path/filepath"
 
@@ -34,35 +33,15 @@ func (i *MacOSInstaller) Install() error {
 		return fmt.Errorf("安装目录无法写入: %w", err)
 	}
 
-	// 下载安装脚本
-	scriptPath := filepath.Join(os.TempDir(), "composer-setup.php")
-	downloadConfig := utils.DownloadConfig{
-		UseProxy: i.config.UseProxy,
-		ProxyURL: i.config.ProxyURL,
-	}
-	if err := utils.DownloadFile(i.config.DownloadURL, scriptPath, downloadConfig); err != nil {
-		return err
-	}
-	defer os.Remove(scriptPath)
-
-	// 执行PHP脚本安装Composer
+	// 安装 composer.phar
 	pharPath := filepath.Join(i.config.InstallPath, "composer.phar")
-
-	var cmd *exec.Cmd
-	if i.config.UseSudo {
-		cmd = exec.Command("sudo", "php", scriptPath, "--install-dir="+i.config.InstallPath, "--filename=composer.phar")
-	} else {
-		cmd = exec.Command("php", scriptPath, "--install-dir="+i.config.InstallPath, "--filename=composer.phar")
-	}
-
-	if output, err := cmd.CombinedOutput(); err != nil {
-		return fmt.Errorf("%w: %s, 错误: %v", ErrInstallationFailed, string(output), err)
+	if err := installComposerPhar(i.config, pharPath, i.config.UseSudo); err != nil {
+		return err
 	}
 
 	// 创建可执行Composer脚本
 	binPath := filepath.Join(i.config.InstallPath, "composer")
-	binContent := fmt.Sprintf("#!/bin/sh\nphp \"%s\" \"$@\"", pharPath)
-	if err := utils.CreateFileWithContent(binPath, []byte(binContent), 0755); err != nil {
+	if err := utils.CreateFileWithContent(binPath, []byte(wrapperScript(pharPath)), 0755); err != nil {
 		return fmt.Errorf("创建可执行文件失败: %w", err)
 	}
 
@@ -72,8 +51,8 @@ func (i *MacOSInstaller) Install() error {
 // tryBrewInstall 尝试使用Homebrew安装Composer
 // 返回值: 安装是否成功
 func (i *MacOSInstaller) tryBrewInstall() bool {
-	// 如果配置不允许使用brew，则跳过
-	if !i.config.PreferBrewOnMac {
+	// 如果配置不允许使用brew，则跳过；指定了版本或渠道时Homebrew无法保证安装的版本，离线安装时无法访问Homebrew，同样跳过
+	if !i.config.PreferBrewOnMac || i.config.PinsRelease() || i.config.IsOffline() {
 		return false
 	}
 
@@ -97,3 +76,30 @@ func (i *MacOSInstaller) tryBrewInstall() bool {
 	fmt.Println("已成功通过Homebrew安装Composer")
 	return true
 }
+
+// lifecycle 返回MacOS 安装器的卸载、升级和回滚实现
+func (i *MacOSInstaller) lifecycle() lifecycle {
+	return lifecycle{
+		config:    i.config,
+		pharPath:  filepath.Join(i.config.InstallPath, "composer.phar"),
+		binPath:   filepath.Join(i.config.InstallPath, "composer"),
+		allowSudo: i.config.UseSudo,
+	}
+}
+
+// Uninstall 从 MacOS 上卸载 Composer
+func (i *MacOSInstaller) Uninstall() error {
+	return i.lifecycle().uninstall()
+}
+
+// Upgrade 在 MacOS 上将 Composer 升级到指定版本，并保留当前版本作为备份
+//
+// 通过 Homebrew 安装的 Composer 没有 composer.phar，请使用 brew upgrade composer。
+func (i *MacOSInstaller) Upgrade(targetVersion string) error {
+	return i.lifecycle().upgrade(targetVersion)
+}
+
+// Rollback 在 MacOS 上回滚到升级前的 Composer 版本
+func (i *MacOSInstaller) Rollback() error {
+	return i.lifecycle().rollback()
+}
@@ -0,0 +1,141 @@
+package installer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// IsOffline 判断配置是否使用本地的 composer.phar 离线安装
+//
+// LocalPharPath、PharReader 或 PharFS 任意一个被设置时返回 true，此时安装、升级都不会访问网络。
+func (c Config) IsOffline() bool {
+	return c.LocalPharPath != "" || c.PharReader != nil || c.PharFS != nil
+}
+
+// stagePhar 将 composer.phar 放到 dir 中的临时文件并校验 SHA-256
+//
+// 离线配置从本地来源复制，否则从下载地址下载。返回临时文件路径和对应的安装清单。
+func stagePhar(config Config, dir string) (string, *InstallManifest, error) {
+	if config.IsOffline() {
+		return copyOfflinePhar(config, dir)
+	}
+	return downloadPhar(config, dir)
+}
+
+// copyOfflinePhar 将离线来源的 composer.phar 复制到 dir 中的临时文件并校验 SHA-256
+//
+// 期望的校验和优先使用 config.PharChecksum，未设置时读取来源旁边的 composer.phar.sha256sum，
+// 两者都没有时拒绝安装。出错时不会留下临时文件。
+func copyOfflinePhar(config Config, dir string) (string, *InstallManifest, error) {
+	src, source, err := openOfflinePhar(config)
+	if err != nil {
+		return "", nil, err
+	}
+	defer src.Close()
+
+	expected := strings.ToLower(strings.TrimSpace(config.PharChecksum))
+	if expected == "" {
+		if expected, err = readOfflineChecksum(config); err != nil {
+			return "", nil, err
+		}
+	}
+
+	tmp, err := os.CreateTemp(dir, ".composer.phar-*")
+	if err != nil {
+		return "", nil, fmt.Errorf("%w: 无法创建临时文件: %v", ErrInstallationFailed, err)
+	}
+	tmpPath := tmp.Name()
+
+	h := sha256.New()
+	_, err = io.Copy(io.MultiWriter(tmp, h), src)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return "", nil, fmt.Errorf("%w: 复制 composer.phar 失败: %v", ErrInstallationFailed, err)
+	}
+
+	actual := hex.EncodeToString(h.Sum(nil))
+	if actual != expected {
+		os.Remove(tmpPath)
+		return "", nil, fmt.Errorf("%w: composer.phar 期望 %s，实际 %s", ErrChecksumMismatch, expected, actual)
+	}
+
+	return tmpPath, &InstallManifest{
+		Version:     strings.TrimPrefix(config.Version, "v"),
+		SourceURL:   source,
+		Checksum:    actual,
+		InstalledAt: time.Now().UTC(),
+	}, nil
+}
+
+// openOfflinePhar 打开离线来源的 composer.phar，并返回写入安装清单的来源地址
+func openOfflinePhar(config Config) (io.ReadCloser, string, error) {
+	switch {
+	case config.LocalPharPath != "":
+		f, err := os.Open(config.LocalPharPath)
+		if err != nil {
+			return nil, "", fmt.Errorf("%w: %v", ErrInstallationFailed, err)
+		}
+		source := config.LocalPharPath
+		if abs, err := filepath.Abs(source); err == nil {
+			source = abs
+		}
+		return f, "file://" + filepath.ToSlash(source), nil
+	case config.PharReader != nil:
+		return io.NopCloser(config.PharReader), "", nil
+	default:
+		f, err := config.PharFS.Open(config.pharFSPath())
+		if err != nil {
+			return nil, "", fmt.Errorf("%w: %v", ErrInstallationFailed, err)
+		}
+		return f, "", nil
+	}
+}
+
+// readOfflineChecksum 读取离线来源旁边的 composer.phar.sha256sum，PharReader 没有校验文件
+func readOfflineChecksum(config Config) (string, error) {
+	var data []byte
+	var err error
+	switch {
+	case config.LocalPharPath != "":
+		data, err = os.ReadFile(config.LocalPharPath + ".sha256sum")
+	case config.PharFS != nil:
+		data, err = fs.ReadFile(config.PharFS, config.pharFSPath()+".sha256sum")
+	default:
+		err = fs.ErrNotExist
+	}
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", fmt.Errorf("%w: 离线安装需要设置 PharChecksum 或提供 composer.phar.sha256sum", ErrChecksumMismatch)
+	}
+	if err != nil {
+		return "", fmt.Errorf("%w: 读取 composer.phar 校验和失败: %v", ErrInstallationFailed, err)
+	}
+
+	fields := strings.Fields(string(data))
+	if len(fields) == 0 {
+		return "", fmt.Errorf("%w: composer.phar 校验和为空", ErrChecksumMismatch)
+	}
+	checksum := strings.ToLower(fields[0])
+	if _, err := hex.DecodeString(checksum); err != nil || len(checksum) != sha256.Size*2 {
+		return "", fmt.Errorf("%w: composer.phar 校验和格式无效", ErrChecksumMismatch)
+	}
+	return checksum, nil
+}
+
+// pharFSPath 返回 composer.phar 在 PharFS 中的路径
+func (c Config) pharFSPath() string {
+	if c.PharFSPath == "" {
+		return "composer.phar"
+	}
+	return c.PharFSPath
+}
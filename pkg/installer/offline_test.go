@@ -0,0 +1,103 @@
+package installer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// offlineConfig 返回指向会让测试失败的服务器的配置，用于确认离线安装不访问网络
+func offlineConfig(t *testing.T) Config {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("离线安装不应访问网络: %s", r.URL)
+	}))
+	t.Cleanup(server.Close)
+
+	config := DefaultConfig()
+	config.DownloadURL = server.URL + "/installer"
+	config.SignatureURL = server.URL + "/installer.sig"
+	config.PharMirrorURL = server.URL
+	config.InstallPath = t.TempDir()
+	config.UseSudo = false
+	return config
+}
+
+func sha256Hex(content string) string {
+	digest := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(digest[:])
+}
+
+func TestOfflineInstallFromLocalPath(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "composer.phar")
+	require.NoError(t, os.WriteFile(src, []byte("offline phar"), 0644))
+	require.NoError(t, os.WriteFile(src+".sha256sum", []byte(sha256Hex("offline phar")+"  composer.phar\n"), 0644))
+
+	config := offlineConfig(t)
+	config.LocalPharPath = src
+	config.Version = "2.7.7"
+	assert.True(t, config.IsOffline())
+
+	require.NoError(t, NewLinuxInstaller(config).Install())
+
+	pharPath := filepath.Join(config.InstallPath, "composer.phar")
+	assert.Equal(t, "offline phar", readTestFile(t, pharPath))
+	// 所有安装器创建相同格式的包装脚本
+	assert.Equal(t, wrapperScript(pharPath), readTestFile(t, filepath.Join(config.InstallPath, "composer")))
+
+	manifest, err := ReadManifest(pharPath)
+	require.NoError(t, err)
+	assert.Equal(t, "2.7.7", manifest.Version)
+	assert.Equal(t, "file://"+filepath.ToSlash(src), manifest.SourceURL)
+	assert.Equal(t, sha256Hex("offline phar"), manifest.Checksum)
+}
+
+func TestOfflineInstallFromReader(t *testing.T) {
+	config := offlineConfig(t)
+	config.PharReader = strings.NewReader("reader phar")
+	pharPath := filepath.Join(config.InstallPath, "composer.phar")
+
+	// PharReader 没有校验文件，必须设置 PharChecksum
+	assert.ErrorIs(t, InstallPhar(config, pharPath), ErrChecksumMismatch)
+	assert.NoFileExists(t, pharPath)
+
+	config.PharReader = strings.NewReader("reader phar")
+	config.PharChecksum = sha256Hex("reader phar")
+	require.NoError(t, InstallPhar(config, pharPath))
+	assert.Equal(t, "reader phar", readTestFile(t, pharPath))
+}
+
+func TestOfflineInstallFromFS(t *testing.T) {
+	assets := fstest.MapFS{
+		"assets/composer.phar":             {Data: []byte("embedded phar")},
+		"assets/composer.phar.sha256sum":   {Data: []byte(sha256Hex("embedded phar"))},
+		"tampered/composer.phar":           {Data: []byte("tampered")},
+		"tampered/composer.phar.sha256sum": {Data: []byte(sha256Hex("embedded phar"))},
+	}
+
+	config := offlineConfig(t)
+	config.PharFS = assets
+	config.PharFSPath = "assets/composer.phar"
+	inst := NewLinuxInstaller(config)
+	require.NoError(t, inst.Install())
+
+	pharPath := filepath.Join(config.InstallPath, "composer.phar")
+	assert.Equal(t, "embedded phar", readTestFile(t, pharPath))
+
+	// 离线升级同样不访问网络，校验失败时保持当前版本
+	config.PharFSPath = "tampered/composer.phar"
+	assert.ErrorIs(t, NewLinuxInstaller(config).Upgrade("2.8.0"), ErrChecksumMismatch)
+	assert.Equal(t, "embedded phar", readTestFile(t, pharPath))
+
+	config.PharFSPath = "missing/composer.phar"
+	assert.ErrorIs(t, NewLinuxInstaller(config).Install(), ErrInstallationFailed)
+}
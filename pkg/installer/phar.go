@@ -0,0 +1,389 @@
+package installer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/scagogogo/go-composer-sdk/pkg/utils"
+)
+
+// Composer 发布渠道
+const (
+	// ChannelStable 最新稳定版
+	ChannelStable = "stable"
+	// ChannelPreview 最新预览版，包括 RC 等预发布版本
+	ChannelPreview = "preview"
+	// ChannelSnapshot 主分支的最新快照
+	ChannelSnapshot = "snapshot"
+	// Channel1x 1.x 的最新版本
+	Channel1x = "1.x"
+	// Channel2x 2.x 的最新版本
+	Channel2x = "2.x"
+	// Channel22LTS 2.2 长期支持版本，兼容 PHP 5.3 至 7.1
+	Channel22LTS = "2.2.x"
+)
+
+// DefaultPharMirrorURL 是下载 composer.phar 的默认地址
+const DefaultPharMirrorURL = "https://getcomposer.org"
+
+// ErrInvalidRelease 表示无效的 Composer 版本号或发布渠道
+var ErrInvalidRelease = errors.New("无效的 Composer 版本或发布渠道")
+
+// releaseVersionPattern 匹配 Composer 发布的版本号，例如 2.7.7 或 2.8.0-RC1
+var releaseVersionPattern = regexp.MustCompile(`^\d+\.\d+\.\d+(-(alpha|beta|RC)\d+)?$`)
+
+// channelPaths 是发布渠道及其别名对应的下载目录
+var channelPaths = map[string]string{
+	ChannelStable:   "latest-stable",
+	ChannelPreview:  "latest-preview",
+	ChannelSnapshot: "latest-snapshot",
+	"1":             "latest-1.x",
+	Channel1x:       "latest-1.x",
+	"2":             "latest-2.x",
+	Channel2x:       "latest-2.x",
+	"2.2":           "latest-2.2.x",
+	Channel22LTS:    "latest-2.2.x",
+	"lts":           "latest-2.2.x",
+}
+
+// PinsRelease 判断配置是否指定了版本或发布渠道
+//
+// 指定后安装器直接下载 composer.phar，不再运行 composer-setup.php。
+func (c Config) PinsRelease() bool {
+	return c.Version != "" || c.Channel != ""
+}
+
+// PharDownloadURL 返回配置对应的 composer.phar 下载地址
+//
+// 参数：
+//   - config: 安装器配置，Version 优先于 Channel，两者都为空时使用稳定版渠道
+//
+// 返回值：
+//   - string: composer.phar 的下载地址，对应的校验文件为该地址加上 .sha256sum
+//   - error: 如果版本号或渠道无效，则返回 ErrInvalidRelease
+//
+// 用法示例：
+//
+//	config := installer.DefaultConfig()
+//	config.Version = "2.7.7"
+//	url, _ := installer.PharDownloadURL(config)
+//	// https://getcomposer.org/download/2.7.7/composer.phar
+func PharDownloadURL(config Config) (string, error) {
+	mirror := strings.TrimRight(config.PharMirrorURL, "/")
+	if mirror == "" {
+		mirror = DefaultPharMirrorURL
+	}
+
+	var dir string
+	switch {
+	case config.Version != "":
+		version := strings.TrimPrefix(config.Version, "v")
+		if !releaseVersionPattern.MatchString(version) {
+			return "", fmt.Errorf("%w: 版本号 %s", ErrInvalidRelease, config.Version)
+		}
+		dir = version
+	case config.Channel != "":
+		path, ok := channelPaths[strings.ToLower(strings.TrimSpace(config.Channel))]
+		if !ok {
+			return "", fmt.Errorf("%w: 渠道 %s", ErrInvalidRelease, config.Channel)
+		}
+		dir = path
+	default:
+		dir = channelPaths[ChannelStable]
+	}
+
+	return mirror + "/download/" + dir + "/composer.phar", nil
+}
+
+// versionChannels 是 /versions 中的键对应的发布渠道
+var versionChannels = map[string]string{
+	"stable":   ChannelStable,
+	"preview":  ChannelPreview,
+	"snapshot": ChannelSnapshot,
+	"1":        Channel1x,
+	"2":        Channel2x,
+	"2.2":      Channel22LTS,
+}
+
+// Release 描述一个可以下载的 Composer 发布版本
+type Release struct {
+	// Channel 发布渠道，取值为 Channel* 常量
+	Channel string `json:"channel"`
+	// Version 版本号，例如 2.7.7
+	Version string `json:"version"`
+	// MinPHP 要求的最低 PHP 版本，格式与 PHP_VERSION_ID 相同，例如 70205
+	MinPHP int `json:"min-php"`
+}
+
+// FetchReleases 获取各发布渠道当前的版本
+//
+// 参数：
+//   - config: 安装器配置，使用 PharMirrorURL 和代理设置
+//
+// 返回值：
+//   - []Release: 各渠道列出的全部版本，按渠道名排序，同一渠道内保持原有顺序；snapshot 等没有版本号的条目会被跳过
+//   - error: 下载或解析失败时返回 ErrDownloadFailed
+//
+// 功能说明：
+//
+//	读取镜像的 /versions（官方地址为 https://getcomposer.org/versions），
+//	每个渠道可能列出多个版本，例如 stable 中除最新版本外还有面向旧版 PHP 的 2.2 LTS 版本，
+//	但不包含历史版本。
+//
+// 用法示例：
+//
+//	releases, err := installer.FetchReleases(installer.DefaultConfig())
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	for _, r := range releases {
+//	    fmt.Printf("%s: %s\n", r.Channel, r.Version)
+//	}
+func FetchReleases(config Config) ([]Release, error) {
+	mirror := strings.TrimRight(config.PharMirrorURL, "/")
+	if mirror == "" {
+		mirror = DefaultPharMirrorURL
+	}
+
+	client, err := utils.NewHTTPClient(downloadConfig(config))
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Get(mirror + "/versions")
+	if err != nil {
+		return nil, fmt.Errorf("%w: 获取 Composer 版本列表失败: %v", ErrDownloadFailed, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: 获取 Composer 版本列表失败，服务器返回状态码 %d", ErrDownloadFailed, resp.StatusCode)
+	}
+
+	var channels map[string][]Release
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&channels); err != nil {
+		return nil, fmt.Errorf("%w: 解析 Composer 版本列表失败: %v", ErrDownloadFailed, err)
+	}
+
+	var releases []Release
+	for key, entries := range channels {
+		channel, ok := versionChannels[key]
+		if !ok {
+			continue
+		}
+		for _, r := range entries {
+			if !releaseVersionPattern.MatchString(r.Version) {
+				continue
+			}
+			r.Channel = channel
+			releases = append(releases, r)
+		}
+	}
+	// 同一渠道内保持版本列表中的顺序，第一个条目是该渠道的最新版本
+	sort.SliceStable(releases, func(i, j int) bool {
+		return releases[i].Channel < releases[j].Channel
+	})
+	return releases, nil
+}
+
+// installComposerPhar 将 composer.phar 安装到 pharPath 并写入安装清单
+//
+// 离线配置时复制本地的 composer.phar，指定了版本或渠道时直接下载并校验 composer.phar，
+// 否则下载并运行官方安装脚本。
+func installComposerPhar(config Config, pharPath string, useSudo bool) error {
+	if config.IsOffline() || config.PinsRelease() {
+		return InstallPhar(config, pharPath)
+	}
+
+	// 下载安装脚本并校验签名
+	scriptPath, err := DownloadInstallerScript(config)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(scriptPath)
+
+	// 执行PHP脚本安装Composer
+	args := []string{scriptPath, "--install-dir=" + filepath.Dir(pharPath), "--filename=" + filepath.Base(pharPath)}
+	var cmd *exec.Cmd
+	if useSudo {
+		cmd = exec.Command("sudo", append([]string{"php"}, args...)...)
+	} else {
+		cmd = exec.Command("php", args...)
+	}
+
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%w: %s, 错误: %v", ErrInstallationFailed, string(output), err)
+	}
+
+	// 安装脚本总是安装最新稳定版，版本号未知
+	checksum, err := fileSHA256(pharPath)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInstallationFailed, err)
+	}
+	ops, err := newFileOps(filepath.Dir(pharPath), useSudo)
+	if err != nil {
+		return err
+	}
+	return ops.writeManifest(pharPath, &InstallManifest{
+		SourceURL:   config.DownloadURL,
+		Checksum:    checksum,
+		InstalledAt: time.Now().UTC(),
+	})
+}
+
+// InstallPhar 下载指定版本或渠道的 composer.phar，校验 SHA-256 后原子地放到 pharPath
+//
+// 参数：
+//   - config: 安装器配置，使用 Version、Channel、PharMirrorURL、PharChecksum、UseSudo 和代理设置
+//   - pharPath: composer.phar 的目标路径
+//
+// 返回值：
+//   - error: 版本无效时返回 ErrInvalidRelease，下载失败时返回 ErrDownloadFailed，
+//     校验失败时返回 ErrChecksumMismatch，此时目标文件保持不变
+//
+// 功能说明：
+//
+//	期望的校验和优先使用 config.PharChecksum，未设置时读取下载地址对应的 .sha256sum 文件。
+//	文件先下载到目标目录中的临时文件，校验通过后再重命名，不会留下不完整的 composer.phar；
+//	目标目录不可写且 UseSudo 为 true 时，通过 sudo install 和 sudo mv 放置文件。
+//	安装成功后在 ManifestPath(pharPath) 写入版本、下载地址、校验和和安装时间。
+//	config.IsOffline() 为 true 时改为从 LocalPharPath、PharReader 或 PharFS 复制，不访问网络。
+func InstallPhar(config Config, pharPath string) error {
+	if !config.IsOffline() {
+		if _, err := PharDownloadURL(config); err != nil {
+			return err
+		}
+	}
+
+	dir := filepath.Dir(pharPath)
+	ops, err := newFileOps(dir, config.UseSudo)
+	if err != nil {
+		return err
+	}
+
+	tmpPath, manifest, err := stagePhar(config, ops.stagingDir(dir))
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpPath)
+
+	if err := ops.place(tmpPath, pharPath, 0755); err != nil {
+		return err
+	}
+	return ops.writeManifest(pharPath, manifest)
+}
+
+// downloadPhar 将 composer.phar 下载到 dir 中的临时文件并校验 SHA-256
+//
+// 返回临时文件路径和对应的安装清单，出错时不会留下临时文件。
+func downloadPhar(config Config, dir string) (string, *InstallManifest, error) {
+	pharURL, err := PharDownloadURL(config)
+	if err != nil {
+		return "", nil, err
+	}
+
+	expected := strings.ToLower(strings.TrimSpace(config.PharChecksum))
+	if expected == "" {
+		if expected, err = fetchPharChecksum(config, pharURL+".sha256sum"); err != nil {
+			return "", nil, err
+		}
+	}
+
+	tmp, err := os.CreateTemp(dir, ".composer.phar-*")
+	if err != nil {
+		return "", nil, fmt.Errorf("%w: 无法创建临时文件: %v", ErrDownloadFailed, err)
+	}
+	tmpPath := tmp.Name()
+	tmp.Close()
+
+	if err := utils.DownloadFile(pharURL, tmpPath, downloadConfig(config)); err != nil {
+		os.Remove(tmpPath)
+		return "", nil, fmt.Errorf("%w: %v", ErrDownloadFailed, err)
+	}
+
+	actual, err := fileSHA256(tmpPath)
+	if err != nil {
+		os.Remove(tmpPath)
+		return "", nil, err
+	}
+	if actual != expected {
+		os.Remove(tmpPath)
+		return "", nil, fmt.Errorf("%w: composer.phar 期望 %s，实际 %s", ErrChecksumMismatch, expected, actual)
+	}
+
+	manifest := &InstallManifest{
+		SourceURL:   pharURL,
+		Checksum:    actual,
+		InstalledAt: time.Now().UTC(),
+	}
+	if config.Version != "" {
+		manifest.Version = strings.TrimPrefix(config.Version, "v")
+	} else {
+		manifest.Channel = config.Channel
+		if manifest.Channel == "" {
+			manifest.Channel = ChannelStable
+		}
+	}
+	return tmpPath, manifest, nil
+}
+
+// fetchPharChecksum 读取 .sha256sum 文件中的 SHA-256 值，文件格式为 "<sha256>  composer.phar"
+func fetchPharChecksum(config Config, checksumURL string) (string, error) {
+	client, err := utils.NewHTTPClient(downloadConfig(config))
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := client.Get(checksumURL)
+	if err != nil {
+		return "", fmt.Errorf("%w: 获取 composer.phar 校验和失败: %v", ErrDownloadFailed, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return "", fmt.Errorf("%w: %s 不存在", ErrInvalidRelease, checksumURL)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%w: 获取 composer.phar 校验和失败，服务器返回状态码 %d", ErrDownloadFailed, resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	if err != nil {
+		return "", fmt.Errorf("%w: 读取 composer.phar 校验和失败: %v", ErrDownloadFailed, err)
+	}
+
+	fields := strings.Fields(string(data))
+	if len(fields) == 0 {
+		return "", fmt.Errorf("%w: composer.phar 校验和为空", ErrDownloadFailed)
+	}
+	checksum := strings.ToLower(fields[0])
+	if _, err := hex.DecodeString(checksum); err != nil || len(checksum) != sha256.Size*2 {
+		return "", fmt.Errorf("%w: composer.phar 校验和格式无效", ErrDownloadFailed)
+	}
+	return checksum, nil
+}
+
+// fileSHA256 计算文件的 SHA-256 十六进制值
+func fileSHA256(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,186 @@
+package installer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newPharServer 创建提供 composer.phar 和 .sha256sum 的测试服务器，files 的键为下载目录
+func newPharServer(t *testing.T, files map[string]string, checksums map[string]string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		dir, file, ok := strings.Cut(strings.TrimPrefix(r.URL.Path, "/download/"), "/")
+		content, exists := files[dir]
+		if !ok || !exists {
+			http.NotFound(w, r)
+			return
+		}
+		switch file {
+		case "composer.phar":
+			w.Write([]byte(content))
+		case "composer.phar.sha256sum":
+			sum, pinned := checksums[dir]
+			if !pinned {
+				digest := sha256.Sum256([]byte(content))
+				sum = hex.EncodeToString(digest[:])
+			}
+			w.Write([]byte(sum + "  composer.phar\n"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestPharDownloadURL(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  Config
+		want    string
+		wantErr bool
+	}{
+		{"版本", Config{Version: "2.7.7"}, "https://getcomposer.org/download/2.7.7/composer.phar", false},
+		{"带v前缀的预发布版本", Config{Version: "v2.8.0-RC1"}, "https://getcomposer.org/download/2.8.0-RC1/composer.phar", false},
+		{"版本优先于渠道", Config{Version: "2.7.7", Channel: ChannelPreview}, "https://getcomposer.org/download/2.7.7/composer.phar", false},
+		{"LTS渠道", Config{Channel: "2.2", PharMirrorURL: "https://mirror.example.com/composer/"}, "https://mirror.example.com/composer/download/latest-2.2.x/composer.phar", false},
+		{"快照渠道", Config{Channel: ChannelSnapshot}, "https://getcomposer.org/download/latest-snapshot/composer.phar", false},
+		{"默认稳定版", Config{}, "https://getcomposer.org/download/latest-stable/composer.phar", false},
+		{"无效版本", Config{Version: "latest"}, "", true},
+		{"无效渠道", Config{Channel: "nightly"}, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := PharDownloadURL(tt.config)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidRelease)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+// versionsJSON 是 /versions 的示例内容
+const versionsJSON = `{
+	"stable": [
+		{"path": "/download/2.7.7/composer.phar", "version": "2.7.7", "min-php": 70205},
+		{"path": "/download/2.2.24/composer.phar", "version": "2.2.24", "min-php": 50300}
+	],
+	"preview": [{"path": "/download/2.8.0-RC1/composer.phar", "version": "2.8.0-RC1", "min-php": 70205}],
+	"snapshot": [{"path": "/composer.phar", "version": "0123456789abcdef0123456789abcdef01234567", "min-php": 70205}],
+	"1": [{"path": "/download/1.10.27/composer.phar", "version": "1.10.27", "min-php": 50300}],
+	"2": [{"path": "/download/2.7.7/composer.phar", "version": "2.7.7", "min-php": 70205}],
+	"2.2": [{"path": "/download/2.2.24/composer.phar", "version": "2.2.24", "min-php": 50300}]
+}`
+
+func TestFetchReleases(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/versions" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(versionsJSON))
+	}))
+	defer server.Close()
+
+	releases, err := FetchReleases(Config{PharMirrorURL: server.URL + "/"})
+	require.NoError(t, err)
+	assert.Equal(t, []Release{
+		{Channel: Channel1x, Version: "1.10.27", MinPHP: 50300},
+		{Channel: Channel22LTS, Version: "2.2.24", MinPHP: 50300},
+		{Channel: Channel2x, Version: "2.7.7", MinPHP: 70205},
+		{Channel: ChannelPreview, Version: "2.8.0-RC1", MinPHP: 70205},
+		{Channel: ChannelStable, Version: "2.7.7", MinPHP: 70205},
+		{Channel: ChannelStable, Version: "2.2.24", MinPHP: 50300},
+	}, releases)
+
+	_, err = FetchReleases(Config{PharMirrorURL: server.URL + "/missing"})
+	assert.ErrorIs(t, err, ErrDownloadFailed)
+}
+
+func TestInstallPhar(t *testing.T) {
+	server := newPharServer(t, map[string]string{
+		"2.7.7":        "phar 2.7.7",
+		"latest-2.2.x": "phar 2.2.24",
+		"2.6.0":        "tampered",
+	}, map[string]string{
+		"2.6.0": strings.Repeat("0", 64),
+	})
+
+	dir := t.TempDir()
+	pharPath := filepath.Join(dir, "composer.phar")
+
+	config := DefaultConfig()
+	config.PharMirrorURL = server.URL
+	config.Version = "2.7.7"
+	require.NoError(t, InstallPhar(config, pharPath))
+	content, err := os.ReadFile(pharPath)
+	require.NoError(t, err)
+	assert.Equal(t, "phar 2.7.7", string(content))
+	if runtime.GOOS != "windows" {
+		info, err := os.Stat(pharPath)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0755), info.Mode().Perm())
+	}
+
+	config.Version = ""
+	config.Channel = Channel22LTS
+	require.NoError(t, InstallPhar(config, pharPath))
+	content, _ = os.ReadFile(pharPath)
+	assert.Equal(t, "phar 2.2.24", string(content))
+
+	// 校验失败时保留原来的文件和安装清单，也不留下临时文件
+	config.Channel = ""
+	config.Version = "2.6.0"
+	assert.ErrorIs(t, InstallPhar(config, pharPath), ErrChecksumMismatch)
+	content, _ = os.ReadFile(pharPath)
+	assert.Equal(t, "phar 2.2.24", string(content))
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 2)
+	manifest, err := ReadManifest(pharPath)
+	require.NoError(t, err)
+	assert.Equal(t, Channel22LTS, manifest.Channel)
+
+	// 固定的校验和优先于 .sha256sum
+	digest := sha256.Sum256([]byte("tampered"))
+	config.PharChecksum = hex.EncodeToString(digest[:])
+	assert.NoError(t, InstallPhar(config, pharPath))
+
+	config.PharChecksum = ""
+	config.Version = "9.9.9"
+	assert.ErrorIs(t, InstallPhar(config, pharPath), ErrInvalidRelease)
+}
+
+func TestLinuxInstallerPinnedVersion(t *testing.T) {
+	server := newPharServer(t, map[string]string{"2.7.7": "phar 2.7.7"}, nil)
+
+	config := DefaultConfig()
+	config.PharMirrorURL = server.URL
+	config.Version = "2.7.7"
+	config.InstallPath = t.TempDir()
+	config.UseSudo = false
+
+	require.NoError(t, NewLinuxInstaller(config).Install())
+
+	content, err := os.ReadFile(filepath.Join(config.InstallPath, "composer.phar"))
+	require.NoError(t, err)
+	assert.Equal(t, "phar 2.7.7", string(content))
+
+	wrapper, err := os.ReadFile(filepath.Join(config.InstallPath, "composer"))
+	require.NoError(t, err)
+	assert.Contains(t, string(wrapper), filepath.Join(config.InstallPath, "composer.phar"))
+}
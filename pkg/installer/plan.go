@@ -0,0 +1,412 @@
+package installer
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// ErrPreflightFailed 表示安装前的预检没有通过
+var ErrPreflightFailed = errors.New("安装预检失败")
+
+// 安装方式，见 InstallPlan.Method
+const (
+	// MethodHomebrew 通过 brew install composer 安装
+	MethodHomebrew = "homebrew"
+	// MethodSetupScript 下载并运行官方安装脚本 composer-setup.php
+	MethodSetupScript = "setup-script"
+	// MethodPhar 直接下载指定版本或渠道的 composer.phar
+	MethodPhar = "phar"
+	// MethodOffline 从本地来源复制 composer.phar，不访问网络
+	MethodOffline = "offline"
+)
+
+// PreflightStatus 表示一项预检的结果
+type PreflightStatus string
+
+const (
+	// PreflightOK 检查通过
+	PreflightOK PreflightStatus = "ok"
+	// PreflightWarning 不影响安装，但可能影响之后运行 Composer
+	PreflightWarning PreflightStatus = "warning"
+	// PreflightFailed 安装会失败
+	PreflightFailed PreflightStatus = "failed"
+)
+
+// RequiredExtensions 是运行 Composer 需要的 PHP 扩展
+var RequiredExtensions = []string{"openssl", "phar", "json", "mbstring"}
+
+// PreflightCheck 是一项预检的结果
+type PreflightCheck struct {
+	// Name 检查项名称，例如 platform、install-path、php、php-version、ext-openssl
+	Name string `json:"name"`
+	// Status 检查结果
+	Status PreflightStatus `json:"status"`
+	// Message 检查结果的说明
+	Message string `json:"message"`
+}
+
+// InstallPlan 描述 Install 将要执行的操作以及安装前的预检结果
+type InstallPlan struct {
+	// Platform 操作系统，与 runtime.GOOS 相同
+	Platform string `json:"platform"`
+	// Installer 选择的平台安装器，例如 InstallerLinux、InstallerUser，不支持的平台为空
+	Installer string `json:"installer,omitempty"`
+	// Method 安装方式，例如 MethodSetupScript、MethodPhar
+	Method string `json:"method,omitempty"`
+	// PHPPath PHP 可执行文件的路径，未找到时为空
+	PHPPath string `json:"php_path,omitempty"`
+	// PHPVersion PHP 版本号，例如 8.3.1
+	PHPVersion string `json:"php_version,omitempty"`
+	// Extensions 必需的 PHP 扩展是否已加载
+	Extensions map[string]bool `json:"extensions,omitempty"`
+	// InstallPath 可执行文件的安装目录
+	InstallPath string `json:"install_path,omitempty"`
+	// PharPath composer.phar 的安装路径
+	PharPath string `json:"phar_path,omitempty"`
+	// ExecutablePath 可执行文件的路径
+	ExecutablePath string `json:"executable_path,omitempty"`
+	// Writable 当前用户能否写入安装目录
+	Writable bool `json:"writable"`
+	// UseSudo 安装时是否会使用 sudo
+	UseSudo bool `json:"use_sudo"`
+	// Downloads 安装时会下载的地址
+	Downloads []string `json:"downloads,omitempty"`
+	// Commands 安装时会执行的外部命令
+	Commands []string `json:"commands,omitempty"`
+	// Files 安装时会创建或替换的文件
+	Files []string `json:"files,omitempty"`
+	// Checks 预检结果
+	Checks []PreflightCheck `json:"checks"`
+}
+
+// OK 判断预检是否全部通过，警告不影响结果
+func (p *InstallPlan) OK() bool {
+	return p.Err() == nil
+}
+
+// Err 返回未通过的预检，全部通过时返回 nil
+//
+// 返回的错误包装了 ErrPreflightFailed，错误信息包含所有失败项的说明。
+func (p *InstallPlan) Err() error {
+	var failed []string
+	for _, check := range p.Checks {
+		if check.Status == PreflightFailed {
+			failed = append(failed, check.Name+": "+check.Message)
+		}
+	}
+	if len(failed) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrPreflightFailed, strings.Join(failed, "; "))
+}
+
+func (p *InstallPlan) check(name string, status PreflightStatus, format string, args ...interface{}) {
+	p.Checks = append(p.Checks, PreflightCheck{Name: name, Status: status, Message: fmt.Sprintf(format, args...)})
+}
+
+// Plan 返回 Install 将要执行的操作和预检结果，不会修改系统
+//
+// 返回值：
+//   - *InstallPlan: 选择的平台安装器、安装方式、PHP 及扩展情况、目标路径是否可写、
+//     是否使用 sudo，以及会下载的地址、执行的命令和创建的文件
+//
+// 功能说明：
+//
+//	Plan 只查找可执行文件并运行 php -r 读取版本和扩展，不会创建目录、下载文件或调用 sudo。
+//	预检失败的项目通过 InstallPlan.Checks 报告，InstallPlan.Err() 汇总为一个错误。
+//	只有通过安装脚本安装时才必须有 PHP，其他方式下缺少 PHP 或扩展只作为警告。
+//
+// 用法示例：
+//
+//	plan := installer.DefaultInstaller().Plan()
+//	for _, check := range plan.Checks {
+//	    fmt.Printf("[%s] %s: %s\n", check.Status, check.Name, check.Message)
+//	}
+//	if err := plan.Err(); err != nil {
+//	    log.Fatal(err)
+//	}
+func (i *Installer) Plan() *InstallPlan {
+	return planInstall(i.config, runtime.GOOS)
+}
+
+// planInstall 为指定操作系统生成安装计划
+func planInstall(config Config, goos string) *InstallPlan {
+	plan := &InstallPlan{Platform: goos}
+
+	kind, err := platformKind(config, goos)
+	if err != nil {
+		plan.check("platform", PreflightFailed, "%v", err)
+		return plan
+	}
+	plan.Installer = kind
+	plan.check("platform", PreflightOK, "使用 %s 安装器", kind)
+
+	binDir, pharDir := config.InstallPath, config.InstallPath
+	if kind == InstallerUser {
+		if binDir == "" {
+			binDir = UserBinDir()
+		}
+		pharDir = config.PharDir
+		if pharDir == "" {
+			pharDir = UserDataDir()
+		}
+	}
+	exe := "composer"
+	if goos == "windows" {
+		exe = "composer.bat"
+	}
+	plan.InstallPath = binDir
+	plan.PharPath = filepath.Join(pharDir, "composer.phar")
+	plan.ExecutablePath = filepath.Join(binDir, exe)
+
+	plan.Method = planMethod(config, kind)
+	if plan.Method == MethodHomebrew {
+		plan.Writable = true
+		plan.Commands = []string{"brew install composer"}
+		plan.check("homebrew", PreflightOK, "通过 Homebrew 安装，Homebrew 负责安装 PHP 依赖")
+		planPHP(plan, config, false)
+		return plan
+	}
+
+	// sudo 只在 Linux 和其他类 Unix 系统的安装器中使用
+	sudoCapable := kind == InstallerLinux || kind == InstallerUnix
+	plan.UseSudo = sudoCapable && config.UseSudo
+	plan.Writable = true
+	for _, dir := range []string{binDir, pharDir} {
+		if dir == "" {
+			plan.Writable = false
+			plan.check("install-path", PreflightFailed, "未配置安装目录")
+			break
+		}
+		if !isWritable(dir) {
+			plan.Writable = false
+			if plan.UseSudo {
+				plan.check("install-path", PreflightWarning, "%s 不可写，将使用 sudo", dir)
+			} else {
+				plan.check("install-path", PreflightFailed, "%v, 目标路径: %s", ErrInsufficientRights, dir)
+			}
+			break
+		}
+	}
+	if plan.Writable {
+		plan.check("install-path", PreflightOK, "%s 可写", binDir)
+	}
+
+	switch plan.Method {
+	case MethodOffline:
+		planOffline(plan, config)
+	case MethodPhar:
+		planPhar(plan, config)
+	default:
+		planSetupScript(plan, config, pharDir)
+	}
+	plan.Files = append(plan.Files, plan.PharPath, ManifestPath(plan.PharPath), plan.ExecutablePath)
+	if plan.UseSudo {
+		plan.Commands = append(plan.Commands,
+			"sudo tee "+plan.ExecutablePath,
+			"sudo chmod 755 "+plan.ExecutablePath)
+	}
+
+	planPHP(plan, config, plan.Method == MethodSetupScript)
+	return plan
+}
+
+// planMethod 返回安装器实际会使用的安装方式
+func planMethod(config Config, kind string) string {
+	switch {
+	case config.IsOffline():
+		return MethodOffline
+	case config.PinsRelease():
+		return MethodPhar
+	case kind == InstallerMacOS && config.PreferBrewOnMac:
+		if _, err := exec.LookPath("brew"); err == nil {
+			return MethodHomebrew
+		}
+	}
+	return MethodSetupScript
+}
+
+// planSetupScript 记录通过安装脚本安装时的下载和命令
+func planSetupScript(plan *InstallPlan, config Config, pharDir string) {
+	plan.Downloads = append(plan.Downloads, config.DownloadURL)
+	switch {
+	case config.InstallerChecksum != "":
+		plan.check("signature", PreflightOK, "使用固定的安装脚本校验和")
+	case config.SignatureURL != "":
+		plan.Downloads = append(plan.Downloads, config.SignatureURL)
+		plan.check("signature", PreflightOK, "从 %s 获取安装脚本签名", config.SignatureURL)
+	default:
+		plan.check("signature", PreflightFailed, "未配置安装脚本签名地址或校验和")
+	}
+
+	command := fmt.Sprintf("php %s --install-dir=%s --filename=composer.phar",
+		filepath.Join(os.TempDir(), "composer-setup-*.php"), pharDir)
+	if plan.UseSudo {
+		command = "sudo " + command
+	}
+	plan.Commands = append(plan.Commands, command)
+}
+
+// planPhar 记录直接下载 composer.phar 时的下载和命令
+func planPhar(plan *InstallPlan, config Config) {
+	pharURL, err := PharDownloadURL(config)
+	if err != nil {
+		plan.check("release", PreflightFailed, "%v", err)
+		return
+	}
+	plan.check("release", PreflightOK, "下载 %s", pharURL)
+	plan.Downloads = append(plan.Downloads, pharURL)
+	if config.PharChecksum == "" {
+		plan.Downloads = append(plan.Downloads, pharURL+".sha256sum")
+	}
+	planSudoPlace(plan)
+}
+
+// planOffline 检查离线来源和校验和是否可用
+func planOffline(plan *InstallPlan, config Config) {
+	var statErr error
+	var sidecar bool
+	switch {
+	case config.LocalPharPath != "":
+		_, statErr = os.Stat(config.LocalPharPath)
+		_, err := os.Stat(config.LocalPharPath + ".sha256sum")
+		sidecar = err == nil
+	case config.PharReader != nil:
+		// PharReader 只能读取一次，不在预检中读取
+	default:
+		_, statErr = fs.Stat(config.PharFS, config.pharFSPath())
+		_, err := fs.Stat(config.PharFS, config.pharFSPath()+".sha256sum")
+		sidecar = err == nil
+	}
+
+	if statErr != nil {
+		plan.check("offline-source", PreflightFailed, "%v", statErr)
+	} else {
+		plan.check("offline-source", PreflightOK, "使用本地的 composer.phar")
+	}
+	if config.PharChecksum == "" && !sidecar {
+		plan.check("checksum", PreflightFailed, "离线安装需要设置 PharChecksum 或提供 composer.phar.sha256sum")
+	}
+	planSudoPlace(plan)
+}
+
+// planSudoPlace 记录目录不可写时通过 sudo 放置 composer.phar 的命令
+func planSudoPlace(plan *InstallPlan) {
+	if plan.UseSudo && !plan.Writable {
+		plan.Commands = append(plan.Commands,
+			fmt.Sprintf("sudo install -m 755 <临时文件> %s.new", plan.PharPath),
+			fmt.Sprintf("sudo mv -f %s.new %s", plan.PharPath, plan.PharPath))
+	}
+}
+
+// planPHP 检查 PHP 及必需的扩展，required 为 false 时问题只作为警告
+func planPHP(plan *InstallPlan, config Config, required bool) {
+	failStatus := PreflightWarning
+	if required {
+		failStatus = PreflightFailed
+	}
+
+	path, err := exec.LookPath("php")
+	if err != nil {
+		plan.check("php", failStatus, "未找到 PHP，运行 Composer 需要 PHP")
+		return
+	}
+	plan.PHPPath = path
+
+	output, err := exec.Command(path, "-r", `echo PHP_VERSION, PHP_EOL, implode(",", get_loaded_extensions());`).Output()
+	if err != nil {
+		plan.check("php", failStatus, "无法运行 %s: %v", path, err)
+		return
+	}
+	lines := strings.SplitN(strings.TrimSpace(string(output)), "\n", 2)
+	plan.PHPVersion = strings.TrimSpace(lines[0])
+	plan.check("php", PreflightOK, "PHP %s (%s)", plan.PHPVersion, path)
+
+	minimum := "7.2.5"
+	if supportsLegacyPHP(config) {
+		minimum = "5.3.2"
+	}
+	if compareVersions(plan.PHPVersion, minimum) < 0 {
+		plan.check("php-version", failStatus, "PHP %s 低于 Composer 要求的 %s，可以使用 %s 渠道", plan.PHPVersion, minimum, Channel22LTS)
+	} else {
+		plan.check("php-version", PreflightOK, "PHP %s 满足 Composer 要求的 %s", plan.PHPVersion, minimum)
+	}
+
+	loaded := map[string]bool{}
+	if len(lines) > 1 {
+		for _, ext := range strings.Split(lines[1], ",") {
+			loaded[strings.ToLower(strings.TrimSpace(ext))] = true
+		}
+	}
+	plan.Extensions = map[string]bool{}
+	for _, ext := range RequiredExtensions {
+		plan.Extensions[ext] = loaded[ext]
+		if loaded[ext] {
+			plan.check("ext-"+ext, PreflightOK, "已加载")
+		} else {
+			plan.check("ext-"+ext, failStatus, "未加载 PHP 扩展 %s", ext)
+		}
+	}
+}
+
+// supportsLegacyPHP 判断配置安装的 Composer 是否支持 PHP 7.2.5 以下的版本
+func supportsLegacyPHP(config Config) bool {
+	if config.Version != "" {
+		version := strings.TrimPrefix(config.Version, "v")
+		return strings.HasPrefix(version, "1.") || strings.HasPrefix(version, "2.2.")
+	}
+	path := channelPaths[strings.ToLower(strings.TrimSpace(config.Channel))]
+	return path == "latest-1.x" || path == "latest-2.2.x"
+}
+
+// compareVersions 比较形如 8.3.1 的版本号，忽略 -dev 等后缀
+func compareVersions(a, b string) int {
+	pa, pb := versionParts(a), versionParts(b)
+	for i := range pa {
+		if pa[i] != pb[i] {
+			if pa[i] < pb[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+func versionParts(version string) [3]int {
+	var parts [3]int
+	for i, field := range strings.SplitN(version, ".", 3) {
+		end := strings.IndexFunc(field, func(r rune) bool { return r < '0' || r > '9' })
+		if end >= 0 {
+			field = field[:end]
+		}
+		parts[i], _ = strconv.Atoi(field)
+	}
+	return parts
+}
+
+// nearestExistingDir 返回 dir 或其最近的已存在的上级目录，路径上存在普通文件时返回 false
+func nearestExistingDir(dir string) (string, bool) {
+	dir = filepath.Clean(dir)
+	for {
+		info, err := os.Stat(dir)
+		if err == nil {
+			return dir, info.IsDir()
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return "", false
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
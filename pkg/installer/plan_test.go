@@ -0,0 +1,149 @@
+package installer
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakePHP 在 PATH 中放置一个输出指定版本和扩展的 php 脚本
+func fakePHP(t *testing.T, version, extensions string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("模拟的 php 脚本只在类 Unix 系统上运行")
+	}
+	dir := t.TempDir()
+	script := "#!/bin/sh\necho " + version + "\necho \"" + extensions + "\"\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "php"), []byte(script), 0755))
+	t.Setenv("PATH", dir)
+}
+
+func findCheck(plan *InstallPlan, name string) PreflightCheck {
+	for _, check := range plan.Checks {
+		if check.Name == name {
+			return check
+		}
+	}
+	return PreflightCheck{}
+}
+
+func TestPlanPinnedRelease(t *testing.T) {
+	fakePHP(t, "8.3.1", "Core,json,openssl,Phar,mbstring")
+
+	config := DefaultConfig()
+	config.Version = "2.7.7"
+	config.InstallPath = filepath.Join(t.TempDir(), "not", "created")
+
+	plan := planInstall(config, "linux")
+	require.NoError(t, plan.Err())
+	assert.True(t, plan.OK())
+	assert.Equal(t, InstallerLinux, plan.Installer)
+	assert.Equal(t, MethodPhar, plan.Method)
+	assert.Equal(t, "8.3.1", plan.PHPVersion)
+	assert.Equal(t, map[string]bool{"openssl": true, "phar": true, "json": true, "mbstring": true}, plan.Extensions)
+	assert.True(t, plan.Writable)
+	assert.False(t, plan.UseSudo)
+	assert.Empty(t, plan.Commands)
+	assert.Equal(t, []string{
+		"https://getcomposer.org/download/2.7.7/composer.phar",
+		"https://getcomposer.org/download/2.7.7/composer.phar.sha256sum",
+	}, plan.Downloads)
+	pharPath := filepath.Join(config.InstallPath, "composer.phar")
+	assert.Equal(t, []string{pharPath, ManifestPath(pharPath), filepath.Join(config.InstallPath, "composer")}, plan.Files)
+
+	// 预检不会创建安装目录
+	assert.NoDirExists(t, config.InstallPath)
+}
+
+func TestPlanUnwritableTarget(t *testing.T) {
+	fakePHP(t, "8.3.1", "json,openssl,Phar,mbstring")
+
+	// 路径中包含普通文件，目录无法创建
+	file := filepath.Join(t.TempDir(), "file")
+	require.NoError(t, os.WriteFile(file, nil, 0644))
+
+	config := DefaultConfig()
+	config.InstallPath = filepath.Join(file, "bin")
+	config.UseSudo = false
+
+	plan := planInstall(config, "linux")
+	assert.ErrorIs(t, plan.Err(), ErrPreflightFailed)
+	assert.False(t, plan.Writable)
+	assert.Equal(t, PreflightFailed, findCheck(plan, "install-path").Status)
+	assert.Equal(t, MethodSetupScript, plan.Method)
+
+	config.UseSudo = true
+	plan = planInstall(config, "linux")
+	require.NoError(t, plan.Err())
+	assert.True(t, plan.UseSudo)
+	assert.Equal(t, PreflightWarning, findCheck(plan, "install-path").Status)
+	require.NotEmpty(t, plan.Commands)
+	assert.True(t, strings.HasPrefix(plan.Commands[0], "sudo php "))
+
+	// Windows 安装器不会使用 sudo
+	plan = planInstall(config, "windows")
+	assert.False(t, plan.UseSudo)
+	assert.Equal(t, PreflightFailed, findCheck(plan, "install-path").Status)
+}
+
+func TestPlanPHPChecks(t *testing.T) {
+	fakePHP(t, "7.1.33", "json,openssl,Phar")
+
+	config := DefaultConfig()
+	config.InstallPath = t.TempDir()
+
+	// 安装脚本需要 PHP，缺少扩展或版本过低时预检失败
+	plan := planInstall(config, "linux")
+	assert.ErrorIs(t, plan.Err(), ErrPreflightFailed)
+	assert.Equal(t, PreflightFailed, findCheck(plan, "php-version").Status)
+	assert.Equal(t, PreflightFailed, findCheck(plan, "ext-mbstring").Status)
+	assert.False(t, plan.Extensions["mbstring"])
+
+	// 2.2 LTS 支持旧版本 PHP，直接下载 composer.phar 时缺少扩展只是警告
+	config.Channel = Channel22LTS
+	plan = planInstall(config, "linux")
+	require.NoError(t, plan.Err())
+	assert.Equal(t, PreflightOK, findCheck(plan, "php-version").Status)
+	assert.Equal(t, PreflightWarning, findCheck(plan, "ext-mbstring").Status)
+
+	t.Setenv("PATH", t.TempDir())
+	config.Channel = ""
+	plan = planInstall(config, "linux")
+	assert.Equal(t, PreflightFailed, findCheck(plan, "php").Status)
+	assert.Empty(t, plan.PHPPath)
+}
+
+func TestPlanUnsupportedPlatform(t *testing.T) {
+	plan := planInstall(DefaultConfig(), "plan9")
+	assert.ErrorIs(t, plan.Err(), ErrPreflightFailed)
+	assert.Empty(t, plan.Installer)
+	assert.Equal(t, PreflightFailed, findCheck(plan, "platform").Status)
+}
+
+func TestPlanOffline(t *testing.T) {
+	fakePHP(t, "8.3.1", "json,openssl,Phar,mbstring")
+
+	src := filepath.Join(t.TempDir(), "composer.phar")
+	require.NoError(t, os.WriteFile(src, []byte("phar"), 0644))
+
+	config := DefaultUserConfig()
+	config.InstallPath = t.TempDir()
+	config.PharDir = t.TempDir()
+	config.LocalPharPath = src
+
+	plan := planInstall(config, "linux")
+	assert.Equal(t, InstallerUser, plan.Installer)
+	assert.Equal(t, MethodOffline, plan.Method)
+	assert.Empty(t, plan.Downloads)
+	assert.Equal(t, PreflightFailed, findCheck(plan, "checksum").Status)
+
+	config.PharChecksum = sha256Hex("phar")
+	plan = planInstall(config, "linux")
+	require.NoError(t, plan.Err())
+	assert.Equal(t, filepath.Join(config.PharDir, "composer.phar"), plan.PharPath)
+}
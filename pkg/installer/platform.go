@@ -7,22 +7,74 @@ import (
 
 // PlatformInstaller 定义平台特定的安装逻辑
 type PlatformInstaller interface {
+	// Install 安装Composer
 	Install() error
+	// Uninstall 删除可执行文件、composer.phar、备份和安装清单，未安装时不返回错误
+	Uninstall() error
+	// Upgrade 下载并校验 targetVersion，将当前的 composer.phar 保留为备份后原子地替换；
+	// targetVersion 为空时使用配置的渠道，未配置渠道时使用最新稳定版
+	Upgrade(targetVersion string) error
+	// Rollback 将 composer.phar 与升级前的备份交换，没有备份时返回 ErrNoBackup
+	Rollback() error
 }
 
+// 平台安装器的名称，见 InstallPlan.Installer
+const (
+	InstallerLinux   = "linux"
+	InstallerMacOS   = "macos"
+	InstallerWindows = "windows"
+	InstallerUnix    = "unix"
+	InstallerUser    = "user"
+)
+
 // GetPlatformInstaller 根据当前操作系统返回适合的安装器
+//
+// 配置的安装范围为 ScopeUser 时，在所有平台上都返回 UserInstaller。
 func GetPlatformInstaller(config Config) (PlatformInstaller, error) {
-	switch runtime.GOOS {
-	case "windows":
+	kind, err := platformKind(config, runtime.GOOS)
+	if err != nil {
+		return nil, err
+	}
+
+	switch kind {
+	case InstallerUser:
+		return NewUserInstaller(config), nil
+	case InstallerWindows:
 		return NewWindowsInstaller(config), nil
-	case "darwin":
+	case InstallerMacOS:
 		return NewMacOSInstaller(config), nil
-	case "linux":
+	case InstallerLinux:
 		return NewLinuxInstaller(config), nil
+	default:
+		return NewUnixInstaller(config), nil
+	}
+}
+
+// platformKind 返回配置和操作系统对应的安装器名称
+func platformKind(config Config, goos string) (string, error) {
+	if config.Scope == ScopeUser {
+		return InstallerUser, nil
+	}
+
+	switch goos {
+	case "windows":
+		return InstallerWindows, nil
+	case "darwin":
+		return InstallerMacOS, nil
+	case "linux":
+		return InstallerLinux, nil
 	case "freebsd", "openbsd", "netbsd", "dragonfly":
 		// 其他类Unix系统使用通用Unix安装器
-		return NewUnixInstaller(config), nil
+		return InstallerUnix, nil
 	default:
-		return nil, fmt.Errorf("%w: %s", ErrUnsupportedPlatform, runtime.GOOS)
+		return "", fmt.Errorf("%w: %s", ErrUnsupportedPlatform, goos)
+	}
+}
+
+// wrapperScript 返回调用 composer.phar 的包装脚本内容，所有安装器使用相同的格式
+func wrapperScript(pharPath string) string {
+	if runtime.GOOS == "windows" {
+		return fmt.Sprintf("@php \"%s\" %%*", pharPath)
 	}
+	return fmt.Sprintf("#!/bin/sh\nexec php \"%s\" \"$@\"\n", pharPath)
 }
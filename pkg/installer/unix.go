@@ -2,7 +2,6 @@ package installer
 
 import (
 	"fmt"
-	"os"
 	"os/exec"
 	"path/filepath"
 
@@ -30,40 +29,20 @@ func (i *UnixInstaller) Install() error {
 		}
 	}
 
-	// 下载安装脚本
-	scriptPath := filepath.Join(os.TempDir(), "composer-setup.php")
-	downloadConfig := utils.DownloadConfig{
-		UseProxy: i.config.UseProxy,
-		ProxyURL: i.config.ProxyURL,
-	}
-	if err := utils.DownloadFile(i.config.DownloadURL, scriptPath, downloadConfig); err != nil {
-		return err
-	}
-	defer os.Remove(scriptPath)
-
-	// 执行PHP脚本安装Composer
+	// 安装 composer.phar
 	pharPath := filepath.Join(i.config.InstallPath, "composer.phar")
-
-	var cmd *exec.Cmd
-	if i.config.UseSudo {
-		cmd = exec.Command("sudo", "php", scriptPath, "--install-dir="+i.config.InstallPath, "--filename=composer.phar")
-	} else {
-		cmd = exec.Command("php", scriptPath, "--install-dir="+i.config.InstallPath, "--filename=composer.phar")
-	}
-
-	if output, err := cmd.CombinedOutput(); err != nil {
-		return fmt.Errorf("%w: %s, 错误: %v", ErrInstallationFailed, string(output), err)
+	if err := installComposerPhar(i.config, pharPath, i.config.UseSudo); err != nil {
+		return err
 	}
 
 	// 创建可执行Composer脚本
 	binPath := filepath.Join(i.config.InstallPath, "composer")
-	binContent := fmt.Sprintf("#!/bin/sh\nphp \"%s\" \"$@\"", pharPath)
+	binContent := wrapperScript(pharPath)
 
 	var err error
 	if i.config.UseSudo {
-		// 使用echo和sudo tee创建文件
-		cmd := exec.Command("sh", "-c", fmt.Sprintf("echo '%s' | sudo tee %s > /dev/null", binContent, binPath))
-		if err = cmd.Run(); err != nil {
+		// 通过sudo tee创建文件，内容经标准输入传入，不经过shell解释
+		if err = writeWithSudo(binPath, []byte(binContent)); err != nil {
 			return fmt.Errorf("使用sudo创建可执行文件失败: %w", err)
 		}
 
@@ -81,3 +60,28 @@ func (i *UnixInstaller) Install() error {
 
 	return nil
 }
+
+// lifecycle 返回通用 Unix 安装器的卸载、升级和回滚实现
+func (i *UnixInstaller) lifecycle() lifecycle {
+	return lifecycle{
+		config:    i.config,
+		pharPath:  filepath.Join(i.config.InstallPath, "composer.phar"),
+		binPath:   filepath.Join(i.config.InstallPath, "composer"),
+		allowSudo: i.config.UseSudo,
+	}
+}
+
+// Uninstall 从类 Unix 系统上卸载 Composer
+func (i *UnixInstaller) Uninstall() error {
+	return i.lifecycle().uninstall()
+}
+
+// Upgrade 在类 Unix 系统上将 Composer 升级到指定版本，并保留当前版本作为备份
+func (i *UnixInstaller) Upgrade(targetVersion string) error {
+	return i.lifecycle().upgrade(targetVersion)
+}
+
+// Rollback 在类 Unix 系统上回滚到升级前的 Composer 版本
+func (i *UnixInstaller) Rollback() error {
+	return i.lifecycle().rollback()
+}
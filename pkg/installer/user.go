@@ -0,0 +1,220 @@
+package installer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/scagogogo/go-composer-sdk/pkg/utils"
+)
+
+// 安装范围
+const (
+	// ScopeSystem 安装到系统目录，例如 /usr/local/bin，通常需要 root 或 sudo 权限
+	ScopeSystem = "system"
+	// ScopeUser 安装到当前用户的目录，不需要 root 或 sudo 权限
+	ScopeUser = "user"
+)
+
+// UserInstallResult 表示用户范围安装的结果
+type UserInstallResult struct {
+	// PharPath composer.phar 的路径
+	PharPath string
+	// ExecutablePath 可执行包装脚本的路径，Windows 上为 composer.bat
+	ExecutablePath string
+	// BinDir 包装脚本所在目录
+	BinDir string
+	// OnPath BinDir 是否在 PATH 环境变量中
+	OnPath bool
+}
+
+// UserInstaller 是不需要 root 或 sudo 权限的用户范围安装器
+//
+// composer.phar 安装在 UserDataDir() 中，包装脚本安装在 UserBinDir() 中，
+// 遵循 XDG 基础目录规范；Windows 上两者都位于 %LOCALAPPDATA%\Composer。
+type UserInstaller struct {
+	config Config
+}
+
+// NewUserInstaller 创建用户范围的安装器
+func NewUserInstaller(config Config) *UserInstaller {
+	return &UserInstaller{
+		config: config,
+	}
+}
+
+// DefaultUserConfig 返回用户范围安装的默认配置
+//
+// 用法示例：
+//
+//	config := installer.DefaultUserConfig()
+//	config.Version = "2.7.7"
+//	result, err := installer.NewUserInstaller(config).InstallWithResult()
+//	if err == nil && !result.OnPath {
+//	    fmt.Printf("请将 %s 添加到 PATH\n", result.BinDir)
+//	}
+func DefaultUserConfig() Config {
+	config := DefaultConfig()
+	config.Scope = ScopeUser
+	config.InstallPath = UserBinDir()
+	config.PharDir = UserDataDir()
+	config.UseSudo = false
+	config.PreferBrewOnMac = false
+	return config
+}
+
+// UserBinDir 返回用户范围的可执行文件目录
+//
+// 依次使用 $XDG_BIN_HOME 和 ~/.local/bin；Windows 上为 %LOCALAPPDATA%\Composer。
+// 无法确定用户主目录时返回空字符串。
+func UserBinDir() string {
+	if runtime.GOOS == "windows" {
+		return windowsUserDir()
+	}
+	if dir := os.Getenv("XDG_BIN_HOME"); filepath.IsAbs(dir) {
+		return dir
+	}
+	home, err := os.UserHomeDir()
+	if err != nil || home == "" {
+		return ""
+	}
+	return filepath.Join(home, ".local", "bin")
+}
+
+// UserDataDir 返回用户范围的 composer.phar 存放目录
+//
+// 依次使用 $XDG_DATA_HOME/composer 和 ~/.local/share/composer；Windows 上为 %LOCALAPPDATA%\Composer。
+// 无法确定用户主目录时返回空字符串。
+func UserDataDir() string {
+	if runtime.GOOS == "windows" {
+		return windowsUserDir()
+	}
+	if dir := os.Getenv("XDG_DATA_HOME"); filepath.IsAbs(dir) {
+		return filepath.Join(dir, "composer")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil || home == "" {
+		return ""
+	}
+	return filepath.Join(home, ".local", "share", "composer")
+}
+
+// windowsUserDir 返回 Windows 上用户范围的安装目录
+func windowsUserDir() string {
+	if dir := os.Getenv("LOCALAPPDATA"); dir != "" {
+		return filepath.Join(dir, "Composer")
+	}
+	return ""
+}
+
+// IsOnPath 判断目录是否在 PATH 环境变量中
+func IsOnPath(dir string) bool {
+	if dir == "" {
+		return false
+	}
+	target := filepath.Clean(dir)
+	for _, entry := range filepath.SplitList(os.Getenv("PATH")) {
+		if entry == "" {
+			continue
+		}
+		entry = filepath.Clean(entry)
+		if entry == target || (runtime.GOOS == "windows" && strings.EqualFold(entry, target)) {
+			return true
+		}
+	}
+	return false
+}
+
+// executableName 返回包装脚本的文件名
+func executableName() string {
+	if runtime.GOOS == "windows" {
+		return "composer.bat"
+	}
+	return "composer"
+}
+
+// Install 在用户目录中安装 Composer
+func (i *UserInstaller) Install() error {
+	_, err := i.InstallWithResult()
+	return err
+}
+
+// InstallWithResult 在用户目录中安装 Composer 并返回安装结果
+//
+// 返回值：
+//   - *UserInstallResult: 安装结果，包括包装脚本路径以及所在目录是否在 PATH 中
+//   - error: 如果无法确定用户目录、目录不可写或安装失败，则返回相应的错误信息
+//
+// 功能说明：
+//
+//	InstallPath 和 PharDir 为空时分别使用 UserBinDir() 和 UserDataDir()。
+//	安装过程不会使用 sudo，配置指定了版本或渠道时不需要 PHP。
+func (i *UserInstaller) InstallWithResult() (*UserInstallResult, error) {
+	config := i.config
+	config.UseSudo = false
+	if config.InstallPath == "" {
+		config.InstallPath = UserBinDir()
+	}
+	if config.PharDir == "" {
+		config.PharDir = UserDataDir()
+	}
+	if config.InstallPath == "" || config.PharDir == "" {
+		return nil, fmt.Errorf("%w: 无法确定用户安装目录", ErrInstallationFailed)
+	}
+
+	for _, dir := range []string{config.PharDir, config.InstallPath} {
+		if err := utils.CheckWritePermission(dir); err != nil {
+			return nil, fmt.Errorf("%w, 目标路径: %s", ErrInsufficientRights, dir)
+		}
+	}
+
+	pharPath := filepath.Join(config.PharDir, "composer.phar")
+	if err := installComposerPhar(config, pharPath, false); err != nil {
+		return nil, err
+	}
+
+	binPath := filepath.Join(config.InstallPath, executableName())
+	if err := utils.CreateFileWithContent(binPath, []byte(wrapperScript(pharPath)), 0755); err != nil {
+		return nil, fmt.Errorf("创建可执行文件失败: %w", err)
+	}
+
+	return &UserInstallResult{
+		PharPath:       pharPath,
+		ExecutablePath: binPath,
+		BinDir:         config.InstallPath,
+		OnPath:         IsOnPath(config.InstallPath),
+	}, nil
+}
+
+// lifecycle 返回用户范围安装器的卸载、升级和回滚实现
+func (i *UserInstaller) lifecycle() lifecycle {
+	binDir, pharDir := i.config.InstallPath, i.config.PharDir
+	if binDir == "" {
+		binDir = UserBinDir()
+	}
+	if pharDir == "" {
+		pharDir = UserDataDir()
+	}
+	return lifecycle{
+		config:   i.config,
+		pharPath: filepath.Join(pharDir, "composer.phar"),
+		binPath:  filepath.Join(binDir, executableName()),
+	}
+}
+
+// Uninstall 从用户目录中卸载 Composer
+func (i *UserInstaller) Uninstall() error {
+	return i.lifecycle().uninstall()
+}
+
+// Upgrade 将用户目录中的 Composer 升级到指定版本，并保留当前版本作为备份
+func (i *UserInstaller) Upgrade(targetVersion string) error {
+	return i.lifecycle().upgrade(targetVersion)
+}
+
+// Rollback 将用户目录中的 Composer 回滚到升级前的版本
+func (i *UserInstaller) Rollback() error {
+	return i.lifecycle().rollback()
+}
@@ -0,0 +1,88 @@
+package installer
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserDirs(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Windows 使用 %LOCALAPPDATA%")
+	}
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_BIN_HOME", "")
+	t.Setenv("XDG_DATA_HOME", "")
+	assert.Equal(t, filepath.Join(home, ".local", "bin"), UserBinDir())
+	assert.Equal(t, filepath.Join(home, ".local", "share", "composer"), UserDataDir())
+
+	// 相对路径不符合 XDG 规范，会被忽略
+	t.Setenv("XDG_DATA_HOME", "relative")
+	assert.Equal(t, filepath.Join(home, ".local", "share", "composer"), UserDataDir())
+
+	t.Setenv("XDG_BIN_HOME", "/opt/user/bin")
+	t.Setenv("XDG_DATA_HOME", "/opt/user/share")
+	assert.Equal(t, "/opt/user/bin", UserBinDir())
+	assert.Equal(t, "/opt/user/share/composer", UserDataDir())
+
+	config := DefaultUserConfig()
+	assert.Equal(t, ScopeUser, config.Scope)
+	assert.Equal(t, "/opt/user/bin", config.InstallPath)
+	assert.Equal(t, "/opt/user/share/composer", config.PharDir)
+	assert.False(t, config.UseSudo)
+	assert.Equal(t, "/opt/user/bin/composer", NewInstaller(config).ExecutablePath())
+
+	platformInstaller, err := GetPlatformInstaller(config)
+	require.NoError(t, err)
+	assert.IsType(t, &UserInstaller{}, platformInstaller)
+}
+
+func TestIsOnPath(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("PATH", "/usr/bin"+string(os.PathListSeparator)+dir+string(filepath.Separator))
+	assert.True(t, IsOnPath(dir))
+	assert.False(t, IsOnPath(filepath.Join(dir, "bin")))
+	assert.False(t, IsOnPath(""))
+}
+
+func TestUserInstaller(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("包装脚本测试只在类 Unix 系统上运行")
+	}
+
+	server := newPharServer(t, map[string]string{"2.7.7": "phar 2.7.7"}, nil)
+	root := t.TempDir()
+	t.Setenv("XDG_BIN_HOME", filepath.Join(root, "bin"))
+	t.Setenv("XDG_DATA_HOME", filepath.Join(root, "share"))
+	t.Setenv("PATH", "/usr/bin")
+
+	config := DefaultUserConfig()
+	config.PharMirrorURL = server.URL
+	config.Version = "2.7.7"
+	config.UseSudo = true
+
+	result, err := NewUserInstaller(config).InstallWithResult()
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(root, "share", "composer", "composer.phar"), result.PharPath)
+	assert.Equal(t, filepath.Join(root, "bin", "composer"), result.ExecutablePath)
+	assert.False(t, result.OnPath)
+
+	wrapper, err := os.ReadFile(result.ExecutablePath)
+	require.NoError(t, err)
+	assert.Equal(t, "#!/bin/sh\nexec php \""+result.PharPath+"\" \"$@\"\n", string(wrapper))
+	info, err := os.Stat(result.ExecutablePath)
+	require.NoError(t, err)
+	assert.NotZero(t, info.Mode().Perm()&0111)
+
+	t.Setenv("PATH", filepath.Join(root, "bin"))
+	require.NoError(t, NewInstaller(config).Install())
+	result, err = NewUserInstaller(config).InstallWithResult()
+	require.NoError(t, err)
+	assert.True(t, result.OnPath)
+}
@@ -0,0 +1,129 @@
+package installer
+
+import (
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/scagogogo/go-composer-sdk/pkg/utils"
+)
+
+// DownloadInstallerScript 下载 Composer 安装脚本并校验其 SHA-384
+//
+// 参数：
+//   - config: 安装器配置，使用其中的 DownloadURL、SignatureURL、InstallerChecksum 和代理设置
+//
+// 返回值：
+//   - string: 安装脚本的临时文件路径，调用方负责在使用后删除
+//   - error: 如果下载失败，返回包装了 ErrDownloadFailed 的错误；校验失败时返回包装了 ErrChecksumMismatch 的错误
+//
+// 功能说明：
+//
+//	期望的校验和优先使用 config.InstallerChecksum，未设置时从 config.SignatureURL 获取官方签名。
+//	安装脚本保存在唯一的临时文件中，校验失败时会删除该文件。
+//
+// 用法示例：
+//
+//	scriptPath, err := installer.DownloadInstallerScript(installer.DefaultConfig())
+//	if errors.Is(err, installer.ErrChecksumMismatch) {
+//	    log.Fatal("安装脚本可能已被篡改")
+//	}
+//	defer os.Remove(scriptPath)
+func DownloadInstallerScript(config Config) (string, error) {
+	expected := strings.ToLower(strings.TrimSpace(config.InstallerChecksum))
+	if expected == "" {
+		if config.SignatureURL == "" {
+			return "", fmt.Errorf("%w: 未配置安装脚本签名地址或校验和", ErrDownloadFailed)
+		}
+		signature, err := fetchInstallerSignature(config)
+		if err != nil {
+			return "", err
+		}
+		expected = signature
+	}
+
+	tmp, err := os.CreateTemp("", "composer-setup-*.php")
+	if err != nil {
+		return "", fmt.Errorf("%w: 无法创建临时文件: %v", ErrDownloadFailed, err)
+	}
+	scriptPath := tmp.Name()
+	tmp.Close()
+
+	if err := utils.DownloadFile(config.DownloadURL, scriptPath, downloadConfig(config)); err != nil {
+		os.Remove(scriptPath)
+		return "", fmt.Errorf("%w: %v", ErrDownloadFailed, err)
+	}
+
+	actual, err := fileSHA384(scriptPath)
+	if err != nil {
+		os.Remove(scriptPath)
+		return "", err
+	}
+	if actual != expected {
+		os.Remove(scriptPath)
+		return "", fmt.Errorf("%w: 安装脚本期望 %s，实际 %s", ErrChecksumMismatch, expected, actual)
+	}
+
+	return scriptPath, nil
+}
+
+// fetchInstallerSignature 获取官方发布的安装脚本 SHA-384 签名
+func fetchInstallerSignature(config Config) (string, error) {
+	client, err := utils.NewHTTPClient(downloadConfig(config))
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := client.Get(config.SignatureURL)
+	if err != nil {
+		return "", fmt.Errorf("%w: 获取安装脚本签名失败: %v", ErrDownloadFailed, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%w: 获取安装脚本签名失败，服务器返回状态码 %d", ErrDownloadFailed, resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	if err != nil {
+		return "", fmt.Errorf("%w: 读取安装脚本签名失败: %v", ErrDownloadFailed, err)
+	}
+
+	signature := strings.ToLower(strings.TrimSpace(string(data)))
+	if _, err := hex.DecodeString(signature); err != nil || len(signature) != sha512.Size384*2 {
+		return "", fmt.Errorf("%w: 安装脚本签名格式无效", ErrDownloadFailed)
+	}
+	return signature, nil
+}
+
+// fileSHA384 计算文件的 SHA-384 十六进制值
+func fileSHA384(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha512.New384()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// downloadRetries 是安装器下载文件失败后的重试次数
+const downloadRetries = 3
+
+// downloadConfig 返回安装器配置对应的下载配置
+func downloadConfig(config Config) utils.DownloadConfig {
+	return utils.DownloadConfig{
+		UseProxy:       config.UseProxy,
+		ProxyURL:       config.ProxyURL,
+		TimeoutSeconds: config.TimeoutSeconds,
+		Retries:        downloadRetries,
+	}
+}
@@ -0,0 +1,117 @@
+package installer
+
+import (
+	"crypto/sha512"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testInstallerScript = "<?php echo 'composer-setup';\n"
+
+// newInstallerServer 创建提供安装脚本和签名的测试服务器
+func newInstallerServer(t *testing.T, signature string) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/installer", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(testInstallerScript))
+	})
+	mux.HandleFunc("/installer.sig", func(w http.ResponseWriter, r *http.Request) {
+		if signature == "" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(signature + "\n"))
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func testScriptChecksum() string {
+	sum := sha512.Sum384([]byte(testInstallerScript))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestDownloadInstallerScript(t *testing.T) {
+	server := newInstallerServer(t, strings.ToUpper(testScriptChecksum()))
+
+	config := DefaultConfig()
+	config.DownloadURL = server.URL + "/installer"
+	config.SignatureURL = server.URL + "/installer.sig"
+
+	first, err := DownloadInstallerScript(config)
+	require.NoError(t, err)
+	defer os.Remove(first)
+
+	content, err := os.ReadFile(first)
+	require.NoError(t, err)
+	assert.Equal(t, testInstallerScript, string(content))
+
+	// 每次下载使用唯一的临时文件
+	second, err := DownloadInstallerScript(config)
+	require.NoError(t, err)
+	defer os.Remove(second)
+	assert.NotEqual(t, first, second)
+}
+
+func TestDownloadInstallerScriptMismatch(t *testing.T) {
+	server := newInstallerServer(t, strings.Repeat("0", 96))
+
+	config := DefaultConfig()
+	config.DownloadURL = server.URL + "/installer"
+	config.SignatureURL = server.URL + "/installer.sig"
+
+	path, err := DownloadInstallerScript(config)
+	assert.ErrorIs(t, err, ErrChecksumMismatch)
+	assert.Empty(t, path)
+
+	// 固定的校验和优先于签名地址
+	config.InstallerChecksum = testScriptChecksum()
+	path, err = DownloadInstallerScript(config)
+	require.NoError(t, err)
+	os.Remove(path)
+
+	config.InstallerChecksum = strings.Repeat("a", 96)
+	_, err = DownloadInstallerScript(config)
+	assert.ErrorIs(t, err, ErrChecksumMismatch)
+}
+
+func TestDownloadInstallerScriptSignatureErrors(t *testing.T) {
+	server := newInstallerServer(t, "")
+
+	config := DefaultConfig()
+	config.DownloadURL = server.URL + "/installer"
+	config.SignatureURL = server.URL + "/installer.sig"
+
+	_, err := DownloadInstallerScript(config)
+	assert.ErrorIs(t, err, ErrDownloadFailed)
+
+	invalid := newInstallerServer(t, "not-a-signature")
+	config.SignatureURL = invalid.URL + "/installer.sig"
+	_, err = DownloadInstallerScript(config)
+	assert.ErrorIs(t, err, ErrDownloadFailed)
+
+	config.SignatureURL = ""
+	_, err = DownloadInstallerScript(config)
+	assert.ErrorIs(t, err, ErrDownloadFailed)
+}
+
+func TestLinuxInstallerRejectsTamperedScript(t *testing.T) {
+	server := newInstallerServer(t, strings.Repeat("0", 96))
+
+	config := DefaultConfig()
+	config.DownloadURL = server.URL + "/installer"
+	config.SignatureURL = server.URL + "/installer.sig"
+	config.InstallPath = t.TempDir()
+
+	err := NewLinuxInstaller(config).Install()
+	assert.ErrorIs(t, err, ErrChecksumMismatch)
+	assert.NoFileExists(t, config.InstallPath+"/composer")
+}
@@ -2,8 +2,6 @@ package installer
 
 import (
 	"fmt"
-	"os"
-	"os/exec"
 	"path/filepath"
 
 	"github.com/scagogogo/go-composer-sdk/pkg/utils"
@@ -28,28 +26,15 @@ func (i *WindowsInstaller) Install() error {
 		return fmt.Errorf("创建安装目录失败: %w", err)
 	}
 
-	// 下载安装脚本
-	scriptPath := filepath.Join(os.TempDir(), "composer-setup.php")
-	downloadConfig := utils.DownloadConfig{
-		UseProxy: i.config.UseProxy,
-		ProxyURL: i.config.ProxyURL,
-	}
-	if err := utils.DownloadFile(i.config.DownloadURL, scriptPath, downloadConfig); err != nil {
-		return err
-	}
-	defer os.Remove(scriptPath)
-
-	// 执行PHP脚本安装Composer
+	// 安装 composer.phar
 	pharPath := filepath.Join(i.config.InstallPath, "composer.phar")
-	cmd := exec.Command("php", scriptPath, "--install-dir="+i.config.InstallPath, "--filename=composer.phar")
-	if output, err := cmd.CombinedOutput(); err != nil {
-		return fmt.Errorf("%w: %s, 错误: %v", ErrInstallationFailed, string(output), err)
+	if err := installComposerPhar(i.config, pharPath, false); err != nil {
+		return err
 	}
 
 	// 创建批处理文件以便直接调用composer
 	batPath := filepath.Join(i.config.InstallPath, "composer.bat")
-	batContent := fmt.Sprintf("@php \"%s\" %%*", pharPath)
-	if err := utils.CreateFileWithContent(batPath, []byte(batContent), 0755); err != nil {
+	if err := utils.CreateFileWithContent(batPath, []byte(wrapperScript(pharPath)), 0755); err != nil {
 		return fmt.Errorf("创建批处理文件失败: %w", err)
 	}
 
@@ -60,3 +45,28 @@ func (i *WindowsInstaller) Install() error {
 
 	return nil
 }
+
+// lifecycle 返回Windows 安装器的卸载、升级和回滚实现
+func (i *WindowsInstaller) lifecycle() lifecycle {
+	return lifecycle{
+		config:    i.config,
+		pharPath:  filepath.Join(i.config.InstallPath, "composer.phar"),
+		binPath:   filepath.Join(i.config.InstallPath, "composer.bat"),
+		allowSudo: false,
+	}
+}
+
+// Uninstall 从 Windows 上卸载 Composer
+func (i *WindowsInstaller) Uninstall() error {
+	return i.lifecycle().uninstall()
+}
+
+// Upgrade 在 Windows 上将 Composer 升级到指定版本，并保留当前版本作为备份
+func (i *WindowsInstaller) Upgrade(targetVersion string) error {
+	return i.lifecycle().upgrade(targetVersion)
+}
+
+// Rollback 在 Windows 上回滚到升级前的 Composer 版本
+func (i *WindowsInstaller) Rollback() error {
+	return i.lifecycle().rollback()
+}
@@ -0,0 +1,17 @@
+//go:build !windows
+
+package installer
+
+import "syscall"
+
+// isWritable 判断当前用户能否在目录中创建文件，不会修改文件系统
+//
+// 目录不存在时检查最近的已存在的上级目录，因为安装时会创建该目录。
+func isWritable(dir string) bool {
+	existing, ok := nearestExistingDir(dir)
+	if !ok {
+		return false
+	}
+	const wOK = 0x2
+	return syscall.Access(existing, wOK) == nil
+}
@@ -0,0 +1,22 @@
+//go:build windows
+
+package installer
+
+import "os"
+
+// isWritable 判断当前用户能否在目录中创建文件
+//
+// Windows 上无法只通过权限位判断，因此在最近的已存在的目录中创建并立即删除一个临时文件。
+func isWritable(dir string) bool {
+	existing, ok := nearestExistingDir(dir)
+	if !ok {
+		return false
+	}
+	f, err := os.CreateTemp(existing, ".write-test-*")
+	if err != nil {
+		return false
+	}
+	f.Close()
+	os.Remove(f.Name())
+	return true
+}
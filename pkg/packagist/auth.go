@@ -0,0 +1,220 @@
+package packagist
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// HTTPBasicCredential 表示 http-basic 认证信息
+type HTTPBasicCredential struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// UnmarshalJSON 解析 http-basic 认证信息
+//
+// 除 Composer 标准的 {"username": ..., "password": ...} 对象外，
+// 也兼容 SDK 早期写入的 "username:password" 字符串。
+func (c *HTTPBasicCredential) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		c.Username, c.Password, _ = strings.Cut(s, ":")
+		return nil
+	}
+
+	type plain HTTPBasicCredential
+	return json.Unmarshal(data, (*plain)(c))
+}
+
+// GitLabTokenCredential 表示 gitlab-token 认证信息
+type GitLabTokenCredential struct {
+	Username string `json:"username,omitempty"`
+	Token    string `json:"token"`
+}
+
+// UnmarshalJSON 解析 gitlab-token 认证信息，兼容只包含令牌的字符串
+func (c *GitLabTokenCredential) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		c.Token = s
+		return nil
+	}
+
+	type plain GitLabTokenCredential
+	return json.Unmarshal(data, (*plain)(c))
+}
+
+// Auth 表示 auth.json 中的认证信息，按域名组织
+type Auth struct {
+	HTTPBasic   map[string]HTTPBasicCredential   `json:"http-basic,omitempty"`
+	Bearer      map[string]string                `json:"bearer,omitempty"`
+	GitHubOAuth map[string]string                `json:"github-oauth,omitempty"`
+	GitLabOAuth map[string]string                `json:"gitlab-oauth,omitempty"`
+	GitLabToken map[string]GitLabTokenCredential `json:"gitlab-token,omitempty"`
+}
+
+// ParseAuth 解析 auth.json 或 COMPOSER_AUTH 的内容
+//
+// 参数：
+//   - data: JSON格式的认证信息
+//
+// 返回值：
+//   - *Auth: 解析后的认证信息
+//   - error: 如果内容不是合法的JSON，则返回相应的错误信息
+func ParseAuth(data []byte) (*Auth, error) {
+	var auth Auth
+	if len(strings.TrimSpace(string(data))) == 0 {
+		return &auth, nil
+	}
+	if err := json.Unmarshal(data, &auth); err != nil {
+		return nil, fmt.Errorf("解析认证信息失败: %w", err)
+	}
+	return &auth, nil
+}
+
+// LoadAuth 依次读取多个 auth.json 文件并合并认证信息
+//
+// 后面的文件优先级更高，不存在的文件会被忽略。
+// 如果设置了 COMPOSER_AUTH 环境变量，其内容具有最高优先级，与 Composer 的行为一致。
+//
+// 用法示例：
+//
+//	auth, err := packagist.LoadAuth(
+//	    filepath.Join(composerHome, "auth.json"),
+//	    filepath.Join(projectDir, "auth.json"),
+//	)
+func LoadAuth(paths ...string) (*Auth, error) {
+	merged, err := LoadAuthFiles(paths...)
+	if err != nil {
+		return nil, err
+	}
+
+	if env := os.Getenv("COMPOSER_AUTH"); env != "" {
+		auth, err := ParseAuth([]byte(env))
+		if err != nil {
+			return nil, fmt.Errorf("COMPOSER_AUTH: %w", err)
+		}
+		merged.Merge(auth)
+	}
+
+	return merged, nil
+}
+
+// LoadAuthFiles 依次读取多个 auth.json 文件并合并认证信息，不读取 COMPOSER_AUTH 环境变量
+//
+// 后面的文件优先级更高，不存在的文件会被忽略。需要自行决定 COMPOSER_AUTH 的来源时使用，
+// 例如 composer.Composer 使用实例的环境变量。
+func LoadAuthFiles(paths ...string) (*Auth, error) {
+	merged := &Auth{}
+
+	for _, path := range paths {
+		if path == "" {
+			continue
+		}
+		data, err := os.ReadFile(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		auth, err := ParseAuth(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		merged.Merge(auth)
+	}
+
+	return merged, nil
+}
+
+// Merge 合并另一份认证信息，同一域名以 other 中的为准
+func (a *Auth) Merge(other *Auth) {
+	if other == nil {
+		return
+	}
+	a.HTTPBasic = mergeMap(a.HTTPBasic, other.HTTPBasic)
+	a.Bearer = mergeMap(a.Bearer, other.Bearer)
+	a.GitHubOAuth = mergeMap(a.GitHubOAuth, other.GitHubOAuth)
+	a.GitLabOAuth = mergeMap(a.GitLabOAuth, other.GitLabOAuth)
+	a.GitLabToken = mergeMap(a.GitLabToken, other.GitLabToken)
+}
+
+// mergeMap 将 src 中的键值合并到 dst
+func mergeMap[V any](dst, src map[string]V) map[string]V {
+	if len(src) == 0 {
+		return dst
+	}
+	if dst == nil {
+		dst = make(map[string]V, len(src))
+	}
+	for k, v := range src {
+		dst[k] = v
+	}
+	return dst
+}
+
+// Apply 根据请求的域名为请求添加认证信息
+//
+// 查找顺序为 http-basic、bearer、gitlab-token、gitlab-oauth、github-oauth，
+// 域名先按 host:port 精确匹配，再按不带端口的主机名匹配。
+// github-oauth 配置在 github.com 上时同样适用于 api.github.com 和 codeload.github.com。
+//
+// 返回值：
+//   - bool: 是否添加了认证信息
+func (a *Auth) Apply(req *http.Request) bool {
+	if a == nil || req.URL == nil {
+		return false
+	}
+
+	domains := []string{req.URL.Host}
+	if hostname := req.URL.Hostname(); hostname != req.URL.Host {
+		domains = append(domains, hostname)
+	}
+
+	for _, domain := range domains {
+		if cred, ok := a.HTTPBasic[domain]; ok {
+			req.SetBasicAuth(cred.Username, cred.Password)
+			return true
+		}
+		if token, ok := a.Bearer[domain]; ok {
+			req.Header.Set("Authorization", "Bearer "+token)
+			return true
+		}
+		if cred, ok := a.GitLabToken[domain]; ok {
+			if cred.Username != "" {
+				req.SetBasicAuth(cred.Username, cred.Token)
+			} else {
+				req.Header.Set("PRIVATE-TOKEN", cred.Token)
+			}
+			return true
+		}
+		if token, ok := a.GitLabOAuth[domain]; ok {
+			req.Header.Set("Authorization", "Bearer "+token)
+			return true
+		}
+		if token, ok := a.GitHubOAuth[githubAuthDomain(domain)]; ok {
+			req.Header.Set("Authorization", "token "+token)
+			return true
+		}
+	}
+
+	return false
+}
+
+// githubAuthDomain 将 GitHub 的 API 和下载域名映射到 github-oauth 中配置的域名
+func githubAuthDomain(domain string) string {
+	switch domain {
+	case "api.github.com", "codeload.github.com":
+		return "github.com"
+	}
+	return domain
+}
+
+// IsEmpty 判断是否没有任何认证信息
+func (a *Auth) IsEmpty() bool {
+	return a == nil || len(a.HTTPBasic)+len(a.Bearer)+len(a.GitHubOAuth)+len(a.GitLabOAuth)+len(a.GitLabToken) == 0
+}
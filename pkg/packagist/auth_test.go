@@ -0,0 +1,159 @@
+package packagist
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseAuthFormats(t *testing.T) {
+	auth, err := ParseAuth([]byte(`{
+		"http-basic": {
+			"repo.example.com": {"username": "alice", "password": "secret"},
+			"legacy.example.com": "bob:pa:ss"
+		},
+		"gitlab-token": {
+			"gitlab.com": "glpat-1",
+			"gitlab.example.com": {"username": "deploy", "token": "glpat-2"}
+		}
+	}`))
+	if err != nil {
+		t.Fatalf("解析认证信息失败: %v", err)
+	}
+
+	if cred := auth.HTTPBasic["repo.example.com"]; cred.Username != "alice" || cred.Password != "secret" {
+		t.Errorf("对象格式的http-basic解析不正确: %+v", cred)
+	}
+	if cred := auth.HTTPBasic["legacy.example.com"]; cred.Username != "bob" || cred.Password != "pa:ss" {
+		t.Errorf("字符串格式的http-basic解析不正确: %+v", cred)
+	}
+	if cred := auth.GitLabToken["gitlab.com"]; cred.Token != "glpat-1" || cred.Username != "" {
+		t.Errorf("字符串格式的gitlab-token解析不正确: %+v", cred)
+	}
+	if cred := auth.GitLabToken["gitlab.example.com"]; cred.Username != "deploy" {
+		t.Errorf("对象格式的gitlab-token解析不正确: %+v", cred)
+	}
+
+	if _, err := ParseAuth([]byte(`{invalid`)); err == nil {
+		t.Error("无效的JSON应返回错误")
+	}
+}
+
+func TestLoadAuthPrecedence(t *testing.T) {
+	dir := t.TempDir()
+	global := filepath.Join(dir, "global.json")
+	project := filepath.Join(dir, "project.json")
+	_ = os.WriteFile(global, []byte(`{"bearer": {"a.example.com": "global", "b.example.com": "global"}}`), 0600)
+	_ = os.WriteFile(project, []byte(`{"bearer": {"b.example.com": "project"}}`), 0600)
+	t.Setenv("COMPOSER_AUTH", `{"bearer": {"a.example.com": "env"}}`)
+
+	auth, err := LoadAuth(global, project, filepath.Join(dir, "missing.json"))
+	if err != nil {
+		t.Fatalf("加载认证信息失败: %v", err)
+	}
+	if auth.Bearer["a.example.com"] != "env" {
+		t.Errorf("COMPOSER_AUTH应具有最高优先级，实际为%q", auth.Bearer["a.example.com"])
+	}
+	if auth.Bearer["b.example.com"] != "project" {
+		t.Errorf("项目auth.json应覆盖全局配置，实际为%q", auth.Bearer["b.example.com"])
+	}
+}
+
+func TestAuthApply(t *testing.T) {
+	auth := &Auth{
+		HTTPBasic:   map[string]HTTPBasicCredential{"repo.example.com:8080": {Username: "u", Password: "p"}},
+		Bearer:      map[string]string{"bearer.example.com": "tok"},
+		GitHubOAuth: map[string]string{"github.com": "gh"},
+		GitLabToken: map[string]GitLabTokenCredential{"gitlab.com": {Token: "gl"}},
+	}
+
+	cases := []struct {
+		url    string
+		header string
+		want   string
+	}{
+		{"https://repo.example.com:8080/packages.json", "Authorization", "Basic dTpw"},
+		{"https://bearer.example.com/p2/a/b.json", "Authorization", "Bearer tok"},
+		{"https://api.github.com/repos/a/b/zipball/abc", "Authorization", "token gh"},
+		{"https://gitlab.com/api/v4/projects/1", "PRIVATE-TOKEN", "gl"},
+		{"https://other.example.com/packages.json", "Authorization", ""},
+	}
+	for _, tc := range cases {
+		u, _ := url.Parse(tc.url)
+		req := &http.Request{URL: u, Header: http.Header{}}
+		auth.Apply(req)
+		if got := req.Header.Get(tc.header); got != tc.want {
+			t.Errorf("%s: %s 应为%q，实际为%q", tc.url, tc.header, tc.want, got)
+		}
+	}
+}
+
+func TestClientPrivateRepository(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, ok := r.BasicAuth(); !ok || user != "alice" || pass != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/packages.json":
+			_, _ = w.Write([]byte(`{"packages": [], "providers-url": "/p/%package%$%hash%.json", "provider-includes": {"p/include$%hash%.json": {"sha256": "abc"}}}`))
+		case "/p/include$abc.json":
+			_, _ = w.Write([]byte(`{"providers": {"acme/private": {"sha256": "def"}}}`))
+		case "/p/acme/private$def.json":
+			_, _ = w.Write([]byte(`{"packages": {"acme/private": {"1.0.0": {"name": "acme/private", "version": "1.0.0", "dist": {"type": "zip", "url": "` + server.URL + `/dists/acme-private-1.0.0.zip"}}}}}`))
+		case "/dists/acme-private-1.0.0.zip":
+			_, _ = w.Write([]byte("zip-content"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	u, _ := url.Parse(server.URL)
+
+	// 未配置认证信息时请求失败
+	anonymous := newTestClient(t, server.URL)
+	if _, err := anonymous.GetPackageVersions("acme/private"); !errors.Is(err, ErrRequestFailed) {
+		t.Errorf("未认证的请求应失败，实际为%v", err)
+	}
+
+	config := DefaultConfig()
+	config.BaseURL = server.URL
+	config.Auth = &Auth{HTTPBasic: map[string]HTTPBasicCredential{u.Host: {Username: "alice", Password: "secret"}}}
+	client, err := NewClient(config)
+	if err != nil {
+		t.Fatalf("创建客户端失败: %v", err)
+	}
+
+	providers, err := client.GetProviders()
+	if err != nil || providers["acme/private"] != "def" {
+		t.Fatalf("读取provider-includes失败: %v %v", providers, err)
+	}
+
+	versions, err := client.GetPackageVersions("acme/private")
+	if err != nil || len(versions) != 1 {
+		t.Fatalf("获取私有包版本失败: %v %v", versions, err)
+	}
+
+	var buf bytes.Buffer
+	if err := client.DownloadDist(versions[0].Dist, &buf); err != nil {
+		t.Fatalf("下载分发包失败: %v", err)
+	}
+	if buf.String() != "zip-content" {
+		t.Errorf("分发包内容不正确: %q", buf.String())
+	}
+
+	dest := filepath.Join(t.TempDir(), "dist", "acme-private.zip")
+	if _, err := client.DownloadDistFile(versions[0].Dist, dest); err != nil {
+		t.Fatalf("保存分发包失败: %v", err)
+	}
+	if data, _ := os.ReadFile(dest); string(data) != "zip-content" {
+		t.Errorf("保存的分发包内容不正确: %q", data)
+	}
+}
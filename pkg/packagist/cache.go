@@ -0,0 +1,333 @@
+package packagist
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrCacheMiss 表示离线模式下缓存中不存在所需的元数据
+var ErrCacheMiss = errors.New("缓存中不存在该元数据")
+
+// 注入到缓存文件中的字段名，与 Composer 的缓存格式保持一致
+const (
+	cacheKeyLastModified = "last-modified"
+	cacheKeyETag         = "etag"
+)
+
+// nonAlnumPattern 匹配 Composer 缓存目录名中需要替换的字符
+var nonAlnumPattern = regexp.MustCompile(`[^a-zA-Z0-9.]`)
+
+// DefaultCacheDir 返回 Composer 默认的仓库元数据缓存目录
+//
+// 优先使用 COMPOSER_CACHE_DIR 环境变量，否则按照 Composer 在各平台上的默认位置：
+//   - Linux/Unix: $XDG_CACHE_HOME/composer 或 ~/.cache/composer
+//   - macOS: ~/Library/Caches/composer
+//   - Windows: %LOCALAPPDATA%\Composer
+//
+// 返回的路径为其中的 repo 子目录。
+func DefaultCacheDir() string {
+	if dir := os.Getenv("COMPOSER_CACHE_DIR"); dir != "" {
+		return filepath.Join(dir, "repo")
+	}
+
+	home, _ := os.UserHomeDir()
+	switch runtime.GOOS {
+	case "windows":
+		return filepath.Join(os.Getenv("LOCALAPPDATA"), "Composer", "repo")
+	case "darwin":
+		return filepath.Join(home, "Library", "Caches", "composer", "repo")
+	default:
+		if xdg := os.Getenv("XDG_CACHE_HOME"); xdg != "" {
+			return filepath.Join(xdg, "composer", "repo")
+		}
+		return filepath.Join(home, ".cache", "composer", "repo")
+	}
+}
+
+// cacheEntry 表示一条缓存的元数据
+type cacheEntry struct {
+	body         []byte
+	lastModified string
+	etag         string
+	storedAt     time.Time
+}
+
+// metadataCache 是仓库元数据的磁盘缓存
+//
+// 目录布局与 Composer 的 COMPOSER_CACHE_DIR/repo 相同：每个仓库一个子目录，
+// 目录名为仓库地址中非字母数字的字符替换为 "-"，文件名为 packages.json 或
+// provider-vendor~package.json，并在JSON中注入 last-modified 字段。
+type metadataCache struct {
+	dir     string
+	maxSize int64
+	mu      sync.Mutex
+}
+
+// newMetadataCache 创建仓库的元数据缓存
+func newMetadataCache(root string, baseURL string, maxSize int64) *metadataCache {
+	return &metadataCache{
+		dir:     filepath.Join(root, repoCacheDirName(baseURL)),
+		maxSize: maxSize,
+	}
+}
+
+// repoCacheDirName 按照 Composer 的规则生成仓库缓存目录名
+func repoCacheDirName(baseURL string) string {
+	return nonAlnumPattern.ReplaceAllString(baseURL, "-")
+}
+
+// v2CacheFile 返回 v2 元数据的缓存文件名，例如 acme/logger~dev 对应 provider-acme~logger~dev.json
+func v2CacheFile(key string) string {
+	return "provider-" + strings.ReplaceAll(key, "/", "~") + ".json"
+}
+
+// v1CacheFile 返回 v1 provider 的缓存文件名，例如 acme/logger 对应 provider-acme$logger.json
+func v1CacheFile(name string) string {
+	return "provider-" + strings.ReplaceAll(name, "/", "$") + ".json"
+}
+
+// read 读取缓存条目
+func (m *metadataCache) read(file string) (*cacheEntry, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	path := filepath.Join(m.dir, file)
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, false
+	}
+	body, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+
+	var headers struct {
+		LastModified string `json:"last-modified"`
+		ETag         string `json:"etag"`
+	}
+	_ = json.Unmarshal(body, &headers)
+
+	return &cacheEntry{
+		body:         body,
+		lastModified: headers.LastModified,
+		etag:         headers.ETag,
+		storedAt:     info.ModTime(),
+	}, true
+}
+
+// write 写入缓存条目，并在超出容量限制时淘汰最旧的文件
+func (m *metadataCache) write(file string, body []byte, lastModified string, etag string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	content := injectCacheHeaders(body, lastModified, etag)
+
+	if err := os.MkdirAll(m.dir, 0755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(m.dir, ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), filepath.Join(m.dir, file)); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	return m.enforceLimit(file)
+}
+
+// touch 更新缓存条目的时间，用于304响应后重置TTL
+func (m *metadataCache) touch(file string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	_ = os.Chtimes(filepath.Join(m.dir, file), now, now)
+}
+
+// remove 删除缓存条目
+func (m *metadataCache) remove(file string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	_ = os.Remove(filepath.Join(m.dir, file))
+}
+
+// enforceLimit 按修改时间从旧到新删除文件，直到缓存目录大小不超过限制
+func (m *metadataCache) enforceLimit(keep string) error {
+	if m.maxSize <= 0 {
+		return nil
+	}
+
+	entries, err := os.ReadDir(m.dir)
+	if err != nil {
+		return err
+	}
+
+	type fileInfo struct {
+		name    string
+		size    int64
+		modTime time.Time
+	}
+
+	var files []fileInfo
+	var total int64
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		files = append(files, fileInfo{e.Name(), info.Size(), info.ModTime()})
+		total += info.Size()
+	}
+
+	sort.Slice(files, func(i, j int) bool { return files[i].modTime.Before(files[j].modTime) })
+
+	for _, f := range files {
+		if total <= m.maxSize {
+			break
+		}
+		if f.name == keep {
+			continue
+		}
+		if err := os.Remove(filepath.Join(m.dir, f.name)); err == nil {
+			total -= f.size
+		}
+	}
+
+	return nil
+}
+
+// injectCacheHeaders 将 Last-Modified 和 ETag 写入JSON对象的顶层字段
+//
+// Composer 会读取缓存文件中的 last-modified 字段来发送 If-Modified-Since 请求头，
+// 因此这样生成的缓存文件可以与 Composer 共享。非JSON对象的内容保持不变。
+func injectCacheHeaders(body []byte, lastModified string, etag string) []byte {
+	if lastModified == "" && etag == "" {
+		return body
+	}
+
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(body, &obj); err != nil {
+		return body
+	}
+
+	if lastModified != "" {
+		obj[cacheKeyLastModified], _ = json.Marshal(lastModified)
+	}
+	if etag != "" {
+		obj[cacheKeyETag], _ = json.Marshal(etag)
+	}
+
+	content, err := json.Marshal(obj)
+	if err != nil {
+		return body
+	}
+	return content
+}
+
+// cachedGet 通过缓存获取元数据
+//
+// 缓存条目在TTL内直接返回；过期后带上 If-Modified-Since/If-None-Match 请求头重新验证，
+// 服务器返回304时继续使用缓存。离线模式下只读取缓存，网络错误时也会回退到过期的缓存。
+func (c *Client) cachedGet(target string, file string) ([]byte, error) {
+	entry, cached := c.cache.read(file)
+
+	if c.config.Offline {
+		if !cached {
+			return nil, fmt.Errorf("%w: %s", ErrCacheMiss, target)
+		}
+		return entry.body, nil
+	}
+
+	if cached && c.config.CacheTTL > 0 && time.Since(entry.storedAt) < c.config.CacheTTL {
+		return entry.body, nil
+	}
+
+	headers := map[string]string{}
+	if cached {
+		if entry.lastModified != "" {
+			headers["If-Modified-Since"] = entry.lastModified
+		}
+		if entry.etag != "" {
+			headers["If-None-Match"] = entry.etag
+		}
+	}
+
+	resp, err := c.do(target, headers)
+	if err != nil {
+		if cached && !errors.Is(err, ErrPackageNotFound) {
+			return entry.body, nil
+		}
+		if errors.Is(err, ErrPackageNotFound) {
+			c.cache.remove(file)
+		}
+		return nil, err
+	}
+
+	if resp.notModified {
+		if !cached {
+			return nil, fmt.Errorf("%w: %s 返回304但缓存中没有对应内容", ErrRequestFailed, target)
+		}
+		c.cache.touch(file)
+		return entry.body, nil
+	}
+
+	// 缓存只用于加速，写入或淘汰失败（例如磁盘已满、目录只读）不影响本次获取的结果
+	_ = c.cache.write(file, resp.body, resp.lastModified, resp.etag)
+	return resp.body, nil
+}
+
+// cachedGetHashed 通过缓存获取以内容哈希寻址的 provider 文件
+//
+// 这类文件的地址中包含内容的 sha256，缓存文件却只以包名命名，
+// 因此缓存内容的 sha256 与期望的哈希一致时才会使用，且不受TTL限制；
+// 不一致说明 provider 已经更新，需要重新下载。
+func (c *Client) cachedGetHashed(target string, file string, hash string) ([]byte, error) {
+	if entry, cached := c.cache.read(file); cached && sha256Hex(entry.body) == hash {
+		return entry.body, nil
+	}
+	if c.config.Offline {
+		return nil, fmt.Errorf("%w: %s", ErrCacheMiss, target)
+	}
+
+	resp, err := c.do(target, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// 不注入 last-modified 等字段，保证缓存内容的哈希与 provider 一致
+	_ = c.cache.write(file, resp.body, "", "")
+	return resp.body, nil
+}
+
+// sha256Hex 返回内容的 sha256 十六进制字符串
+func sha256Hex(body []byte) string {
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:])
+}
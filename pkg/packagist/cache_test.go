@@ -0,0 +1,219 @@
+package packagist
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const testLastModified = "Mon, 01 Jan 2024 00:00:00 GMT"
+
+// newConditionalServer 创建支持 If-Modified-Since 的测试服务器
+func newConditionalServer(t *testing.T, requests *int32, notModified *int32) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(requests, 1)
+		switch r.URL.Path {
+		case "/packages.json":
+			_, _ = w.Write([]byte(`{"packages": [], "metadata-url": "/p2/%package%.json"}`))
+		case "/p2/acme/logger.json":
+			if r.Header.Get("If-Modified-Since") == testLastModified || r.Header.Get("If-None-Match") == `"v1"` {
+				atomic.AddInt32(notModified, 1)
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			w.Header().Set("Last-Modified", testLastModified)
+			w.Header().Set("ETag", `"v1"`)
+			_, _ = w.Write([]byte(testMinifiedMetadata))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newCachedClient(t *testing.T, baseURL string, cacheDir string, mutate func(*Config)) *Client {
+	t.Helper()
+	config := DefaultConfig()
+	config.BaseURL = baseURL
+	config.CacheDir = cacheDir
+	if mutate != nil {
+		mutate(&config)
+	}
+	client, err := NewClient(config)
+	if err != nil {
+		t.Fatalf("创建客户端失败: %v", err)
+	}
+	return client
+}
+
+func TestCacheConditionalRequests(t *testing.T) {
+	var requests, notModified int32
+	server := newConditionalServer(t, &requests, &notModified)
+	cacheDir := t.TempDir()
+
+	client := newCachedClient(t, server.URL, cacheDir, nil)
+	if _, err := client.GetPackageVersions("acme/logger"); err != nil {
+		t.Fatalf("获取版本失败: %v", err)
+	}
+
+	// 缓存布局与 Composer 相同
+	cacheFile := filepath.Join(cacheDir, repoCacheDirName(server.URL), "provider-acme~logger.json")
+	content, err := os.ReadFile(cacheFile)
+	if err != nil {
+		t.Fatalf("缓存文件不存在: %v", err)
+	}
+	if !strings.Contains(string(content), `"last-modified":"`+testLastModified+`"`) {
+		t.Errorf("缓存文件应注入last-modified字段: %s", content)
+	}
+	if !strings.HasPrefix(filepath.Base(filepath.Dir(cacheFile)), "http---127.0.0.1") {
+		t.Errorf("仓库缓存目录名不符合Composer规则: %s", filepath.Dir(cacheFile))
+	}
+
+	// 新客户端复用磁盘缓存并发送条件请求
+	client = newCachedClient(t, server.URL, cacheDir, nil)
+	versions, err := client.GetPackageVersions("acme/logger")
+	if err != nil {
+		t.Fatalf("从缓存获取版本失败: %v", err)
+	}
+	if len(versions) != 3 {
+		t.Errorf("缓存内容解析不正确，版本数为%d", len(versions))
+	}
+	if atomic.LoadInt32(&notModified) != 1 {
+		t.Errorf("应发送一次条件请求并收到304，实际为%d", notModified)
+	}
+}
+
+func TestCacheTTLAndOffline(t *testing.T) {
+	var requests, notModified int32
+	server := newConditionalServer(t, &requests, &notModified)
+	cacheDir := t.TempDir()
+
+	client := newCachedClient(t, server.URL, cacheDir, func(c *Config) { c.CacheTTL = time.Hour })
+	if _, err := client.GetPackageVersions("acme/logger"); err != nil {
+		t.Fatalf("获取版本失败: %v", err)
+	}
+	before := atomic.LoadInt32(&requests)
+	if _, err := client.GetPackageVersions("acme/logger"); err != nil {
+		t.Fatalf("获取版本失败: %v", err)
+	}
+	if after := atomic.LoadInt32(&requests); after != before {
+		t.Errorf("TTL内不应请求仓库，额外请求了%d次", after-before)
+	}
+
+	// 离线模式：服务器关闭后仍可从缓存读取
+	server.Close()
+	offline := newCachedClient(t, server.URL, cacheDir, func(c *Config) { c.Offline = true })
+	versions, err := offline.GetPackageVersions("acme/logger")
+	if err != nil || len(versions) != 3 {
+		t.Fatalf("离线模式应从缓存读取: %d %v", len(versions), err)
+	}
+	if _, err := offline.GetPackageVersions("acme/missing"); !errors.Is(err, ErrCacheMiss) {
+		t.Errorf("离线模式下缓存缺失应返回ErrCacheMiss，实际为%v", err)
+	}
+
+	// 网络错误时回退到过期缓存
+	stale := newCachedClient(t, server.URL, cacheDir, nil)
+	if _, err := stale.GetPackageVersions("acme/logger"); err != nil {
+		t.Errorf("网络错误时应返回过期缓存: %v", err)
+	}
+}
+
+func TestCacheSizeLimit(t *testing.T) {
+	cache := newMetadataCache(t.TempDir(), "https://repo.example.com", 250)
+
+	old := time.Now().Add(-time.Hour)
+	if err := cache.write("provider-a~one.json", []byte(strings.Repeat("a", 100)), "", ""); err != nil {
+		t.Fatalf("写入缓存失败: %v", err)
+	}
+	_ = os.Chtimes(filepath.Join(cache.dir, "provider-a~one.json"), old, old)
+	if err := cache.write("provider-a~two.json", []byte(strings.Repeat("b", 100)), "", ""); err != nil {
+		t.Fatalf("写入缓存失败: %v", err)
+	}
+	if err := cache.write("provider-a~three.json", []byte(strings.Repeat("c", 100)), "", ""); err != nil {
+		t.Fatalf("写入缓存失败: %v", err)
+	}
+
+	if _, ok := cache.read("provider-a~one.json"); ok {
+		t.Error("超出容量时应淘汰最旧的缓存文件")
+	}
+	if _, ok := cache.read("provider-a~three.json"); !ok {
+		t.Error("最新写入的缓存文件不应被淘汰")
+	}
+}
+
+func TestCacheWriteFailure(t *testing.T) {
+	var requests, notModified int32
+	server := newConditionalServer(t, &requests, &notModified)
+	cacheDir := t.TempDir()
+
+	// 仓库缓存目录的位置被普通文件占用，无法写入缓存
+	if err := os.WriteFile(filepath.Join(cacheDir, repoCacheDirName(server.URL)), []byte("x"), 0644); err != nil {
+		t.Fatalf("创建文件失败: %v", err)
+	}
+
+	client := newCachedClient(t, server.URL, cacheDir, nil)
+	versions, err := client.GetPackageVersions("acme/logger")
+	if err != nil {
+		t.Fatalf("缓存写入失败不应影响获取结果: %v", err)
+	}
+	if len(versions) != 3 {
+		t.Errorf("期望3个版本，实际为%d", len(versions))
+	}
+}
+
+func TestCacheProviderHash(t *testing.T) {
+	provider := `{"packages": {"acme/lib": {"1.0.0": {"name": "acme/lib", "version": "1.0.0"}}}}`
+	var providerRequests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		index := `{"providers": {"acme/lib": {"sha256": "` + sha256Hex([]byte(provider)) + `"}}}`
+		switch r.URL.Path {
+		case "/packages.json":
+			_, _ = w.Write([]byte(`{"packages": [], "providers-url": "/p/%package%$%hash%.json", "provider-includes": {"p/include$%hash%.json": {"sha256": "` + sha256Hex([]byte(index)) + `"}}}`))
+		case "/p/include$" + sha256Hex([]byte(index)) + ".json":
+			_, _ = w.Write([]byte(index))
+		case "/p/acme/lib$" + sha256Hex([]byte(provider)) + ".json":
+			atomic.AddInt32(&providerRequests, 1)
+			_, _ = w.Write([]byte(provider))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	cacheDir := t.TempDir()
+
+	versions, err := newCachedClient(t, server.URL, cacheDir, nil).GetPackageVersions("acme/lib")
+	if err != nil || len(versions) != 1 {
+		t.Fatalf("获取版本失败: %v %v", versions, err)
+	}
+
+	// 哈希未变时直接使用缓存
+	if _, err := newCachedClient(t, server.URL, cacheDir, nil).GetPackageVersions("acme/lib"); err != nil {
+		t.Fatalf("获取版本失败: %v", err)
+	}
+	if n := atomic.LoadInt32(&providerRequests); n != 1 {
+		t.Errorf("哈希一致时应使用缓存，实际请求了%d次", n)
+	}
+
+	// provider 更新后哈希变化，缓存中的旧内容不再使用
+	provider = `{"packages": {"acme/lib": {"1.0.0": {"name": "acme/lib", "version": "1.0.0"}, "1.1.0": {"name": "acme/lib", "version": "1.1.0"}}}}`
+	versions, err = newCachedClient(t, server.URL, cacheDir, nil).GetPackageVersions("acme/lib")
+	if err != nil || len(versions) != 2 {
+		t.Fatalf("provider 更新后应获取新版本: %v %v", versions, err)
+	}
+
+	// 离线模式下哈希不一致的缓存视为缺失
+	provider = `{"packages": {"acme/lib": {}}}`
+	offline := newCachedClient(t, server.URL, cacheDir, func(c *Config) { c.Offline = true })
+	offline.providers = map[string]string{"acme/lib": sha256Hex([]byte(provider))}
+	if _, err := offline.GetPackageVersions("acme/lib"); !errors.Is(err, ErrCacheMiss) {
+		t.Errorf("期望错误 %v，但得到 %v", ErrCacheMiss, err)
+	}
+}
@@ -0,0 +1,480 @@
+// Package packagist 实现了 Composer 仓库元数据协议的客户端，
+// 可以在不运行 PHP 的情况下查询 Packagist 或私有 Composer 仓库中的包信息。
+package packagist
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/scagogogo/go-composer-sdk/pkg/utils"
+)
+
+// 常见错误
+var (
+	// ErrPackageNotFound 表示仓库中不存在该包
+	ErrPackageNotFound = errors.New("包不存在")
+	// ErrRequestFailed 表示请求仓库失败
+	ErrRequestFailed = errors.New("请求仓库失败")
+	// ErrInvalidMetadata 表示仓库返回的元数据无法解析
+	ErrInvalidMetadata = errors.New("仓库元数据无效")
+)
+
+// DefaultBaseURL 是 Packagist 元数据仓库的地址
+const DefaultBaseURL = "https://repo.packagist.org"
+
+// defaultMetadataURL 是仓库未声明 metadata-url 时使用的 v2 元数据地址模板
+const defaultMetadataURL = "/p2/%package%.json"
+
+// Config 保存仓库客户端的配置
+type Config struct {
+	// 仓库地址，例如 https://repo.packagist.org 或私有仓库地址
+	BaseURL string
+	// 是否使用代理
+	UseProxy bool
+	// 代理地址
+	ProxyURL string
+	// 超时时间（秒）
+	TimeoutSeconds int
+	// 请求时使用的 User-Agent
+	UserAgent string
+	// 元数据缓存根目录，为空时不使用缓存，可使用 DefaultCacheDir() 与 Composer 共享缓存
+	CacheDir string
+	// 缓存有效期，在有效期内不会请求仓库；为0时每次都通过条件请求重新验证
+	CacheTTL time.Duration
+	// 单个仓库缓存目录的最大字节数，为0表示不限制
+	CacheMaxSize int64
+	// 离线模式，只从缓存读取元数据
+	Offline bool
+	// 认证信息，请求时按域名添加到请求头中，可通过 LoadAuth 从 auth.json 加载
+	Auth *Auth
+}
+
+// DefaultConfig 返回访问 Packagist 的默认配置
+func DefaultConfig() Config {
+	return Config{
+		BaseURL:        DefaultBaseURL,
+		TimeoutSeconds: 30,
+		UserAgent:      "go-composer-sdk",
+	}
+}
+
+// Client 是 Composer 仓库元数据客户端
+type Client struct {
+	config     Config
+	httpClient *http.Client
+
+	// 磁盘元数据缓存，未配置 CacheDir 时为nil
+	cache *metadataCache
+
+	// 缓存的 packages.json
+	rootMu sync.Mutex
+	root   *RootMetadata
+
+	// 缓存的 provider-includes 索引，键为包名，值为 provider 文件的 sha256
+	providersMu sync.Mutex
+	providers   map[string]string
+}
+
+// NewClient 创建一个新的仓库客户端
+//
+// 参数：
+//   - config: 客户端配置，BaseURL 为空时使用 Packagist
+//
+// 返回值：
+//   - *Client: 仓库客户端
+//   - error: 如果代理地址无效，则返回相应的错误信息
+//
+// 用法示例：
+//
+//	client, err := packagist.NewClient(packagist.DefaultConfig())
+//	if err != nil {
+//	    log.Fatalf("创建仓库客户端失败: %v", err)
+//	}
+//	versions, err := client.GetPackageVersions("monolog/monolog")
+func NewClient(config Config) (*Client, error) {
+	if config.BaseURL == "" {
+		config.BaseURL = DefaultBaseURL
+	}
+	config.BaseURL = strings.TrimRight(config.BaseURL, "/")
+
+	httpClient, err := utils.NewHTTPClient(utils.DownloadConfig{
+		UseProxy:       config.UseProxy,
+		ProxyURL:       config.ProxyURL,
+		TimeoutSeconds: config.TimeoutSeconds,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	client := &Client{
+		config:     config,
+		httpClient: httpClient,
+	}
+	if config.CacheDir != "" {
+		client.cache = newMetadataCache(config.CacheDir, config.BaseURL, config.CacheMaxSize)
+	}
+
+	return client, nil
+}
+
+// GetConfig 获取客户端配置
+func (c *Client) GetConfig() Config {
+	return c.config
+}
+
+// SetHTTPClient 设置底层使用的HTTP客户端
+func (c *Client) SetHTTPClient(client *http.Client) {
+	c.httpClient = client
+}
+
+// Root 获取并缓存仓库的 packages.json
+//
+// 仓库没有提供 packages.json（返回404）时，视为只支持默认 v2 元数据地址的仓库，
+// 返回空的根元数据。
+//
+// 返回值：
+//   - *RootMetadata: 仓库根元数据
+//   - error: 如果请求或解析失败，则返回相应的错误信息
+func (c *Client) Root() (*RootMetadata, error) {
+	c.rootMu.Lock()
+	defer c.rootMu.Unlock()
+
+	if c.root != nil {
+		return c.root, nil
+	}
+
+	data, err := c.get(c.config.BaseURL+"/packages.json", "packages.json")
+	if errors.Is(err, ErrPackageNotFound) || errors.Is(err, ErrCacheMiss) {
+		c.root = &RootMetadata{}
+		return c.root, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var root RootMetadata
+	if err := json.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("%w: packages.json: %v", ErrInvalidMetadata, err)
+	}
+
+	c.root = &root
+	return c.root, nil
+}
+
+// GetPackageVersions 获取包的所有正式版本
+//
+// 参数：
+//   - name: 包名，例如 "monolog/monolog"
+//
+// 返回值：
+//   - []Version: 版本列表，顺序与仓库返回的一致（Packagist 为从新到旧）
+//   - error: 如果包不存在则返回 ErrPackageNotFound
+//
+// 功能说明：
+//
+//	对于 v2 仓库，该方法读取 metadata-url 指向的 {vendor}/{package}.json 并展开压缩格式；
+//	对于只提供 providers-lazy-url 或内联 packages 的 v1 仓库，则返回该包的全部版本。
+func (c *Client) GetPackageVersions(name string) ([]Version, error) {
+	return c.getVersions(name, false)
+}
+
+// GetPackageDevVersions 获取包的开发分支版本（v2 仓库中的 ~dev.json）
+func (c *Client) GetPackageDevVersions(name string) ([]Version, error) {
+	return c.getVersions(name, true)
+}
+
+// GetAllPackageVersions 获取包的正式版本和开发分支版本
+//
+// 开发分支元数据不存在时只返回正式版本。
+func (c *Client) GetAllPackageVersions(name string) ([]Version, error) {
+	versions, err := c.GetPackageVersions(name)
+	if err != nil {
+		return nil, err
+	}
+
+	devVersions, err := c.GetPackageDevVersions(name)
+	if err != nil && !errors.Is(err, ErrPackageNotFound) {
+		return nil, err
+	}
+
+	return append(versions, devVersions...), nil
+}
+
+// HasPackage 判断仓库是否声明了该包
+//
+// 仓库的 packages.json 未提供 available-packages 或 available-package-patterns 时，
+// 无法在不请求元数据的情况下判断，此时返回true。
+func (c *Client) HasPackage(name string) (bool, error) {
+	root, err := c.Root()
+	if err != nil {
+		return false, err
+	}
+	return root.declares(strings.ToLower(name)), nil
+}
+
+// getVersions 根据仓库支持的协议获取包的版本
+func (c *Client) getVersions(name string, dev bool) ([]Version, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if !isValidPackageName(name) {
+		return nil, fmt.Errorf("%w: 无效的包名 %q", ErrPackageNotFound, name)
+	}
+
+	root, err := c.Root()
+	if err != nil {
+		return nil, err
+	}
+
+	if !root.declares(name) {
+		return nil, fmt.Errorf("%w: %s", ErrPackageNotFound, name)
+	}
+
+	// v1 内联包
+	if inline, ok := root.Packages[name]; ok {
+		return filterDev(sortedVersions(inline), dev), nil
+	}
+
+	switch {
+	case root.MetadataURL != "":
+		return c.fetchV2(root.MetadataURL, name, dev)
+	case root.ProvidersLazyURL != "":
+		return c.fetchV1Lazy(root.ProvidersLazyURL, name, dev)
+	case root.ProvidersURL != "" && len(root.ProviderIncludes) > 0:
+		return c.fetchV1Provider(root.ProvidersURL, name, dev)
+	case len(root.Packages) > 0:
+		// 仓库只有内联包且不包含该包
+		return nil, fmt.Errorf("%w: %s", ErrPackageNotFound, name)
+	default:
+		return c.fetchV2(defaultMetadataURL, name, dev)
+	}
+}
+
+// fetchV2 读取 v2 元数据文件
+func (c *Client) fetchV2(template string, name string, dev bool) ([]Version, error) {
+	key := name
+	if dev {
+		key += "~dev"
+	}
+
+	target, err := c.resolve(strings.ReplaceAll(template, "%package%", key))
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := c.get(target, v2CacheFile(key))
+	if err != nil {
+		return nil, err
+	}
+
+	var meta packageMetadata
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrInvalidMetadata, key, err)
+	}
+
+	raw, ok := meta.Packages[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrPackageNotFound, name)
+	}
+	if meta.Minified == minifiedFormat {
+		raw = ExpandMinified(raw)
+	}
+
+	return decodeVersions(raw)
+}
+
+// fetchV1Lazy 读取 v1 按需加载的 provider 文件
+func (c *Client) fetchV1Lazy(template string, name string, dev bool) ([]Version, error) {
+	target, err := c.resolve(strings.ReplaceAll(template, "%package%", name))
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := c.get(target, v1CacheFile(name))
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeProvider(data, name, dev)
+}
+
+// decodeProvider 解析 v1 provider 文件中指定包的版本
+func decodeProvider(data []byte, name string, dev bool) ([]Version, error) {
+	var provider struct {
+		Packages map[string]map[string]Version `json:"packages"`
+	}
+	if err := json.Unmarshal(data, &provider); err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrInvalidMetadata, name, err)
+	}
+
+	versions, ok := provider.Packages[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrPackageNotFound, name)
+	}
+
+	return filterDev(sortedVersions(versions), dev), nil
+}
+
+// resolve 将相对地址解析为基于仓库地址的绝对地址
+func (c *Client) resolve(ref string) (string, error) {
+	base, err := url.Parse(c.config.BaseURL + "/")
+	if err != nil {
+		return "", fmt.Errorf("%w: 无效的仓库地址: %v", ErrRequestFailed, err)
+	}
+	target, err := url.Parse(ref)
+	if err != nil {
+		return "", fmt.Errorf("%w: 无效的元数据地址: %v", ErrRequestFailed, err)
+	}
+	return base.ResolveReference(target).String(), nil
+}
+
+// response 表示一次元数据请求的结果
+type response struct {
+	body         []byte
+	lastModified string
+	etag         string
+	notModified  bool
+}
+
+// get 获取元数据，配置了缓存时通过缓存读取
+func (c *Client) get(target string, cacheFile string) ([]byte, error) {
+	if c.cache != nil {
+		return c.cachedGet(target, cacheFile)
+	}
+	if c.config.Offline {
+		return nil, fmt.Errorf("%w: 离线模式需要配置CacheDir: %s", ErrCacheMiss, target)
+	}
+
+	resp, err := c.do(target, nil)
+	if err != nil {
+		return nil, err
+	}
+	return resp.body, nil
+}
+
+// getHashed 获取以内容哈希寻址的元数据，配置了缓存时只使用哈希一致的缓存
+func (c *Client) getHashed(target string, cacheFile string, hash string) ([]byte, error) {
+	if c.cache != nil {
+		return c.cachedGetHashed(target, cacheFile, hash)
+	}
+	return c.get(target, cacheFile)
+}
+
+// do 请求指定地址，304响应会被标记为 notModified
+func (c *Client) do(target string, headers map[string]string) (*response, error) {
+	req, err := http.NewRequest(http.MethodGet, target, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrRequestFailed, err)
+	}
+	c.prepareRequest(req)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrRequestFailed, err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified:
+		return &response{notModified: true}, nil
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, fmt.Errorf("%w: %s", ErrPackageNotFound, target)
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("%w: %s 返回状态码 %d", ErrRequestFailed, target, resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("%w: 读取响应失败: %v", ErrRequestFailed, err)
+	}
+
+	return &response{
+		body:         data,
+		lastModified: resp.Header.Get("Last-Modified"),
+		etag:         resp.Header.Get("ETag"),
+	}, nil
+}
+
+// prepareRequest 为请求设置 User-Agent 和认证信息
+func (c *Client) prepareRequest(req *http.Request) {
+	if c.config.UserAgent != "" {
+		req.Header.Set("User-Agent", c.config.UserAgent)
+	}
+	c.config.Auth.Apply(req)
+}
+
+// declares 判断根元数据是否声明了该包
+func (r *RootMetadata) declares(name string) bool {
+	if len(r.AvailablePackages) == 0 && len(r.AvailablePackagePatterns) == 0 {
+		return true
+	}
+	for _, p := range r.AvailablePackages {
+		if strings.EqualFold(p, name) {
+			return true
+		}
+	}
+	for _, pattern := range r.AvailablePackagePatterns {
+		if matchPackagePattern(pattern, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchPackagePattern 判断包名是否匹配包含 * 通配符的模式
+func matchPackagePattern(pattern string, name string) bool {
+	expr := "^" + strings.ReplaceAll(regexp.QuoteMeta(strings.ToLower(pattern)), `\*`, ".*") + "$"
+	re, err := regexp.Compile(expr)
+	return err == nil && re.MatchString(name)
+}
+
+// isValidPackageName 判断包名是否为 vendor/package 格式
+func isValidPackageName(name string) bool {
+	parts := strings.Split(name, "/")
+	return len(parts) == 2 && parts[0] != "" && parts[1] != ""
+}
+
+// sortedVersions 将版本映射转换为按版本字符串排序的列表
+func sortedVersions(m map[string]Version) []Version {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	versions := make([]Version, 0, len(keys))
+	for _, k := range keys {
+		v := m[k]
+		if v.Version == "" {
+			v.Version = k
+		}
+		versions = append(versions, v)
+	}
+	return versions
+}
+
+// filterDev 按是否为开发分支筛选版本
+func filterDev(versions []Version, dev bool) []Version {
+	filtered := make([]Version, 0, len(versions))
+	for _, v := range versions {
+		if v.IsDev() == dev {
+			filtered = append(filtered, v)
+		}
+	}
+	return filtered
+}
+
+// IsDev 判断版本是否为开发分支（dev-xxx 或 xxx-dev）
+func (v Version) IsDev() bool {
+	return strings.HasPrefix(v.Version, "dev-") || strings.HasSuffix(v.Version, "-dev")
+}
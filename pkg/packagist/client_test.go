@@ -0,0 +1,250 @@
+package packagist
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync/atomic"
+	"testing"
+)
+
+const testMinifiedMetadata = `{
+	"minified": "composer/2.0",
+	"packages": {
+		"acme/logger": [
+			{
+				"name": "acme/logger",
+				"description": "A logger",
+				"version": "2.1.0",
+				"version_normalized": "2.1.0.0",
+				"license": ["MIT"],
+				"dist": {"type": "zip", "url": "https://example.com/acme/logger/2.1.0.zip", "reference": "abc", "shasum": ""},
+				"require": {"php": ">=8.1", "psr/log": "^3.0"},
+				"type": "library"
+			},
+			{
+				"version": "2.0.0",
+				"version_normalized": "2.0.0.0",
+				"dist": {"type": "zip", "url": "https://example.com/acme/logger/2.0.0.zip", "reference": "def", "shasum": ""}
+			},
+			{
+				"version": "1.0.0",
+				"version_normalized": "1.0.0.0",
+				"require": {"php": ">=7.4"},
+				"description": "__unset",
+				"abandoned": "acme/new-logger"
+			}
+		]
+	}
+}`
+
+const testDevMetadata = `{
+	"minified": "composer/2.0",
+	"packages": {
+		"acme/logger": [
+			{"name": "acme/logger", "version": "dev-main", "version_normalized": "dev-main", "require": []}
+		]
+	}
+}`
+
+func newTestServer(t *testing.T, routes map[string]string) (*httptest.Server, *int32) {
+	t.Helper()
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		body, ok := routes[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	return server, &hits
+}
+
+func newTestClient(t *testing.T, baseURL string) *Client {
+	t.Helper()
+	config := DefaultConfig()
+	config.BaseURL = baseURL
+	client, err := NewClient(config)
+	if err != nil {
+		t.Fatalf("创建客户端失败: %v", err)
+	}
+	return client
+}
+
+func TestClientV2Metadata(t *testing.T) {
+	server, _ := newTestServer(t, map[string]string{
+		"/packages.json": `{
+			"packages": [],
+			"metadata-url": "/p2/%package%.json",
+			"available-packages": ["acme/logger"],
+			"available-package-patterns": ["acme-internal/*"]
+		}`,
+		"/p2/acme/logger.json":     testMinifiedMetadata,
+		"/p2/acme/logger~dev.json": testDevMetadata,
+	})
+	client := newTestClient(t, server.URL)
+
+	versions, err := client.GetPackageVersions("Acme/Logger")
+	if err != nil {
+		t.Fatalf("获取版本失败: %v", err)
+	}
+	if len(versions) != 3 {
+		t.Fatalf("应有3个版本，实际为%d", len(versions))
+	}
+
+	v200 := versions[1]
+	if v200.Name != "acme/logger" || v200.Description != "A logger" || v200.Require["psr/log"] != "^3.0" {
+		t.Errorf("压缩格式未正确继承前一个版本的字段: %+v", v200)
+	}
+	if v200.Dist == nil || v200.Dist.Reference != "def" {
+		t.Errorf("覆盖的字段未生效: %+v", v200.Dist)
+	}
+
+	v100 := versions[2]
+	if v100.Description != "" {
+		t.Errorf("__unset 字段应被删除，实际为%q", v100.Description)
+	}
+	if _, ok := v100.Require["psr/log"]; ok {
+		t.Errorf("require 应被整体覆盖: %+v", v100.Require)
+	}
+	if !v100.Abandoned || v100.Replacement != "acme/new-logger" {
+		t.Errorf("abandoned 字段解析不正确: %+v", v100)
+	}
+	if !reflect.DeepEqual([]string(v100.License), []string{"MIT"}) {
+		t.Errorf("license 应继承为[MIT]，实际为%v", v100.License)
+	}
+
+	all, err := client.GetAllPackageVersions("acme/logger")
+	if err != nil {
+		t.Fatalf("获取全部版本失败: %v", err)
+	}
+	if len(all) != 4 || !all[3].IsDev() || all[3].Require == nil {
+		t.Errorf("应包含开发分支版本且空require应为空映射: %+v", all)
+	}
+
+	if _, err := client.GetPackageVersions("other/package"); !errors.Is(err, ErrPackageNotFound) {
+		t.Errorf("未在available-packages中声明的包应返回ErrPackageNotFound，实际为%v", err)
+	}
+
+	ok, err := client.HasPackage("acme-internal/tool")
+	if err != nil || !ok {
+		t.Errorf("匹配available-package-patterns的包应存在: %v %v", ok, err)
+	}
+}
+
+func TestClientDefaultsWithoutRoot(t *testing.T) {
+	server, hits := newTestServer(t, map[string]string{
+		"/p2/acme/logger.json": testMinifiedMetadata,
+	})
+	client := newTestClient(t, server.URL)
+
+	versions, err := client.GetPackageVersions("acme/logger")
+	if err != nil {
+		t.Fatalf("无packages.json时应回退到默认p2地址: %v", err)
+	}
+	if len(versions) != 3 {
+		t.Errorf("应有3个版本，实际为%d", len(versions))
+	}
+
+	if _, err := client.GetPackageDevVersions("acme/logger"); !errors.Is(err, ErrPackageNotFound) {
+		t.Errorf("不存在的~dev文件应返回ErrPackageNotFound，实际为%v", err)
+	}
+
+	all, err := client.GetAllPackageVersions("acme/logger")
+	if err != nil || len(all) != 3 {
+		t.Errorf("缺少开发分支时应只返回正式版本: %d %v", len(all), err)
+	}
+
+	// packages.json 只应请求一次
+	before := atomic.LoadInt32(hits)
+	if _, err := client.GetPackageVersions("acme/logger"); err != nil {
+		t.Fatalf("再次获取版本失败: %v", err)
+	}
+	if after := atomic.LoadInt32(hits); after-before != 1 {
+		t.Errorf("根元数据应被缓存，额外请求了%d次", after-before)
+	}
+
+	if _, err := client.GetPackageVersions("invalid"); !errors.Is(err, ErrPackageNotFound) {
+		t.Errorf("无效的包名应返回ErrPackageNotFound，实际为%v", err)
+	}
+}
+
+func TestClientV1Repositories(t *testing.T) {
+	t.Run("providers-lazy-url", func(t *testing.T) {
+		server, _ := newTestServer(t, map[string]string{
+			"/packages.json": `{"packages": [], "providers-lazy-url": "/p/%package%.json"}`,
+			"/p/acme/logger.json": `{"packages": {"acme/logger": {
+				"1.0.0": {"name": "acme/logger", "version": "1.0.0", "version_normalized": "1.0.0.0"},
+				"dev-main": {"name": "acme/logger", "version": "dev-main", "version_normalized": "dev-main"}
+			}}}`,
+		})
+		client := newTestClient(t, server.URL)
+
+		versions, err := client.GetPackageVersions("acme/logger")
+		if err != nil {
+			t.Fatalf("获取版本失败: %v", err)
+		}
+		if len(versions) != 1 || versions[0].Version != "1.0.0" {
+			t.Errorf("正式版本解析不正确: %+v", versions)
+		}
+
+		dev, err := client.GetPackageDevVersions("acme/logger")
+		if err != nil || len(dev) != 1 || dev[0].Version != "dev-main" {
+			t.Errorf("开发版本解析不正确: %+v %v", dev, err)
+		}
+	})
+
+	t.Run("inline packages", func(t *testing.T) {
+		server, _ := newTestServer(t, map[string]string{
+			"/packages.json": `{"packages": {"acme/logger": {
+				"1.0.0": {"name": "acme/logger", "version": "1.0.0", "license": "MIT"}
+			}}}`,
+		})
+		client := newTestClient(t, server.URL)
+
+		versions, err := client.GetPackageVersions("acme/logger")
+		if err != nil {
+			t.Fatalf("获取版本失败: %v", err)
+		}
+		if len(versions) != 1 || versions[0].License[0] != "MIT" {
+			t.Errorf("内联包解析不正确: %+v", versions)
+		}
+
+		if _, err := client.GetPackageVersions("acme/other"); !errors.Is(err, ErrPackageNotFound) {
+			t.Errorf("未内联的包应返回ErrPackageNotFound，实际为%v", err)
+		}
+	})
+}
+
+func TestClientServerError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	client := newTestClient(t, server.URL)
+	if _, err := client.GetPackageVersions("acme/logger"); !errors.Is(err, ErrRequestFailed) {
+		t.Errorf("服务器错误应返回ErrRequestFailed，实际为%v", err)
+	}
+}
+
+func TestMinifyRoundTrip(t *testing.T) {
+	full := []map[string]interface{}{
+		{"name": "acme/logger", "version": "2.0.0", "description": "A logger"},
+		{"name": "acme/logger", "version": "1.0.0"},
+	}
+
+	minified := MinifyVersions(full)
+	if minified[1]["description"] != unsetMarker || minified[1]["name"] != nil {
+		t.Errorf("压缩结果不正确: %+v", minified[1])
+	}
+
+	if expanded := ExpandMinified(minified); !reflect.DeepEqual(expanded, full) {
+		t.Errorf("压缩后展开应得到原始数据:\n期望 %v\n实际 %v", full, expanded)
+	}
+}
@@ -0,0 +1,121 @@
+package packagist
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrChecksumMismatch 表示下载的分发包与元数据中的 shasum 不一致
+var ErrChecksumMismatch = errors.New("分发包校验和不匹配")
+
+// DownloadDist 下载包的分发包并写入 w
+//
+// 参数：
+//   - dist: 版本元数据中的分发包信息
+//   - w: 写入分发包内容的目标
+//
+// 返回值：
+//   - error: 如果下载失败则返回 ErrRequestFailed，分发包不存在时返回 ErrPackageNotFound
+//
+// 功能说明：
+//
+//	分发包地址可以是绝对地址或相对于仓库地址的路径，请求时会按分发包所在的域名
+//	添加 Config.Auth 中的认证信息，因此可以下载私有仓库或 GitHub 私有项目的分发包。
+func (c *Client) DownloadDist(dist *Dist, w io.Writer) error {
+	if dist == nil || dist.URL == "" {
+		return fmt.Errorf("%w: 分发包地址为空", ErrRequestFailed)
+	}
+	if c.config.Offline {
+		return fmt.Errorf("%w: 离线模式下无法下载分发包 %s", ErrRequestFailed, dist.URL)
+	}
+
+	target, err := c.resolve(dist.URL)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodGet, target, nil)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrRequestFailed, err)
+	}
+	c.prepareRequest(req)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrRequestFailed, err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return fmt.Errorf("%w: %s", ErrPackageNotFound, target)
+	case resp.StatusCode != http.StatusOK:
+		return fmt.Errorf("%w: %s 返回状态码 %d", ErrRequestFailed, target, resp.StatusCode)
+	}
+
+	if _, err := io.Copy(w, resp.Body); err != nil {
+		return fmt.Errorf("%w: 读取分发包失败: %v", ErrRequestFailed, err)
+	}
+	return nil
+}
+
+// DownloadDistFile 下载包的分发包并保存到指定路径
+//
+// 文件先写入同目录下的临时文件，下载完成后再重命名，避免留下不完整的文件。
+// 元数据中提供了 shasum 时会校验文件的 SHA-1，不一致时返回 ErrChecksumMismatch 且不会写入目标文件。
+//
+// 返回值：
+//   - string: 下载文件的 SHA-1 校验和
+//   - error: 如果下载或校验失败，则返回相应的错误信息
+func (c *Client) DownloadDistFile(dist *Dist, destPath string) (string, error) {
+	if err := os.MkdirAll(filepath.Dir(destPath), 0755); err != nil {
+		return "", err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(destPath), ".dist-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+
+	hash := sha1.New()
+	if err := c.DownloadDist(dist, io.MultiWriter(tmp, hash)); err != nil {
+		tmp.Close()
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", err
+	}
+
+	sum := hex.EncodeToString(hash.Sum(nil))
+	if dist.Shasum != "" && !strings.EqualFold(dist.Shasum, sum) {
+		return "", fmt.Errorf("%w: %s 期望 %s，实际为 %s", ErrChecksumMismatch, dist.URL, dist.Shasum, sum)
+	}
+
+	if err := os.Rename(tmp.Name(), destPath); err != nil {
+		return "", err
+	}
+	return sum, nil
+}
+
+// FileSHA1 计算文件的 SHA-1 校验和，格式与元数据中的 shasum 相同
+func FileSHA1(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	hash := sha1.New()
+	if _, err := io.Copy(hash, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
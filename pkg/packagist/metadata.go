@@ -0,0 +1,276 @@
+package packagist
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// unsetMarker 是 Composer 压缩元数据中表示删除字段的特殊值
+const unsetMarker = "__unset"
+
+// minifiedFormat 是 Composer v2 压缩元数据格式的标识
+const minifiedFormat = "composer/2.0"
+
+// RootMetadata 表示仓库根目录下的 packages.json
+type RootMetadata struct {
+	// Packages v1 格式中直接内联的包，键为包名，值为版本到版本信息的映射
+	Packages map[string]map[string]Version `json:"-"`
+	// MetadataURL v2 元数据地址模板，包含 %package% 占位符
+	MetadataURL string `json:"metadata-url,omitempty"`
+	// AvailablePackages 仓库中存在的全部包名，为空表示未声明
+	AvailablePackages []string `json:"available-packages,omitempty"`
+	// AvailablePackagePatterns 仓库中存在的包名模式，支持 * 通配符
+	AvailablePackagePatterns []string `json:"available-package-patterns,omitempty"`
+	// ProvidersLazyURL v1 按需加载的 provider 地址模板
+	ProvidersLazyURL string `json:"providers-lazy-url,omitempty"`
+	// ProvidersURL v1 provider 地址模板，包含 %package% 和 %hash% 占位符
+	ProvidersURL string `json:"providers-url,omitempty"`
+	// ProviderIncludes v1 provider 索引文件
+	ProviderIncludes map[string]ProviderInclude `json:"provider-includes,omitempty"`
+	// Search 搜索接口地址模板
+	Search string `json:"search,omitempty"`
+	// List 包列表接口地址
+	List string `json:"list,omitempty"`
+	// NotifyBatch 安装统计上报地址
+	NotifyBatch string `json:"notify-batch,omitempty"`
+}
+
+// ProviderInclude 表示 provider-includes 中的一个索引文件
+type ProviderInclude struct {
+	SHA256 string `json:"sha256"`
+}
+
+// UnmarshalJSON 解析 packages.json
+//
+// 没有内联包的仓库通常会输出 "packages": []，这里统一处理为空映射。
+func (r *RootMetadata) UnmarshalJSON(data []byte) error {
+	type plain RootMetadata
+	aux := struct {
+		*plain
+		Packages json.RawMessage `json:"packages"`
+	}{plain: (*plain)(r)}
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	if len(aux.Packages) > 0 && aux.Packages[0] == '{' {
+		if err := json.Unmarshal(aux.Packages, &r.Packages); err != nil {
+			return fmt.Errorf("解析packages字段失败: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Source 表示包的源码仓库信息
+type Source struct {
+	Type      string `json:"type"`
+	URL       string `json:"url"`
+	Reference string `json:"reference,omitempty"`
+}
+
+// Dist 表示包的分发包信息
+type Dist struct {
+	Type      string `json:"type"`
+	URL       string `json:"url"`
+	Reference string `json:"reference,omitempty"`
+	Shasum    string `json:"shasum,omitempty"`
+}
+
+// Author 表示包作者
+type Author struct {
+	Name     string `json:"name,omitempty"`
+	Email    string `json:"email,omitempty"`
+	Homepage string `json:"homepage,omitempty"`
+	Role     string `json:"role,omitempty"`
+}
+
+// Links 表示包名到版本约束的映射，用于 require、replace、provide 等字段
+type Links map[string]string
+
+// UnmarshalJSON 解析包链接
+//
+// PHP 会把空关联数组序列化为 []，因此空数组被视为空映射。
+func (l *Links) UnmarshalJSON(data []byte) error {
+	if string(data) == "[]" {
+		*l = Links{}
+		return nil
+	}
+	m := map[string]string{}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return err
+	}
+	*l = m
+	return nil
+}
+
+// StringList 表示可以是单个字符串或字符串数组的字段，例如 license
+type StringList []string
+
+// UnmarshalJSON 解析字符串或字符串数组
+func (s *StringList) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*s = StringList{single}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	*s = list
+	return nil
+}
+
+// Version 表示包的一个版本
+type Version struct {
+	Name              string                 `json:"name"`
+	Description       string                 `json:"description,omitempty"`
+	Keywords          []string               `json:"keywords,omitempty"`
+	Homepage          string                 `json:"homepage,omitempty"`
+	Version           string                 `json:"version"`
+	VersionNormalized string                 `json:"version_normalized,omitempty"`
+	License           StringList             `json:"license,omitempty"`
+	Authors           []Author               `json:"authors,omitempty"`
+	Source            *Source                `json:"source,omitempty"`
+	Dist              *Dist                  `json:"dist,omitempty"`
+	Type              string                 `json:"type,omitempty"`
+	Time              string                 `json:"time,omitempty"`
+	Require           Links                  `json:"require,omitempty"`
+	RequireDev        Links                  `json:"require-dev,omitempty"`
+	Replace           Links                  `json:"replace,omitempty"`
+	Provide           Links                  `json:"provide,omitempty"`
+	Conflict          Links                  `json:"conflict,omitempty"`
+	Suggest           Links                  `json:"suggest,omitempty"`
+	Autoload          json.RawMessage        `json:"autoload,omitempty"`
+	Extra             map[string]interface{} `json:"extra,omitempty"`
+	Support           map[string]string      `json:"support,omitempty"`
+	// Abandoned 表示包是否已被放弃维护
+	Abandoned bool `json:"-"`
+	// Replacement 已放弃的包推荐使用的替代包，可能为空
+	Replacement string `json:"-"`
+}
+
+// UnmarshalJSON 解析版本信息，并将 abandoned 字段拆分为 Abandoned 和 Replacement
+func (v *Version) UnmarshalJSON(data []byte) error {
+	type plain Version
+	aux := struct {
+		*plain
+		Abandoned interface{} `json:"abandoned"`
+	}{plain: (*plain)(v)}
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	switch a := aux.Abandoned.(type) {
+	case bool:
+		v.Abandoned = a
+	case string:
+		v.Abandoned = true
+		v.Replacement = a
+	}
+
+	return nil
+}
+
+// MarshalJSON 序列化版本信息，还原 abandoned 字段
+func (v Version) MarshalJSON() ([]byte, error) {
+	type plain Version
+	aux := struct {
+		plain
+		Abandoned interface{} `json:"abandoned,omitempty"`
+	}{plain: plain(v)}
+
+	if v.Replacement != "" {
+		aux.Abandoned = v.Replacement
+	} else if v.Abandoned {
+		aux.Abandoned = true
+	}
+
+	return json.Marshal(aux)
+}
+
+// packageMetadata 表示 /p2/{vendor}/{package}.json 文件
+type packageMetadata struct {
+	Minified string                              `json:"minified,omitempty"`
+	Packages map[string][]map[string]interface{} `json:"packages"`
+}
+
+// ExpandMinified 展开 Composer v2 压缩格式的版本列表
+//
+// 压缩格式中每个版本只记录与上一个版本不同的字段，值为 "__unset" 表示删除该字段。
+// 返回的每个元素都是包含全部字段的独立副本。
+func ExpandMinified(versions []map[string]interface{}) []map[string]interface{} {
+	expanded := make([]map[string]interface{}, 0, len(versions))
+
+	var previous map[string]interface{}
+	for _, v := range versions {
+		current := make(map[string]interface{}, len(previous)+len(v))
+		for key, value := range previous {
+			current[key] = value
+		}
+		for key, value := range v {
+			if s, ok := value.(string); ok && s == unsetMarker {
+				delete(current, key)
+				continue
+			}
+			current[key] = value
+		}
+		expanded = append(expanded, current)
+		previous = current
+	}
+
+	return expanded
+}
+
+// MinifyVersions 将完整的版本列表压缩为 Composer v2 压缩格式
+//
+// 这是 ExpandMinified 的逆操作，可用于生成静态仓库的 p2 元数据文件。
+func MinifyVersions(versions []map[string]interface{}) []map[string]interface{} {
+	minified := make([]map[string]interface{}, 0, len(versions))
+
+	var previous map[string]interface{}
+	for _, v := range versions {
+		diff := make(map[string]interface{})
+		for key, value := range v {
+			if prev, ok := previous[key]; !ok || !jsonEqual(prev, value) {
+				diff[key] = value
+			}
+		}
+		for key := range previous {
+			if _, ok := v[key]; !ok {
+				diff[key] = unsetMarker
+			}
+		}
+		minified = append(minified, diff)
+		previous = v
+	}
+
+	return minified
+}
+
+// jsonEqual 比较两个JSON值是否相等
+func jsonEqual(a, b interface{}) bool {
+	ja, err1 := json.Marshal(a)
+	jb, err2 := json.Marshal(b)
+	return err1 == nil && err2 == nil && string(ja) == string(jb)
+}
+
+// decodeVersions 将原始版本数据转换为 Version 结构
+func decodeVersions(raw []map[string]interface{}) ([]Version, error) {
+	versions := make([]Version, 0, len(raw))
+	for _, r := range raw {
+		data, err := json.Marshal(r)
+		if err != nil {
+			return nil, err
+		}
+		var v Version
+		if err := json.Unmarshal(data, &v); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidMetadata, err)
+		}
+		versions = append(versions, v)
+	}
+	return versions, nil
+}
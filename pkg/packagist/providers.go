@@ -0,0 +1,87 @@
+package packagist
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// providerIndex 表示 provider-includes 索引文件
+type providerIndex struct {
+	Providers map[string]ProviderInclude `json:"providers"`
+}
+
+// GetProviders 获取 v1 仓库 provider-includes 中声明的全部包
+//
+// 返回值：
+//   - map[string]string: 包名到 provider 文件 sha256 的映射，仓库未提供 provider-includes 时为空
+//   - error: 如果请求或解析索引文件失败，则返回相应的错误信息
+//
+// 功能说明：
+//
+//	索引文件地址中的 %hash% 会被替换为 packages.json 中记录的 sha256。
+//	与元数据请求一样，索引文件的请求会按域名带上 Config.Auth 中的认证信息。
+func (c *Client) GetProviders() (map[string]string, error) {
+	c.providersMu.Lock()
+	defer c.providersMu.Unlock()
+
+	if c.providers != nil {
+		return c.providers, nil
+	}
+
+	root, err := c.Root()
+	if err != nil {
+		return nil, err
+	}
+
+	providers := make(map[string]string)
+	for include, info := range root.ProviderIncludes {
+		target, err := c.resolve(strings.ReplaceAll(include, "%hash%", info.SHA256))
+		if err != nil {
+			return nil, err
+		}
+
+		data, err := c.getHashed(target, "provider-"+path.Base(strings.ReplaceAll(include, "%hash%", "")), info.SHA256)
+		if err != nil {
+			return nil, err
+		}
+
+		var index providerIndex
+		if err := json.Unmarshal(data, &index); err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", ErrInvalidMetadata, include, err)
+		}
+		for name, p := range index.Providers {
+			providers[strings.ToLower(name)] = p.SHA256
+		}
+	}
+
+	c.providers = providers
+	return c.providers, nil
+}
+
+// fetchV1Provider 通过 providers-url 和 provider-includes 中的哈希读取 v1 provider 文件
+func (c *Client) fetchV1Provider(template string, name string, dev bool) ([]Version, error) {
+	providers, err := c.GetProviders()
+	if err != nil {
+		return nil, err
+	}
+
+	hash, ok := providers[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrPackageNotFound, name)
+	}
+
+	ref := strings.ReplaceAll(template, "%package%", name)
+	target, err := c.resolve(strings.ReplaceAll(ref, "%hash%", hash))
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := c.getHashed(target, v1CacheFile(name), hash)
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeProvider(data, name, dev)
+}
@@ -0,0 +1,457 @@
+package resolver
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	orSeparatorPattern   = regexp.MustCompile(`\s*\|\|?\s*`)
+	andSeparatorPattern  = regexp.MustCompile(`\s*,\s*`)
+	operatorSpacePattern = regexp.MustCompile(`(<>|!=|>=?|<=?|==?)\s+`)
+	wildcardAnyPattern   = regexp.MustCompile(`^v?[xX*](\.[xX*])*$`)
+	wildcardPattern      = regexp.MustCompile(`^v?(\d+)(?:\.(\d+))?(?:\.(\d+))?(?:\.[xX*])+$`)
+	operatorPattern      = regexp.MustCompile(`^(<>|!=|>=?|<=?|==?)?\s*(.*)$`)
+
+	constraintVersion = `v?(\d+)(?:\.(\d+))?(?:\.(\d+))?(?:\.(\d+))?` + modifierPattern + `(?:\+\S+)?`
+	caretPattern      = regexp.MustCompile(`(?i)^\^` + constraintVersion + `$`)
+	tildePattern      = regexp.MustCompile(`(?i)^~` + constraintVersion + `$`)
+	hyphenPattern     = regexp.MustCompile(`(?i)^(` + constraintVersion + `) +- +(` + constraintVersion + `)$`)
+)
+
+// bound 表示区间的一个端点，version 为nil时表示无穷
+type bound struct {
+	version   *Version
+	inclusive bool
+}
+
+// interval 表示数字版本的一个区间
+type interval struct {
+	low  bound
+	high bound
+}
+
+// contains 判断区间是否包含版本
+func (i interval) contains(v *Version) bool {
+	if i.low.version != nil {
+		c := v.Compare(i.low.version)
+		if c < 0 || (c == 0 && !i.low.inclusive) {
+			return false
+		}
+	}
+	if i.high.version != nil {
+		c := v.Compare(i.high.version)
+		if c > 0 || (c == 0 && !i.high.inclusive) {
+			return false
+		}
+	}
+	return true
+}
+
+// intersect 计算两个区间的交集
+func (i interval) intersect(o interval) (interval, bool) {
+	result := interval{low: i.low, high: i.high}
+
+	if o.low.version != nil {
+		if result.low.version == nil {
+			result.low = o.low
+		} else if c := o.low.version.Compare(result.low.version); c > 0 {
+			result.low = o.low
+		} else if c == 0 {
+			result.low.inclusive = result.low.inclusive && o.low.inclusive
+		}
+	}
+	if o.high.version != nil {
+		if result.high.version == nil {
+			result.high = o.high
+		} else if c := o.high.version.Compare(result.high.version); c < 0 {
+			result.high = o.high
+		} else if c == 0 {
+			result.high.inclusive = result.high.inclusive && o.high.inclusive
+		}
+	}
+
+	if result.low.version != nil && result.high.version != nil {
+		c := result.low.version.Compare(result.high.version)
+		if c > 0 || (c == 0 && !(result.low.inclusive && result.high.inclusive)) {
+			return interval{}, false
+		}
+	}
+	return result, true
+}
+
+// Constraint 表示 Composer 的版本约束，例如 "^1.2"、">=2.0 <3.0 || dev-main"
+//
+// 数字版本的约束被表示为若干区间的并集，分支版本只能通过 dev-xxx 精确匹配。
+type Constraint struct {
+	raw       string
+	intervals []interval
+	branches  map[string]bool
+	anyBranch bool
+	stability Stability
+}
+
+// ParseConstraint 按照 Composer 的语法解析版本约束
+//
+// 支持 *、精确版本、比较运算符、^、~、1.2.* 通配符、"1.0 - 2.0" 范围、
+// 逗号或空格表示的"与"、| 或 || 表示的"或"，以及 @dev 等稳定性标记。
+//
+// 参数：
+//   - constraint: 版本约束字符串
+//
+// 返回值：
+//   - *Constraint: 解析后的约束
+//   - error: 如果约束无法解析，则返回 ErrInvalidConstraint
+func ParseConstraint(constraint string) (*Constraint, error) {
+	raw := strings.TrimSpace(constraint)
+	s := raw
+	if i := strings.Index(strings.ToLower(s), " as "); i >= 0 {
+		s = strings.TrimSpace(s[:i])
+	}
+	if s == "" {
+		return nil, fmt.Errorf("%w: 约束为空", ErrInvalidConstraint)
+	}
+
+	result := &Constraint{raw: raw}
+	for _, part := range orSeparatorPattern.Split(s, -1) {
+		c, err := parseAndConstraint(part)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q: %v", ErrInvalidConstraint, raw, err)
+		}
+		result.union(c)
+	}
+	return result, nil
+}
+
+// MustParseConstraint 解析版本约束，失败时 panic，用于常量约束
+func MustParseConstraint(constraint string) *Constraint {
+	c, err := ParseConstraint(constraint)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+// String 返回原始的约束字符串
+func (c *Constraint) String() string {
+	return c.raw
+}
+
+// Stability 返回约束中显式指定的最不稳定的稳定性，例如 "^2.0@beta" 返回 beta
+//
+// 约束未引用非稳定版本时返回空字符串。
+func (c *Constraint) Stability() Stability {
+	return c.stability
+}
+
+// Matches 判断版本是否满足约束
+func (c *Constraint) Matches(v *Version) bool {
+	if v.branch != "" {
+		return c.anyBranch || c.branches[v.branch]
+	}
+	for _, i := range c.intervals {
+		if i.contains(v) {
+			return true
+		}
+	}
+	return false
+}
+
+// Intersects 判断两个约束是否存在同时满足的版本
+func (c *Constraint) Intersects(other *Constraint) bool {
+	return !c.intersect(other).empty()
+}
+
+// empty 判断约束是否不匹配任何版本
+func (c *Constraint) empty() bool {
+	return len(c.intervals) == 0 && len(c.branches) == 0 && !c.anyBranch
+}
+
+// union 将另一个约束并入当前约束
+func (c *Constraint) union(other *Constraint) {
+	c.intervals = append(c.intervals, other.intervals...)
+	c.anyBranch = c.anyBranch || other.anyBranch
+	for b := range other.branches {
+		if c.branches == nil {
+			c.branches = map[string]bool{}
+		}
+		c.branches[b] = true
+	}
+	if other.stability != "" {
+		c.stability = LessStable(stabilityOrStable(c.stability), other.stability)
+	}
+}
+
+// intersect 计算两个约束的交集
+func (c *Constraint) intersect(other *Constraint) *Constraint {
+	result := &Constraint{raw: c.raw + " " + other.raw}
+	for _, a := range c.intervals {
+		for _, b := range other.intervals {
+			if i, ok := a.intersect(b); ok {
+				result.intervals = append(result.intervals, i)
+			}
+		}
+	}
+
+	result.anyBranch = c.anyBranch && other.anyBranch
+	addBranches := func(from *Constraint, filter *Constraint) {
+		for b := range from.branches {
+			if filter.anyBranch || filter.branches[b] {
+				if result.branches == nil {
+					result.branches = map[string]bool{}
+				}
+				result.branches[b] = true
+			}
+		}
+	}
+	addBranches(c, other)
+	addBranches(other, c)
+
+	if c.stability != "" || other.stability != "" {
+		result.stability = LessStable(stabilityOrStable(c.stability), stabilityOrStable(other.stability))
+	}
+	return result
+}
+
+// parseAndConstraint 解析不包含"或"的约束
+func parseAndConstraint(s string) (*Constraint, error) {
+	s = strings.TrimSpace(s)
+
+	if m := hyphenPattern.FindStringSubmatch(s); m != nil {
+		return parseHyphenRange(m)
+	}
+
+	s = andSeparatorPattern.ReplaceAllString(s, " ")
+	s = operatorSpacePattern.ReplaceAllString(s, "$1")
+
+	var result *Constraint
+	for _, token := range strings.Fields(s) {
+		c, err := parseSingleConstraint(token)
+		if err != nil {
+			return nil, err
+		}
+		if result == nil {
+			result = c
+		} else {
+			result = result.intersect(c)
+		}
+	}
+	if result == nil {
+		return nil, fmt.Errorf("约束为空")
+	}
+	return result, nil
+}
+
+// parseSingleConstraint 解析单个约束，例如 ">=1.0"、"^2.3"、"1.2.*"
+func parseSingleConstraint(token string) (*Constraint, error) {
+	var flag Stability
+	if i := strings.LastIndex(token, "@"); i >= 0 {
+		stability, ok := ParseStability(token[i+1:])
+		if !ok {
+			return nil, fmt.Errorf("无效的稳定性标记 %q", token[i+1:])
+		}
+		flag = stability
+		token = token[:i]
+	}
+	if i := strings.Index(token, "#"); i >= 0 {
+		token = token[:i]
+	}
+
+	c, err := parseConstraintToken(token)
+	if err != nil {
+		return nil, err
+	}
+	c.raw = token
+	if flag != "" {
+		c.stability = flag
+	}
+	return c, nil
+}
+
+// parseConstraintToken 解析去掉稳定性标记后的单个约束
+func parseConstraintToken(token string) (*Constraint, error) {
+	if token == "" || wildcardAnyPattern.MatchString(token) {
+		return anyConstraint(), nil
+	}
+
+	if m := caretPattern.FindStringSubmatch(token); m != nil {
+		position := 3
+		switch {
+		case m[1] != "0" || m[2] == "":
+			position = 1
+		case m[2] != "0" || m[3] == "":
+			position = 2
+		}
+		return rangeFromMatch(token[1:], m, position)
+	}
+
+	if m := tildePattern.FindStringSubmatch(token); m != nil {
+		position := 1
+		switch {
+		case m[4] != "":
+			position = 4
+		case m[3] != "":
+			position = 3
+		case m[2] != "":
+			position = 2
+		}
+		if position > 1 {
+			position--
+		}
+		return rangeFromMatch(token[1:], m, position)
+	}
+
+	if m := wildcardPattern.FindStringSubmatch(token); m != nil {
+		position := 1
+		switch {
+		case m[3] != "":
+			position = 3
+		case m[2] != "":
+			position = 2
+		}
+		low := manipulateVersion(m[1:4], position, 0)
+		high := manipulateVersion(m[1:4], position, 1)
+		c := &Constraint{intervals: []interval{{high: bound{version: high}}}}
+		if low.Normalized() != "0.0.0.0-dev" {
+			c.intervals[0].low = bound{version: low, inclusive: true}
+		}
+		return c, nil
+	}
+
+	m := operatorPattern.FindStringSubmatch(token)
+	op, versionText := m[1], m[2]
+	v, err := ParseVersion(versionText)
+	if err != nil {
+		return nil, err
+	}
+	stability := v.Stability()
+	if (op == "<" || op == ">=") && v.branch == "" && stability == StabilityStable && v.modifier != modifierPatch {
+		v = v.withDevSuffix()
+	}
+
+	c := operatorConstraint(op, v)
+	if stability != StabilityStable && op != "<" && op != "<=" {
+		c.stability = stability
+	}
+	return c, nil
+}
+
+// rangeFromMatch 根据 ^ 或 ~ 约束构造区间
+func rangeFromMatch(versionText string, m []string, position int) (*Constraint, error) {
+	low, err := ParseVersion(versionText)
+	if err != nil {
+		return nil, err
+	}
+	if m[5] == "" && m[7] == "" {
+		low = low.withDevSuffix()
+	}
+
+	high := manipulateVersion(m[1:5], position, 1)
+	c := &Constraint{intervals: []interval{{
+		low:  bound{version: low, inclusive: true},
+		high: bound{version: high},
+	}}}
+	if m[5] != "" || m[7] != "" {
+		c.stability = low.Stability()
+	}
+	return c, nil
+}
+
+// parseHyphenRange 解析 "1.0 - 2.0" 形式的范围约束
+func parseHyphenRange(m []string) (*Constraint, error) {
+	// 每个版本子表达式包含1个外层分组和7个内部分组
+	from, to := m[1], m[9]
+	fromGroups, toGroups := m[2:9], m[10:17]
+
+	low, err := ParseVersion(from)
+	if err != nil {
+		return nil, err
+	}
+	if fromGroups[4] == "" && fromGroups[6] == "" {
+		low = low.withDevSuffix()
+	}
+
+	var high bound
+	if (toGroups[1] != "" && toGroups[2] != "") || toGroups[4] != "" || toGroups[6] != "" {
+		v, err := ParseVersion(to)
+		if err != nil {
+			return nil, err
+		}
+		high = bound{version: v, inclusive: true}
+	} else {
+		position := 2
+		if toGroups[1] == "" {
+			position = 1
+		}
+		high = bound{version: manipulateVersion(toGroups[0:4], position, 1)}
+	}
+
+	return &Constraint{intervals: []interval{{low: bound{version: low, inclusive: true}, high: high}}}, nil
+}
+
+// manipulateVersion 将版本第 position 段加上 increment，并把之后的段置零，结果带 -dev 后缀
+func manipulateVersion(parts []string, position int, increment int) *Version {
+	v := &Version{modifier: modifierStable, devSuffix: true}
+	for i := 0; i < 4 && i < len(parts); i++ {
+		v.parts[i], _ = strconv.Atoi(parts[i])
+	}
+	for i := position; i < 4; i++ {
+		v.parts[i] = 0
+	}
+	v.parts[position-1] += increment
+	return v
+}
+
+// operatorConstraint 根据比较运算符构造约束
+func operatorConstraint(op string, v *Version) *Constraint {
+	if v.branch != "" {
+		switch op {
+		case "", "=", "==":
+			return &Constraint{branches: map[string]bool{v.branch: true}, stability: StabilityDev}
+		case "!=", "<>":
+			return anyConstraint()
+		}
+		return &Constraint{}
+	}
+
+	switch op {
+	case "<":
+		return &Constraint{intervals: []interval{{high: bound{version: v}}}}
+	case "<=":
+		return &Constraint{intervals: []interval{{high: bound{version: v, inclusive: true}}}}
+	case ">":
+		return &Constraint{intervals: []interval{{low: bound{version: v}}}}
+	case ">=":
+		return &Constraint{intervals: []interval{{low: bound{version: v, inclusive: true}}}}
+	case "!=", "<>":
+		return &Constraint{
+			intervals: []interval{{high: bound{version: v}}, {low: bound{version: v}}},
+			anyBranch: true,
+		}
+	}
+	return &Constraint{intervals: []interval{{
+		low:  bound{version: v, inclusive: true},
+		high: bound{version: v, inclusive: true},
+	}}}
+}
+
+// anyConstraint 返回匹配任意版本的约束
+func anyConstraint() *Constraint {
+	return &Constraint{raw: "*", intervals: []interval{{}}, anyBranch: true}
+}
+
+// exactConstraint 返回只匹配指定版本的约束
+func exactConstraint(v *Version) *Constraint {
+	c := operatorConstraint("==", v)
+	c.raw = v.String()
+	return c
+}
+
+func stabilityOrStable(s Stability) Stability {
+	if s == "" {
+		return StabilityStable
+	}
+	return s
+}
@@ -0,0 +1,145 @@
+package resolver
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/scagogogo/go-composer-sdk/pkg/packagist"
+)
+
+// Root 表示解析使用的根包，即项目的 composer.json
+type Root struct {
+	Name             string            `json:"name,omitempty"`
+	Version          string            `json:"version,omitempty"`
+	Require          map[string]string `json:"require,omitempty"`
+	RequireDev       map[string]string `json:"require-dev,omitempty"`
+	Replace          map[string]string `json:"replace,omitempty"`
+	Provide          map[string]string `json:"provide,omitempty"`
+	Conflict         map[string]string `json:"conflict,omitempty"`
+	MinimumStability string            `json:"minimum-stability,omitempty"`
+	PreferStable     bool              `json:"prefer-stable,omitempty"`
+	// Platform 对应 config.platform，用于覆盖平台包的版本
+	Platform map[string]string `json:"-"`
+}
+
+// LoadRoot 读取 composer.json 作为解析的根包
+//
+// 参数：
+//   - path: composer.json 文件路径
+//
+// 返回值：
+//   - *Root: 根包
+//   - error: 如果读取或解析失败，则返回相应的错误信息
+func LoadRoot(path string) (*Root, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var root Root
+	if err := json.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("解析 %s 失败: %w", path, err)
+	}
+
+	var config struct {
+		Config struct {
+			Platform map[string]interface{} `json:"platform"`
+		} `json:"config"`
+	}
+	if err := json.Unmarshal(data, &config); err == nil {
+		root.Platform = PlatformOverrides(config.Config.Platform)
+	}
+
+	return &root, nil
+}
+
+// PlatformOverrides 将 composer.json 中 config.platform 的值转换为平台包版本
+//
+// 值为 false 表示禁用该平台包，转换时会被忽略。
+func PlatformOverrides(platform map[string]interface{}) map[string]string {
+	if len(platform) == 0 {
+		return nil
+	}
+	result := make(map[string]string, len(platform))
+	for name, value := range platform {
+		if s, ok := value.(string); ok {
+			result[strings.ToLower(name)] = s
+		}
+	}
+	return result
+}
+
+// LockFile 表示 composer.lock 文件
+type LockFile struct {
+	ContentHash      string              `json:"content-hash,omitempty"`
+	Packages         []packagist.Version `json:"packages"`
+	PackagesDev      []packagist.Version `json:"packages-dev"`
+	MinimumStability string              `json:"minimum-stability,omitempty"`
+	StabilityFlags   StabilityFlags      `json:"stability-flags,omitempty"`
+	PreferStable     bool                `json:"prefer-stable"`
+	PreferLowest     bool                `json:"prefer-lowest"`
+	Platform         packagist.Links     `json:"platform,omitempty"`
+	PlatformDev      packagist.Links     `json:"platform-dev,omitempty"`
+}
+
+// StabilityFlags 表示锁文件中包名到稳定性标记的映射
+type StabilityFlags map[string]int
+
+// UnmarshalJSON 解析稳定性标记
+//
+// 没有任何标记时 Composer 会写入 []，因此空数组被视为空映射。
+func (f *StabilityFlags) UnmarshalJSON(data []byte) error {
+	if string(data) == "[]" {
+		*f = StabilityFlags{}
+		return nil
+	}
+	m := map[string]int{}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return err
+	}
+	*f = m
+	return nil
+}
+
+// LoadLockFile 读取 composer.lock 文件
+//
+// 参数：
+//   - path: composer.lock 文件路径
+//
+// 返回值：
+//   - *LockFile: 锁文件内容
+//   - error: 如果读取或解析失败，则返回相应的错误信息；文件不存在时可用 os.IsNotExist 判断
+func LoadLockFile(path string) (*LockFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var lock LockFile
+	if err := json.Unmarshal(data, &lock); err != nil {
+		return nil, fmt.Errorf("解析 %s 失败: %w", path, err)
+	}
+	return &lock, nil
+}
+
+// AllPackages 返回锁文件中的全部包，包括开发依赖
+func (l *LockFile) AllPackages() []packagist.Version {
+	if l == nil {
+		return nil
+	}
+	all := make([]packagist.Version, 0, len(l.Packages)+len(l.PackagesDev))
+	all = append(all, l.Packages...)
+	return append(all, l.PackagesDev...)
+}
+
+// Find 按包名查找锁定的版本
+func (l *LockFile) Find(name string) (packagist.Version, bool) {
+	for _, p := range l.AllPackages() {
+		if strings.EqualFold(p.Name, name) {
+			return p, true
+		}
+	}
+	return packagist.Version{}, false
+}
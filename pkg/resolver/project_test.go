@@ -0,0 +1,47 @@
+package resolver
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoadLockFile(t *testing.T) {
+	// 由 Composer 生成的锁文件，没有内容的映射字段被写为 []
+	lock, err := LoadLockFile(filepath.Join("testdata", "composer.lock"))
+	if err != nil {
+		t.Fatalf("读取锁文件失败: %v", err)
+	}
+	if len(lock.Packages) != 1 || lock.Packages[0].Name != "psr/log" || lock.Packages[0].Version != "3.0.0" {
+		t.Errorf("packages 解析错误: %+v", lock.Packages)
+	}
+	if len(lock.StabilityFlags) != 0 || len(lock.Platform) != 0 || len(lock.PlatformDev) != 0 {
+		t.Errorf("空数组应解析为空映射: %v %v %v", lock.StabilityFlags, lock.Platform, lock.PlatformDev)
+	}
+	if _, ok := lock.Find("PSR/Log"); !ok {
+		t.Error("应能按包名找到锁定的版本")
+	}
+
+	path := filepath.Join(t.TempDir(), "composer.lock")
+	content := `{
+		"packages": [],
+		"packages-dev": [],
+		"stability-flags": {"acme/lib": 20},
+		"platform": {"php": "^8.1", "ext-json": "*"},
+		"platform-dev": {"ext-xdebug": "*"}
+	}`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("写入锁文件失败: %v", err)
+	}
+	lock, err = LoadLockFile(path)
+	if err != nil {
+		t.Fatalf("读取锁文件失败: %v", err)
+	}
+	if !reflect.DeepEqual(lock.StabilityFlags, StabilityFlags{"acme/lib": 20}) {
+		t.Errorf("stability-flags 解析错误: %v", lock.StabilityFlags)
+	}
+	if lock.Platform["php"] != "^8.1" || lock.PlatformDev["ext-xdebug"] != "*" {
+		t.Errorf("platform 解析错误: %v %v", lock.Platform, lock.PlatformDev)
+	}
+}
@@ -0,0 +1,274 @@
+package resolver
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/scagogogo/go-composer-sdk/pkg/packagist"
+)
+
+// Repository 是解析器读取包元数据的来源
+type Repository interface {
+	// FindPackages 返回包的全部版本，包不存在时返回空列表
+	FindPackages(name string) ([]packagist.Version, error)
+}
+
+// ProviderRepository 是能够查找 replace/provide 了某个包名的仓库
+//
+// 实现了该接口的仓库可以让解析器用替代包满足依赖，例如由 monolog/monolog 提供的 psr/log-implementation 虚拟包。
+type ProviderRepository interface {
+	Repository
+	// WhatProvides 返回在 replace 或 provide 中声明了该包名的所有版本
+	WhatProvides(name string) ([]packagist.Version, error)
+}
+
+// StaticRepository 是内存中的仓库，适用于本地 Composer 仓库目录或测试数据
+type StaticRepository struct {
+	packages map[string][]packagist.Version
+}
+
+// NewStaticRepository 使用给定的版本创建内存仓库
+//
+// 用法示例：
+//
+//	repo := resolver.NewStaticRepository(
+//	    packagist.Version{Name: "psr/log", Version: "3.0.0"},
+//	    packagist.Version{Name: "psr/log", Version: "2.0.0"},
+//	)
+func NewStaticRepository(versions ...packagist.Version) *StaticRepository {
+	repo := &StaticRepository{packages: make(map[string][]packagist.Version)}
+	repo.Add(versions...)
+	return repo
+}
+
+// Add 向仓库添加版本
+func (r *StaticRepository) Add(versions ...packagist.Version) {
+	for _, v := range versions {
+		name := strings.ToLower(v.Name)
+		r.packages[name] = append(r.packages[name], v)
+	}
+}
+
+// PackageNames 返回仓库中的全部包名
+func (r *StaticRepository) PackageNames() []string {
+	names := make([]string, 0, len(r.packages))
+	for name := range r.packages {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// FindPackages 返回包的全部版本
+func (r *StaticRepository) FindPackages(name string) ([]packagist.Version, error) {
+	return r.packages[strings.ToLower(name)], nil
+}
+
+// WhatProvides 返回在 replace 或 provide 中声明了该包名的所有版本
+func (r *StaticRepository) WhatProvides(name string) ([]packagist.Version, error) {
+	name = strings.ToLower(name)
+
+	var result []packagist.Version
+	for _, pkgName := range r.PackageNames() {
+		for _, v := range r.packages[pkgName] {
+			if hasLink(v.Replace, name) || hasLink(v.Provide, name) {
+				result = append(result, v)
+			}
+		}
+	}
+	return result, nil
+}
+
+// LoadRepository 从本地 Composer 仓库或测试数据文件加载内存仓库
+//
+// 参数：
+//   - path: 仓库目录或JSON文件路径
+//
+// 返回值：
+//   - *StaticRepository: 加载的仓库
+//   - error: 如果读取或解析失败，则返回相应的错误信息
+//
+// 功能说明：
+//
+//	path 为目录时，读取其中 packages.json 内联的包以及 p2/ 下的全部元数据文件（支持压缩格式），
+//	即 Satis 或 composer/satis 兼容工具生成的静态仓库布局。
+//	path 为文件时，支持以下格式：
+//	  - packages.json 格式：{"packages": {"vendor/name": {"1.0.0": {...}}}}
+//	  - p2 元数据格式：{"packages": {"vendor/name": [{...}, ...]}}
+//	  - 版本数组：[{"name": "vendor/name", "version": "1.0.0", ...}, ...]
+func LoadRepository(path string) (*StaticRepository, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	repo := NewStaticRepository()
+	if !info.IsDir() {
+		if err := repo.loadFile(path); err != nil {
+			return nil, err
+		}
+		return repo, nil
+	}
+
+	rootFile := filepath.Join(path, "packages.json")
+	if _, err := os.Stat(rootFile); err == nil {
+		if err := repo.loadFile(rootFile); err != nil {
+			return nil, err
+		}
+	}
+
+	err = filepath.Walk(filepath.Join(path, "p2"), func(file string, info os.FileInfo, err error) error {
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if info.IsDir() || filepath.Ext(file) != ".json" {
+			return nil
+		}
+		return repo.loadFile(file)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return repo, nil
+}
+
+// loadFile 读取单个仓库元数据文件
+func (r *StaticRepository) loadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	versions, err := decodeRepositoryFile(data)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	r.Add(versions...)
+	return nil
+}
+
+// decodeRepositoryFile 解析仓库元数据文件中的版本
+func decodeRepositoryFile(data []byte) ([]packagist.Version, error) {
+	trimmed := strings.TrimSpace(string(data))
+	if strings.HasPrefix(trimmed, "[") {
+		var versions []packagist.Version
+		if err := json.Unmarshal(data, &versions); err != nil {
+			return nil, err
+		}
+		return versions, nil
+	}
+
+	var file struct {
+		Minified string                     `json:"minified"`
+		Packages map[string]json.RawMessage `json:"packages"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(file.Packages))
+	for name := range file.Packages {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var versions []packagist.Version
+	for _, name := range names {
+		raw := file.Packages[name]
+		if strings.HasPrefix(strings.TrimSpace(string(raw)), "[") {
+			var list []map[string]interface{}
+			if err := json.Unmarshal(raw, &list); err != nil {
+				return nil, err
+			}
+			if file.Minified != "" {
+				list = packagist.ExpandMinified(list)
+			}
+			for _, item := range list {
+				v, err := decodeVersion(item)
+				if err != nil {
+					return nil, err
+				}
+				if v.Name == "" {
+					v.Name = name
+				}
+				versions = append(versions, v)
+			}
+			continue
+		}
+
+		var byVersion map[string]packagist.Version
+		if err := json.Unmarshal(raw, &byVersion); err != nil {
+			return nil, err
+		}
+		keys := make([]string, 0, len(byVersion))
+		for k := range byVersion {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			v := byVersion[k]
+			if v.Name == "" {
+				v.Name = name
+			}
+			if v.Version == "" {
+				v.Version = k
+			}
+			versions = append(versions, v)
+		}
+	}
+	return versions, nil
+}
+
+// decodeVersion 将通用的JSON对象转换为版本信息
+func decodeVersion(item map[string]interface{}) (packagist.Version, error) {
+	var v packagist.Version
+	data, err := json.Marshal(item)
+	if err != nil {
+		return v, err
+	}
+	err = json.Unmarshal(data, &v)
+	return v, err
+}
+
+// ClientRepository 将 packagist.Client 适配为解析器使用的仓库
+type ClientRepository struct {
+	client *packagist.Client
+}
+
+// NewClientRepository 创建基于仓库客户端的 Repository
+//
+// 用法示例：
+//
+//	client, _ := packagist.NewClient(packagist.DefaultConfig())
+//	repo := resolver.NewClientRepository(client)
+func NewClientRepository(client *packagist.Client) *ClientRepository {
+	return &ClientRepository{client: client}
+}
+
+// FindPackages 返回包的正式版本和开发分支版本
+func (r *ClientRepository) FindPackages(name string) ([]packagist.Version, error) {
+	versions, err := r.client.GetAllPackageVersions(name)
+	if errors.Is(err, packagist.ErrPackageNotFound) {
+		return nil, nil
+	}
+	return versions, err
+}
+
+// hasLink 判断链接中是否包含指定包名
+func hasLink(links packagist.Links, name string) bool {
+	for k := range links {
+		if strings.EqualFold(k, name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,948 @@
+// Package resolver 实现了纯 Go 的 Composer 依赖解析器，
+// 可以在不运行 PHP 的情况下预览 composer update 将要执行的操作。
+//
+// 解析器支持版本约束、replace、provide、conflict、minimum-stability 和 prefer-stable，
+// 使用带回溯的深度优先搜索，结果不保证在所有边界情况下与 Composer 的 SAT 求解器一致，
+// 但在无法解析时会给出导致失败的约束说明。
+package resolver
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/scagogogo/go-composer-sdk/pkg/packagist"
+)
+
+// 常见错误
+var (
+	// ErrInvalidVersion 表示版本号无法解析
+	ErrInvalidVersion = errors.New("无效的版本号")
+	// ErrInvalidConstraint 表示版本约束无法解析
+	ErrInvalidConstraint = errors.New("无效的版本约束")
+	// ErrUnresolvable 表示不存在满足全部约束的包组合
+	ErrUnresolvable = errors.New("无法解析依赖关系")
+	// ErrTooComplex 表示解析超过了最大尝试次数
+	ErrTooComplex = errors.New("依赖解析超过最大尝试次数")
+)
+
+// DefaultMaxSteps 是默认的最大尝试次数
+const DefaultMaxSteps = 100000
+
+// rootPackageName 是未设置 name 的根包在说明中使用的名称
+const rootPackageName = "__root__"
+
+// platformPackagePattern 与 Composer PlatformRepository::PLATFORM_PACKAGE_REGEX 一致
+var platformPackagePattern = regexp.MustCompile(`(?i)^(?:php(?:-64bit|-ipv6|-zts|-debug)?|hhvm|(?:ext|lib)-[a-z0-9](?:[_.-]?[a-z0-9]+)*|composer(?:-(?:plugin|runtime)-api)?)$`)
+
+// IsPlatformPackage 判断包名是否为 php、ext-*、lib-* 等平台包
+func IsPlatformPackage(name string) bool {
+	return platformPackagePattern.MatchString(name)
+}
+
+// Options 保存解析选项
+type Options struct {
+	// 平台包版本，例如 {"php": "8.2.10", "ext-json": "8.2.10"}，会被 composer.json 的 config.platform 覆盖
+	Platform map[string]string
+	// 忽略全部平台需求
+	IgnorePlatformReqs bool
+	// 不解析 require-dev
+	NoDev bool
+	// 只更新指定的包，支持 * 通配符；为空表示全部更新，其余包保持锁定的版本
+	Packages []string
+	// 同时更新指定包在锁文件中的依赖
+	WithDependencies bool
+	// 最大尝试次数，为0时使用 DefaultMaxSteps
+	MaxSteps int
+}
+
+// OperationType 表示计划执行的操作类型
+type OperationType string
+
+// 操作类型
+const (
+	OperationInstall   OperationType = "install"
+	OperationUpgrade   OperationType = "upgrade"
+	OperationDowngrade OperationType = "downgrade"
+	OperationUpdate    OperationType = "update"
+	OperationRemove    OperationType = "remove"
+)
+
+// Operation 表示一项计划执行的操作
+type Operation struct {
+	Type    OperationType `json:"type"`
+	Package string        `json:"package"`
+	// From 锁文件中的版本，安装操作时为空
+	From string `json:"from,omitempty"`
+	// To 解析得到的版本，删除操作时为空
+	To string `json:"to,omitempty"`
+}
+
+// String 返回与 Composer 输出类似的操作说明
+func (o Operation) String() string {
+	switch o.Type {
+	case OperationInstall:
+		return fmt.Sprintf("Installing %s (%s)", o.Package, o.To)
+	case OperationRemove:
+		return fmt.Sprintf("Removing %s (%s)", o.Package, o.From)
+	case OperationDowngrade:
+		return fmt.Sprintf("Downgrading %s (%s => %s)", o.Package, o.From, o.To)
+	case OperationUpgrade:
+		return fmt.Sprintf("Upgrading %s (%s => %s)", o.Package, o.From, o.To)
+	}
+	return fmt.Sprintf("Updating %s (%s => %s)", o.Package, o.From, o.To)
+}
+
+// ResolvedPackage 表示解析结果中的一个包
+type ResolvedPackage struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	// Dev 是否只被 require-dev 依赖
+	Dev bool `json:"dev"`
+	// Metadata 仓库中该版本的完整元数据
+	Metadata packagist.Version `json:"-"`
+}
+
+// Plan 表示一次解析的结果
+type Plan struct {
+	// Packages 解析得到的全部包，按包名排序
+	Packages []ResolvedPackage `json:"packages"`
+	// Operations 相对于锁文件需要执行的操作
+	Operations []Operation `json:"operations"`
+}
+
+// HasChanges 判断是否存在需要执行的操作
+func (p *Plan) HasChanges() bool {
+	return len(p.Operations) > 0
+}
+
+// Find 按包名查找解析结果
+func (p *Plan) Find(name string) (ResolvedPackage, bool) {
+	for _, pkg := range p.Packages {
+		if strings.EqualFold(pkg.Name, name) {
+			return pkg, true
+		}
+	}
+	return ResolvedPackage{}, false
+}
+
+// Problem 描述一个导致解析失败的原因
+type Problem struct {
+	// Package 无法满足的包
+	Package string `json:"package"`
+	// Constraint 要求的版本约束
+	Constraint string `json:"constraint"`
+	// RequiredBy 提出该要求的包
+	RequiredBy string `json:"required_by"`
+	// Reason 无法满足的原因
+	Reason string `json:"reason"`
+}
+
+// String 返回问题说明
+func (p Problem) String() string {
+	return fmt.Sprintf("%s 需要 %s %s，%s", p.RequiredBy, p.Package, p.Constraint, p.Reason)
+}
+
+// UnresolvableError 表示依赖无法解析，包含尝试过程中遇到的问题
+type UnresolvableError struct {
+	Problems []Problem
+}
+
+// Error 实现 error 接口
+func (e *UnresolvableError) Error() string {
+	var b strings.Builder
+	b.WriteString(ErrUnresolvable.Error())
+	for i, p := range e.Problems {
+		fmt.Fprintf(&b, "\n  问题 %d: %s", i+1, p)
+	}
+	return b.String()
+}
+
+// Unwrap 使 errors.Is(err, ErrUnresolvable) 成立
+func (e *UnresolvableError) Unwrap() error {
+	return ErrUnresolvable
+}
+
+// Resolver 是依赖解析器
+//
+// Resolver 会缓存从仓库读取的元数据，不能在多个 goroutine 中同时使用。
+type Resolver struct {
+	repo      Repository
+	packages  map[string][]*candidate
+	providers map[string][]*candidate
+}
+
+// NewResolver 创建依赖解析器
+//
+// 参数：
+//   - repo: 包元数据来源，可以是 StaticRepository、ClientRepository 或自定义实现
+//
+// 用法示例：
+//
+//	repo, _ := resolver.LoadRepository("/path/to/local-repo")
+//	root, _ := resolver.LoadRoot("composer.json")
+//	lock, _ := resolver.LoadLockFile("composer.lock")
+//
+//	plan, err := resolver.NewResolver(repo).Plan(root, lock, resolver.Options{
+//	    Platform: map[string]string{"php": "8.2.10"},
+//	})
+//	if err != nil {
+//	    log.Fatalf("依赖无法解析: %v", err)
+//	}
+//	for _, op := range plan.Operations {
+//	    fmt.Println(op)
+//	}
+func NewResolver(repo Repository) *Resolver {
+	return &Resolver{
+		repo:      repo,
+		packages:  make(map[string][]*candidate),
+		providers: make(map[string][]*candidate),
+	}
+}
+
+// Plan 解析依赖并生成相对于锁文件的操作计划
+//
+// 参数：
+//   - root: 项目的根包
+//   - lock: 当前的锁文件，为nil表示首次安装
+//   - options: 解析选项
+//
+// 返回值：
+//   - *Plan: 解析结果和操作计划
+//   - error: 无法解析时返回 *UnresolvableError，可通过 errors.Is(err, ErrUnresolvable) 判断
+func (r *Resolver) Plan(root *Root, lock *LockFile, options Options) (*Plan, error) {
+	s, err := r.newSearch(root, lock, options)
+	if err != nil {
+		return nil, err
+	}
+
+	rootCandidate, err := newRootCandidate(root)
+	if err != nil {
+		return nil, err
+	}
+
+	queue, err := s.rootRequirements()
+	if err != nil {
+		return nil, err
+	}
+
+	initial := &state{selected: map[string]*candidate{rootCandidate.name: rootCandidate}, list: []*candidate{rootCandidate}}
+	result, err := s.solve(initial, queue, 0)
+	if err != nil {
+		return nil, err
+	}
+	if result == nil {
+		return nil, &UnresolvableError{Problems: s.problems}
+	}
+
+	return s.buildPlan(result, lock), nil
+}
+
+// candidate 表示解析过程中的一个候选版本
+type candidate struct {
+	name     string
+	version  *Version
+	alias    *Version
+	meta     packagist.Version
+	isRoot   bool
+	requires map[string]*Constraint
+	conflict map[string]*Constraint
+	replace  map[string]*Constraint
+	provide  map[string]*Constraint
+}
+
+// newCandidate 根据仓库中的版本元数据创建候选版本
+func newCandidate(meta packagist.Version) (*candidate, error) {
+	v, err := ParseVersion(meta.Version)
+	if err != nil && meta.VersionNormalized != "" {
+		v, err = ParseVersion(meta.VersionNormalized)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	c := &candidate{name: strings.ToLower(meta.Name), version: v, meta: meta}
+	if aliases, ok := meta.Extra["branch-alias"].(map[string]interface{}); ok {
+		if alias, ok := aliases[meta.Version].(string); ok {
+			c.alias, _ = ParseVersion(alias)
+		}
+	}
+
+	if c.requires, err = c.parseLinks(meta.Require); err != nil {
+		return nil, err
+	}
+	if c.conflict, err = c.parseLinks(meta.Conflict); err != nil {
+		return nil, err
+	}
+	if c.replace, err = c.parseLinks(meta.Replace); err != nil {
+		return nil, err
+	}
+	if c.provide, err = c.parseLinks(meta.Provide); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// newRootCandidate 创建表示根包的候选版本
+func newRootCandidate(root *Root) (*candidate, error) {
+	name := strings.ToLower(root.Name)
+	if name == "" {
+		name = rootPackageName
+	}
+	version := root.Version
+	if version == "" {
+		version = "1.0.0"
+	}
+
+	c, err := newCandidate(packagist.Version{
+		Name:     name,
+		Version:  version,
+		Replace:  root.Replace,
+		Provide:  root.Provide,
+		Conflict: root.Conflict,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("根包: %w", err)
+	}
+	c.isRoot = true
+	return c, nil
+}
+
+// parseLinks 解析包链接中的版本约束，self.version 表示与包自身版本相同
+func (c *candidate) parseLinks(links packagist.Links) (map[string]*Constraint, error) {
+	result := make(map[string]*Constraint, len(links))
+	for name, raw := range links {
+		if strings.TrimSpace(raw) == "self.version" {
+			result[strings.ToLower(name)] = c.selfConstraint()
+			continue
+		}
+		con, err := ParseConstraint(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%s %s: %w", c.name, name, err)
+		}
+		result[strings.ToLower(name)] = con
+	}
+	return result, nil
+}
+
+// selfConstraint 返回匹配候选版本自身及其分支别名的约束
+func (c *candidate) selfConstraint() *Constraint {
+	con := exactConstraint(c.version)
+	if c.alias != nil {
+		con.union(exactConstraint(c.alias))
+	}
+	return con
+}
+
+// provision 返回候选版本为某个包名提供的版本约束
+//
+// 返回值：
+//   - *Constraint: 提供的版本
+//   - bool: 是否通过 replace 提供
+//   - bool: 是否提供了该包名
+func (c *candidate) provision(name string) (*Constraint, bool, bool) {
+	if c.name == name {
+		return c.selfConstraint(), false, true
+	}
+	if con, ok := c.replace[name]; ok {
+		return con, true, true
+	}
+	if con, ok := c.provide[name]; ok {
+		return con, false, true
+	}
+	return nil, false, false
+}
+
+// matches 判断候选版本或其分支别名是否满足约束
+func (c *candidate) matches(con *Constraint) bool {
+	return con.Matches(c.version) || (c.alias != nil && con.Matches(c.alias))
+}
+
+// label 返回用于说明的包名和版本
+func (c *candidate) label() string {
+	if c.isRoot {
+		if c.name == rootPackageName {
+			return "根 composer.json"
+		}
+		return c.name + " (根包)"
+	}
+	return c.name + " " + c.version.String()
+}
+
+// reference 返回版本的源码引用
+func reference(v packagist.Version) string {
+	if v.Source != nil && v.Source.Reference != "" {
+		return v.Source.Reference
+	}
+	if v.Dist != nil {
+		return v.Dist.Reference
+	}
+	return ""
+}
+
+// state 表示搜索过程中的一组已选择的包
+type state struct {
+	selected map[string]*candidate
+	list     []*candidate
+}
+
+// with 返回添加了候选版本的新状态
+func (s *state) with(c *candidate) *state {
+	next := &state{
+		selected: make(map[string]*candidate, len(s.selected)+1),
+		list:     make([]*candidate, len(s.list), len(s.list)+1),
+	}
+	for k, v := range s.selected {
+		next.selected[k] = v
+	}
+	copy(next.list, s.list)
+	next.selected[c.name] = c
+	next.list = append(next.list, c)
+	return next
+}
+
+// requirement 表示一个待满足的依赖
+type requirement struct {
+	name       string
+	constraint *Constraint
+	requiredBy string
+}
+
+// search 保存一次解析的上下文
+type search struct {
+	r         *Resolver
+	root      *Root
+	options   Options
+	minimum   Stability
+	flags     map[string]Stability
+	locked    map[string]packagist.Version
+	updatable map[string]bool
+	platform  map[string]*Version
+	steps     int
+	problems  []Problem
+	seen      map[Problem]bool
+}
+
+// newSearch 根据根包、锁文件和选项初始化解析上下文
+func (r *Resolver) newSearch(root *Root, lock *LockFile, options Options) (*search, error) {
+	if root == nil {
+		return nil, errors.New("根包不能为空")
+	}
+	if options.MaxSteps <= 0 {
+		options.MaxSteps = DefaultMaxSteps
+	}
+
+	s := &search{
+		r:        r,
+		root:     root,
+		options:  options,
+		minimum:  StabilityStable,
+		flags:    make(map[string]Stability),
+		locked:   make(map[string]packagist.Version),
+		platform: make(map[string]*Version),
+		seen:     make(map[Problem]bool),
+	}
+
+	if root.MinimumStability != "" {
+		stability, ok := ParseStability(root.MinimumStability)
+		if !ok {
+			return nil, fmt.Errorf("无效的 minimum-stability: %q", root.MinimumStability)
+		}
+		s.minimum = stability
+	}
+
+	for _, links := range []map[string]string{root.Require, root.RequireDev} {
+		for name, raw := range links {
+			con, err := ParseConstraint(raw)
+			if err != nil {
+				return nil, fmt.Errorf("根包 %s: %w", name, err)
+			}
+			if stability := con.Stability(); stability != "" && !s.minimum.Allows(stability) {
+				s.flags[strings.ToLower(name)] = stability
+			}
+		}
+	}
+
+	for _, p := range lock.AllPackages() {
+		s.locked[strings.ToLower(p.Name)] = p
+	}
+	s.updatable = s.updatableSet()
+
+	platform := make(map[string]string)
+	for name, version := range options.Platform {
+		platform[strings.ToLower(name)] = version
+	}
+	for name, version := range root.Platform {
+		platform[strings.ToLower(name)] = version
+	}
+	for name, version := range platform {
+		// 无法解析的平台版本视为满足任意约束
+		v, _ := ParseVersion(version)
+		s.platform[name] = v
+	}
+
+	return s, nil
+}
+
+// updatableSet 计算部分更新时允许变化的包，返回nil表示全部可更新
+func (s *search) updatableSet() map[string]bool {
+	if len(s.options.Packages) == 0 {
+		return nil
+	}
+
+	allowed := make(map[string]bool)
+	for _, pattern := range s.options.Packages {
+		pattern = strings.ToLower(pattern)
+		allowed[pattern] = true
+		for name := range s.locked {
+			if ok, _ := path.Match(pattern, name); ok {
+				allowed[name] = true
+			}
+		}
+	}
+
+	if s.options.WithDependencies {
+		queue := make([]string, 0, len(allowed))
+		for name := range allowed {
+			queue = append(queue, name)
+		}
+		for len(queue) > 0 {
+			name := queue[0]
+			queue = queue[1:]
+			for dep := range s.locked[name].Require {
+				dep = strings.ToLower(dep)
+				if !allowed[dep] && !IsPlatformPackage(dep) {
+					allowed[dep] = true
+					queue = append(queue, dep)
+				}
+			}
+		}
+	}
+
+	return allowed
+}
+
+// isUpdatable 判断包是否允许偏离锁定的版本
+func (s *search) isUpdatable(name string) bool {
+	return s.updatable == nil || s.updatable[name]
+}
+
+// rootRequirements 返回根包的依赖，按包名排序
+func (s *search) rootRequirements() ([]requirement, error) {
+	label := s.root.Name
+	if label == "" {
+		label = "根 composer.json"
+	}
+
+	groups := []map[string]string{s.root.Require}
+	if !s.options.NoDev {
+		groups = append(groups, s.root.RequireDev)
+	}
+
+	var queue []requirement
+	for _, links := range groups {
+		names := make([]string, 0, len(links))
+		for name := range links {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			con, err := ParseConstraint(links[name])
+			if err != nil {
+				return nil, fmt.Errorf("根包 %s: %w", name, err)
+			}
+			queue = append(queue, requirement{name: strings.ToLower(name), constraint: con, requiredBy: label})
+		}
+	}
+	return queue, nil
+}
+
+// solve 依次满足队列中的依赖，在无法满足时回溯
+//
+// 返回值为nil且没有错误表示当前分支无解。
+func (s *search) solve(st *state, queue []requirement, i int) (*state, error) {
+	for ; i < len(queue); i++ {
+		req := queue[i]
+
+		if IsPlatformPackage(req.name) {
+			if !s.checkPlatform(req) {
+				return nil, nil
+			}
+			continue
+		}
+
+		satisfied, blocked := s.satisfied(st, req)
+		if satisfied {
+			continue
+		}
+		if blocked {
+			return nil, nil
+		}
+
+		candidates, reason, err := s.candidates(req)
+		if err != nil {
+			return nil, err
+		}
+		if len(candidates) == 0 {
+			s.addProblem(req, reason)
+			return nil, nil
+		}
+
+		for _, c := range candidates {
+			s.steps++
+			if s.steps > s.options.MaxSteps {
+				return nil, fmt.Errorf("%w (%d)", ErrTooComplex, s.options.MaxSteps)
+			}
+
+			if reason := conflictReason(st, c); reason != "" {
+				s.addProblem(req, reason)
+				continue
+			}
+
+			next := st.with(c)
+			nextQueue := make([]requirement, len(queue), len(queue)+len(c.requires))
+			copy(nextQueue, queue)
+			nextQueue = append(nextQueue, c.requirements()...)
+
+			result, err := s.solve(next, nextQueue, i+1)
+			if err != nil || result != nil {
+				return result, err
+			}
+		}
+		return nil, nil
+	}
+	return st, nil
+}
+
+// requirements 返回候选版本的依赖，按包名排序
+func (c *candidate) requirements() []requirement {
+	names := make([]string, 0, len(c.requires))
+	for name := range c.requires {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	reqs := make([]requirement, 0, len(names))
+	for _, name := range names {
+		reqs = append(reqs, requirement{name: name, constraint: c.requires[name], requiredBy: c.label()})
+	}
+	return reqs
+}
+
+// checkPlatform 检查平台包是否满足要求
+func (s *search) checkPlatform(req requirement) bool {
+	if s.options.IgnorePlatformReqs {
+		return true
+	}
+
+	v, ok := s.platform[req.name]
+	if !ok {
+		s.addProblem(req, "当前平台缺少该平台包")
+		return false
+	}
+	if v != nil && !req.constraint.Matches(v) {
+		s.addProblem(req, fmt.Sprintf("当前平台版本为 %s", v))
+		return false
+	}
+	return true
+}
+
+// satisfied 判断依赖是否已被已选择的包满足
+//
+// 返回值：
+//   - bool: 是否已满足
+//   - bool: 是否已有同名或 replace 了该包的版本且不满足约束，此时当前分支无解
+func (s *search) satisfied(st *state, req requirement) (bool, bool) {
+	for _, c := range st.list {
+		provided, replaced, ok := c.provision(req.name)
+		if !ok {
+			continue
+		}
+		if provided.Intersects(req.constraint) {
+			return true, false
+		}
+		if c.name == req.name || replaced {
+			s.addProblem(req, fmt.Sprintf("已选择的 %s 不满足该约束", c.label()))
+			return false, true
+		}
+	}
+	return false, false
+}
+
+// candidates 返回满足依赖的候选版本，按优先顺序排列
+//
+// 没有候选版本时返回说明原因的文字。
+func (s *search) candidates(req requirement) ([]*candidate, string, error) {
+	if locked, ok := s.locked[req.name]; ok && !s.isUpdatable(req.name) {
+		c, err := newCandidate(locked)
+		if err != nil {
+			return nil, fmt.Sprintf("锁定的版本无法解析: %v", err), nil
+		}
+		if !c.matches(req.constraint) {
+			return nil, fmt.Sprintf("锁定的版本 %s 不满足约束，需要将该包加入更新列表", c.version), nil
+		}
+		return []*candidate{c}, "", nil
+	}
+
+	all, err := s.r.findPackages(req.name)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var result []*candidate
+	var filtered []*candidate
+	for _, c := range all {
+		if !c.matches(req.constraint) {
+			continue
+		}
+		if !s.stabilityAllowed(c) {
+			filtered = append(filtered, c)
+			continue
+		}
+		result = append(result, c)
+	}
+	s.sortCandidates(result)
+
+	providers, err := s.r.whatProvides(req.name)
+	if err != nil {
+		return nil, "", err
+	}
+	var provided []*candidate
+	for _, c := range providers {
+		if con, _, ok := c.provision(req.name); ok && con.Intersects(req.constraint) && s.stabilityAllowed(c) {
+			provided = append(provided, c)
+		}
+	}
+	s.sortCandidates(provided)
+	result = append(result, provided...)
+
+	if len(result) > 0 {
+		return result, "", nil
+	}
+
+	switch {
+	case len(all) == 0:
+		return nil, "仓库中找不到该包", nil
+	case len(filtered) > 0:
+		return nil, fmt.Sprintf("找到 %s，但其稳定性低于 minimum-stability (%s)", filtered[0].label(), s.stabilityFor(req.name)), nil
+	}
+
+	versions := make([]string, 0, 5)
+	for i, c := range all {
+		if i == 5 {
+			versions = append(versions, "...")
+			break
+		}
+		versions = append(versions, c.version.String())
+	}
+	return nil, fmt.Sprintf("没有满足约束的版本（可用版本: %s）", strings.Join(versions, ", ")), nil
+}
+
+// stabilityFor 返回包允许的最低稳定性
+func (s *search) stabilityFor(name string) Stability {
+	if flag, ok := s.flags[name]; ok {
+		return flag
+	}
+	return s.minimum
+}
+
+// stabilityAllowed 判断候选版本的稳定性是否被允许
+func (s *search) stabilityAllowed(c *candidate) bool {
+	return s.stabilityFor(c.name).Allows(c.version.Stability())
+}
+
+// sortCandidates 按版本从高到低排序，开启 prefer-stable 时稳定的版本优先
+func (s *search) sortCandidates(candidates []*candidate) {
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if s.root.PreferStable {
+			ra, rb := stabilityRanks[a.version.Stability()], stabilityRanks[b.version.Stability()]
+			if ra != rb {
+				return ra < rb
+			}
+		}
+		return a.version.Compare(b.version) > 0
+	})
+}
+
+// addProblem 记录一个导致失败的问题，重复的问题只记录一次
+func (s *search) addProblem(req requirement, reason string) {
+	p := Problem{Package: req.name, Constraint: req.constraint.String(), RequiredBy: req.requiredBy, Reason: reason}
+	if s.seen[p] {
+		return
+	}
+	s.seen[p] = true
+	s.problems = append(s.problems, p)
+}
+
+// conflictReason 检查候选版本是否与已选择的包冲突，返回冲突说明
+func conflictReason(st *state, c *candidate) string {
+	for _, other := range st.list {
+		if other.name == c.name {
+			return fmt.Sprintf("已选择了 %s", other.label())
+		}
+		for name := range c.replace {
+			if other.name == name {
+				return fmt.Sprintf("%s 替代了已选择的 %s", c.label(), other.label())
+			}
+			if _, ok := other.replace[name]; ok {
+				return fmt.Sprintf("%s 与 %s 都替代了 %s", c.label(), other.label(), name)
+			}
+		}
+		for name := range other.replace {
+			if c.name == name {
+				return fmt.Sprintf("%s 已被 %s 替代", c.name, other.label())
+			}
+		}
+		for name, con := range c.conflict {
+			if provided, _, ok := other.provision(name); ok && provided.Intersects(con) {
+				return fmt.Sprintf("%s 与 %s 冲突 (conflict %s %s)", c.label(), other.label(), name, con)
+			}
+		}
+		for name, con := range other.conflict {
+			if provided, _, ok := c.provision(name); ok && provided.Intersects(con) {
+				return fmt.Sprintf("%s 与 %s 冲突 (conflict %s %s)", other.label(), c.label(), name, con)
+			}
+		}
+	}
+	return ""
+}
+
+// findPackages 读取并缓存包的候选版本，无法解析的版本会被忽略
+func (r *Resolver) findPackages(name string) ([]*candidate, error) {
+	if cached, ok := r.packages[name]; ok {
+		return cached, nil
+	}
+
+	versions, err := r.repo.FindPackages(name)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := make([]*candidate, 0, len(versions))
+	for _, v := range versions {
+		if strings.EqualFold(v.Name, name) {
+			if c, err := newCandidate(v); err == nil {
+				candidates = append(candidates, c)
+			}
+		}
+	}
+	r.packages[name] = candidates
+	return candidates, nil
+}
+
+// whatProvides 读取并缓存 replace 或 provide 了该包名的候选版本
+func (r *Resolver) whatProvides(name string) ([]*candidate, error) {
+	providerRepo, ok := r.repo.(ProviderRepository)
+	if !ok {
+		return nil, nil
+	}
+	if cached, ok := r.providers[name]; ok {
+		return cached, nil
+	}
+
+	versions, err := providerRepo.WhatProvides(name)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := make([]*candidate, 0, len(versions))
+	for _, v := range versions {
+		if !strings.EqualFold(v.Name, name) {
+			if c, err := newCandidate(v); err == nil {
+				candidates = append(candidates, c)
+			}
+		}
+	}
+	r.providers[name] = candidates
+	return candidates, nil
+}
+
+// buildPlan 根据解析结果生成操作计划
+func (s *search) buildPlan(st *state, lock *LockFile) *Plan {
+	nonDev := s.reachable(st)
+
+	plan := &Plan{}
+	resolved := make(map[string]bool)
+	for _, c := range st.list {
+		if c.isRoot {
+			continue
+		}
+		resolved[c.name] = true
+		plan.Packages = append(plan.Packages, ResolvedPackage{
+			Name:     c.meta.Name,
+			Version:  c.version.String(),
+			Dev:      !nonDev[c.name],
+			Metadata: c.meta,
+		})
+
+		locked, ok := s.locked[c.name]
+		if !ok {
+			plan.Operations = append(plan.Operations, Operation{Type: OperationInstall, Package: c.meta.Name, To: c.version.String()})
+			continue
+		}
+
+		op := Operation{Package: c.meta.Name, From: locked.Version, To: c.version.String()}
+		lockedVersion, err := ParseVersion(locked.Version)
+		switch {
+		case err != nil:
+			op.Type = OperationUpdate
+		case c.version.Compare(lockedVersion) > 0:
+			op.Type = OperationUpgrade
+		case c.version.Compare(lockedVersion) < 0:
+			op.Type = OperationDowngrade
+		case reference(locked) != reference(c.meta):
+			op.Type = OperationUpdate
+		default:
+			continue
+		}
+		plan.Operations = append(plan.Operations, op)
+	}
+
+	for _, p := range lock.AllPackages() {
+		if !resolved[strings.ToLower(p.Name)] {
+			plan.Operations = append(plan.Operations, Operation{Type: OperationRemove, Package: p.Name, From: p.Version})
+		}
+	}
+
+	sort.Slice(plan.Packages, func(i, j int) bool { return plan.Packages[i].Name < plan.Packages[j].Name })
+	sort.SliceStable(plan.Operations, func(i, j int) bool { return plan.Operations[i].Package < plan.Operations[j].Package })
+	return plan
+}
+
+// reachable 返回从根包的 require（不含 require-dev）可以到达的包
+func (s *search) reachable(st *state) map[string]bool {
+	provider := func(name string) *candidate {
+		if c, ok := st.selected[name]; ok {
+			return c
+		}
+		for _, c := range st.list {
+			if _, _, ok := c.provision(name); ok {
+				return c
+			}
+		}
+		return nil
+	}
+
+	result := make(map[string]bool)
+	var queue []string
+	for name := range s.root.Require {
+		queue = append(queue, strings.ToLower(name))
+	}
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		c := provider(name)
+		if c == nil || c.isRoot || result[c.name] {
+			continue
+		}
+		result[c.name] = true
+		for dep := range c.requires {
+			queue = append(queue, dep)
+		}
+	}
+	return result
+}
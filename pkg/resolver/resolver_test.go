@@ -0,0 +1,230 @@
+package resolver
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/scagogogo/go-composer-sdk/pkg/packagist"
+)
+
+// pkg 创建测试用的版本元数据
+func pkg(name, version string, require map[string]string) packagist.Version {
+	return packagist.Version{Name: name, Version: version, Require: require}
+}
+
+func testRepository() *StaticRepository {
+	logger3 := pkg("psr/log", "3.0.0", map[string]string{"php": ">=8.0"})
+	monolog3 := pkg("monolog/monolog", "3.5.0", map[string]string{"php": ">=8.1", "psr/log": "^2.0 || ^3.0"})
+	monolog3.Provide = packagist.Links{"psr/log-implementation": "3.0.0"}
+	oldHTTP := pkg("acme/http", "1.0.0", map[string]string{"psr/log": "^1.0"})
+	oldHTTP.Conflict = packagist.Links{"monolog/monolog": ">=3.0"}
+
+	return NewStaticRepository(
+		pkg("psr/log", "1.1.4", nil),
+		pkg("psr/log", "2.0.0", map[string]string{"php": ">=8.0"}),
+		logger3,
+		pkg("monolog/monolog", "2.9.0", map[string]string{"php": ">=7.2", "psr/log": "^1.0 || ^2.0"}),
+		monolog3,
+		pkg("monolog/monolog", "4.0.0-beta1", map[string]string{"php": ">=8.2", "psr/log": "^3.0"}),
+		pkg("acme/http", "2.0.0", map[string]string{"psr/log": "^2.0 || ^3.0"}),
+		oldHTTP,
+		pkg("acme/logger-consumer", "1.0.0", map[string]string{"psr/log-implementation": "^1.0 || ^3.0"}),
+	)
+}
+
+func testLock() *LockFile {
+	return &LockFile{Packages: []packagist.Version{
+		pkg("monolog/monolog", "2.9.0", map[string]string{"psr/log": "^1.0 || ^2.0"}),
+		pkg("psr/log", "1.1.4", nil),
+		pkg("acme/unused", "1.0.0", nil),
+	}}
+}
+
+func TestResolverPlanFullUpdate(t *testing.T) {
+	root := &Root{
+		Require:    map[string]string{"php": "^8.1", "monolog/monolog": "*"},
+		RequireDev: map[string]string{"acme/http": "^2.0"},
+	}
+
+	plan, err := NewResolver(testRepository()).Plan(root, testLock(), Options{Platform: map[string]string{"php": "8.2.10"}})
+	if err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+
+	monolog, _ := plan.Find("monolog/monolog")
+	if monolog.Version != "3.5.0" {
+		t.Errorf("应选择最新的稳定版本3.5.0，实际为%s", monolog.Version)
+	}
+	logger, _ := plan.Find("psr/log")
+	if logger.Version != "3.0.0" || logger.Dev {
+		t.Errorf("psr/log 应为3.0.0且不是开发依赖: %+v", logger)
+	}
+	if http, _ := plan.Find("acme/http"); !http.Dev {
+		t.Error("只被require-dev依赖的包应标记为Dev")
+	}
+
+	want := map[string]OperationType{
+		"acme/http":       OperationInstall,
+		"acme/unused":     OperationRemove,
+		"monolog/monolog": OperationUpgrade,
+		"psr/log":         OperationUpgrade,
+	}
+	if len(plan.Operations) != len(want) {
+		t.Fatalf("操作数量不正确: %v", plan.Operations)
+	}
+	for _, op := range plan.Operations {
+		if want[op.Package] != op.Type {
+			t.Errorf("%s 的操作应为%s，实际为%s", op.Package, want[op.Package], op.Type)
+		}
+	}
+	if got := plan.Operations[2].String(); got != "Upgrading monolog/monolog (2.9.0 => 3.5.0)" {
+		t.Errorf("操作说明不正确: %s", got)
+	}
+}
+
+func TestResolverPartialUpdateAndBacktracking(t *testing.T) {
+	root := &Root{Require: map[string]string{"monolog/monolog": "*", "acme/http": "*"}}
+	options := Options{Platform: map[string]string{"php": "8.2.10"}, Packages: []string{"acme/*"}}
+
+	// monolog 和 psr/log 保持锁定的版本，acme/http 2.0.0 需要 psr/log ^2|^3 因此回溯到 1.0.0
+	plan, err := NewResolver(testRepository()).Plan(root, testLock(), options)
+	if err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+	if p, _ := plan.Find("monolog/monolog"); p.Version != "2.9.0" {
+		t.Errorf("未在更新列表中的包应保持锁定版本，实际为%s", p.Version)
+	}
+	if p, _ := plan.Find("acme/http"); p.Version != "1.0.0" {
+		t.Errorf("acme/http 应回溯到1.0.0，实际为%s", p.Version)
+	}
+
+	// 允许更新依赖后 acme/http 1.0.0 与 monolog 3 冲突，应选择 2.0.0
+	options.Packages = []string{"monolog/monolog", "acme/http"}
+	options.WithDependencies = true
+	plan, err = NewResolver(testRepository()).Plan(root, testLock(), options)
+	if err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+	if p, _ := plan.Find("acme/http"); p.Version != "2.0.0" {
+		t.Errorf("acme/http 应为2.0.0，实际为%s", p.Version)
+	}
+}
+
+func TestResolverStabilityAndConflict(t *testing.T) {
+	repo := testRepository()
+	platform := map[string]string{"php": "8.2.10"}
+
+	plan, err := NewResolver(repo).Plan(&Root{Require: map[string]string{"monolog/monolog": "^4.0@beta"}}, nil, Options{Platform: platform})
+	if err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+	if p, _ := plan.Find("monolog/monolog"); p.Version != "4.0.0-beta1" {
+		t.Errorf("@beta 应允许安装beta版本，实际为%s", p.Version)
+	}
+
+	root := &Root{Require: map[string]string{"monolog/monolog": ">=3.0"}, MinimumStability: "beta", PreferStable: true}
+	plan, err = NewResolver(repo).Plan(root, nil, Options{Platform: platform})
+	if err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+	if p, _ := plan.Find("monolog/monolog"); p.Version != "3.5.0" {
+		t.Errorf("prefer-stable 应优先选择稳定版本，实际为%s", p.Version)
+	}
+
+	root = &Root{Require: map[string]string{"monolog/monolog": "^3.0"}, Conflict: map[string]string{"psr/log": "3.0.0"}}
+	plan, err = NewResolver(repo).Plan(root, nil, Options{Platform: platform})
+	if err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+	if p, _ := plan.Find("psr/log"); p.Version != "2.0.0" {
+		t.Errorf("根包的conflict应排除psr/log 3.0.0，实际为%s", p.Version)
+	}
+}
+
+func TestResolverReplaceAndProvide(t *testing.T) {
+	repo := testRepository()
+	platform := map[string]string{"php": "8.2.10"}
+
+	// 根包 replace 的包不需要从仓库安装
+	root := &Root{Require: map[string]string{"acme/http": "^2.0"}, Replace: map[string]string{"psr/log": "3.0.0"}}
+	plan, err := NewResolver(repo).Plan(root, nil, Options{Platform: platform})
+	if err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+	if _, ok := plan.Find("psr/log"); ok {
+		t.Error("被根包replace的包不应被安装")
+	}
+
+	// 通过 provide 满足虚拟包
+	root = &Root{Require: map[string]string{"acme/logger-consumer": "^1.0"}}
+	plan, err = NewResolver(repo).Plan(root, nil, Options{Platform: platform})
+	if err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+	if p, ok := plan.Find("monolog/monolog"); !ok || p.Version != "3.5.0" {
+		t.Errorf("应安装提供psr/log-implementation的monolog 3.5.0: %+v", plan.Packages)
+	}
+}
+
+func TestResolverUnresolvable(t *testing.T) {
+	root := &Root{Require: map[string]string{"php": "^8.1", "monolog/monolog": "^3.0", "acme/missing": "^1.0"}}
+	_, err := NewResolver(testRepository()).Plan(root, nil, Options{Platform: map[string]string{"php": "7.4.33"}})
+
+	var unresolvable *UnresolvableError
+	if !errors.As(err, &unresolvable) || !errors.Is(err, ErrUnresolvable) {
+		t.Fatalf("应返回UnresolvableError，实际为%v", err)
+	}
+	if len(unresolvable.Problems) == 0 || unresolvable.Problems[0].Package != "acme/missing" {
+		t.Fatalf("问题说明不正确: %+v", unresolvable.Problems)
+	}
+	if !strings.Contains(err.Error(), "仓库中找不到该包") {
+		t.Errorf("错误信息应说明原因: %v", err)
+	}
+
+	root.Require = map[string]string{"php": "^8.1"}
+	_, err = NewResolver(testRepository()).Plan(root, nil, Options{Platform: map[string]string{"php": "7.4.33"}})
+	if !errors.As(err, &unresolvable) || !strings.Contains(unresolvable.Problems[0].Reason, "7.4.33") {
+		t.Errorf("平台版本不满足时应给出当前版本: %v", err)
+	}
+
+	if _, err := NewResolver(testRepository()).Plan(root, nil, Options{IgnorePlatformReqs: true}); err != nil {
+		t.Errorf("忽略平台需求时应解析成功: %v", err)
+	}
+}
+
+func TestLoadRepositoryDirectory(t *testing.T) {
+	dir := t.TempDir()
+	_ = os.MkdirAll(filepath.Join(dir, "p2", "acme"), 0755)
+	_ = os.WriteFile(filepath.Join(dir, "packages.json"), []byte(`{"packages": {"acme/inline": {"1.0.0": {"version": "1.0.0"}}}}`), 0644)
+	_ = os.WriteFile(filepath.Join(dir, "p2", "acme", "tool.json"), []byte(`{
+		"minified": "composer/2.0",
+		"packages": {"acme/tool": [
+			{"name": "acme/tool", "version": "1.1.0", "require": {"acme/inline": "^1.0"}},
+			{"version": "1.0.0"}
+		]}
+	}`), 0644)
+
+	repo, err := LoadRepository(dir)
+	if err != nil {
+		t.Fatalf("加载本地仓库失败: %v", err)
+	}
+	if names := repo.PackageNames(); len(names) != 2 {
+		t.Fatalf("应加载2个包，实际为%v", names)
+	}
+
+	versions, _ := repo.FindPackages("acme/tool")
+	if len(versions) != 2 || versions[1].Require["acme/inline"] != "^1.0" {
+		t.Errorf("压缩格式应被展开: %+v", versions)
+	}
+
+	plan, err := NewResolver(repo).Plan(&Root{Require: map[string]string{"acme/tool": "^1.0"}}, nil, Options{})
+	if err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+	if len(plan.Packages) != 2 {
+		t.Errorf("应解析出2个包: %+v", plan.Packages)
+	}
+}
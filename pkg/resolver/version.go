@@ -0,0 +1,350 @@
+package resolver
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Stability 表示版本的稳定性
+type Stability string
+
+// Composer 支持的稳定性，按从稳定到不稳定排列
+const (
+	StabilityStable Stability = "stable"
+	StabilityRC     Stability = "RC"
+	StabilityBeta   Stability = "beta"
+	StabilityAlpha  Stability = "alpha"
+	StabilityDev    Stability = "dev"
+)
+
+// stabilityRanks 与 Composer BasePackage::STABILITIES 一致，数值越大越不稳定
+var stabilityRanks = map[Stability]int{
+	StabilityStable: 0,
+	StabilityRC:     5,
+	StabilityBeta:   10,
+	StabilityAlpha:  15,
+	StabilityDev:    20,
+}
+
+// ParseStability 解析稳定性名称，不区分大小写
+//
+// 参数：
+//   - name: 稳定性名称，例如 "stable"、"RC"、"beta"、"alpha"、"dev"
+//
+// 返回值：
+//   - Stability: 解析后的稳定性
+//   - bool: 名称是否有效
+func ParseStability(name string) (Stability, bool) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "stable":
+		return StabilityStable, true
+	case "rc":
+		return StabilityRC, true
+	case "beta":
+		return StabilityBeta, true
+	case "alpha":
+		return StabilityAlpha, true
+	case "dev":
+		return StabilityDev, true
+	}
+	return "", false
+}
+
+// Allows 判断在该最低稳定性下是否允许安装稳定性为 other 的版本
+func (s Stability) Allows(other Stability) bool {
+	return stabilityRanks[other] <= stabilityRanks[s]
+}
+
+// LessStable 返回两个稳定性中更不稳定的一个
+func LessStable(a, b Stability) Stability {
+	if stabilityRanks[b] > stabilityRanks[a] {
+		return b
+	}
+	return a
+}
+
+// 预发布修饰符的排序，与 PHP version_compare 的规则一致
+const (
+	modifierDev = iota
+	modifierAlpha
+	modifierBeta
+	modifierRC
+	modifierStable
+	modifierPatch
+)
+
+// branchPlaceholder 是 Composer 用于表示 x 通配分支版本的数字
+const branchPlaceholder = 9999999
+
+const modifierPattern = `[._-]?(?:(stable|beta|b|rc|alpha|a|patch|pl|p)((?:[.-]?\d+)*)?)?([.-]?dev)?`
+
+var (
+	classicalVersionPattern = regexp.MustCompile(`(?i)^v?(\d{1,5})(\.\d+)?(\.\d+)?(\.\d+)?` + modifierPattern + `$`)
+	dateVersionPattern      = regexp.MustCompile(`(?i)^v?(\d{4}(?:[.:-]?\d{2}){1,6}(?:[.:-]?\d{1,3}){0,2})` + modifierPattern + `$`)
+	branchVersionPattern    = regexp.MustCompile(`^v?(\d+)(\.(?:\d+|[xX*]))?(\.(?:\d+|[xX*]))?(\.(?:\d+|[xX*]))?$`)
+	digitsPattern           = regexp.MustCompile(`\d+`)
+)
+
+// Version 表示一个经过 Composer 规则规范化的版本号
+//
+// 数字版本会被补齐为4段，例如 "v1.2" 规范化为 "1.2.0.0"；
+// "dev-main" 这样的分支版本只能与同名分支精确比较。
+type Version struct {
+	original    string
+	parts       [4]int
+	modifier    int
+	modifierNum []int
+	devSuffix   bool
+	branch      string
+}
+
+// ParseVersion 按照 Composer VersionParser 的规则解析版本号
+//
+// 参数：
+//   - version: 版本字符串，例如 "1.2.3"、"v2.0.0-beta1"、"2.x-dev"、"dev-main"
+//
+// 返回值：
+//   - *Version: 解析后的版本
+//   - error: 如果版本号无法解析，则返回 ErrInvalidVersion
+//
+// 用法示例：
+//
+//	v, err := resolver.ParseVersion("v2.1.0-RC1")
+//	fmt.Println(v.Normalized()) // 2.1.0.0-RC1
+func ParseVersion(version string) (*Version, error) {
+	original := strings.TrimSpace(version)
+	v := strings.TrimSpace(version)
+
+	// 去掉别名部分和构建元数据
+	if i := strings.Index(strings.ToLower(v), " as "); i >= 0 {
+		v = strings.TrimSpace(v[:i])
+	}
+	if i := strings.Index(v, "#"); i >= 0 {
+		v = v[:i]
+	}
+	if i := strings.Index(v, "+"); i >= 0 && !strings.HasPrefix(strings.ToLower(v), "dev-") {
+		v = v[:i]
+	}
+
+	if v == "" {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidVersion, version)
+	}
+
+	if strings.HasPrefix(strings.ToLower(v), "dev-") {
+		return &Version{original: original, branch: v[4:]}, nil
+	}
+
+	if m := classicalVersionPattern.FindStringSubmatch(v); m != nil {
+		parsed := &Version{original: original}
+		for i := 0; i < 4; i++ {
+			if n, err := strconv.Atoi(strings.TrimPrefix(m[i+1], ".")); err == nil {
+				parsed.parts[i] = n
+			}
+		}
+		parsed.applyModifier(m[5], m[6], m[7])
+		return parsed, nil
+	}
+
+	if m := dateVersionPattern.FindStringSubmatch(v); m != nil {
+		parsed := &Version{original: original}
+		for i, digits := range digitsPattern.FindAllString(m[1], 4) {
+			parsed.parts[i], _ = strconv.Atoi(digits)
+		}
+		parsed.applyModifier(m[2], m[3], m[4])
+		return parsed, nil
+	}
+
+	// 以 -dev 结尾的分支，例如 2.x-dev 或 feature-dev
+	lower := strings.ToLower(v)
+	if strings.HasSuffix(lower, "-dev") || strings.HasSuffix(lower, ".dev") {
+		name := v[:len(v)-4]
+		if m := branchVersionPattern.FindStringSubmatch(name); m != nil {
+			parsed := &Version{original: original, modifier: modifierStable, devSuffix: true}
+			for i := 0; i < 4; i++ {
+				part := strings.TrimPrefix(m[i+1], ".")
+				switch part {
+				case "":
+					parsed.parts[i] = branchPlaceholder
+				case "x", "X", "*":
+					parsed.parts[i] = branchPlaceholder
+				default:
+					parsed.parts[i], _ = strconv.Atoi(part)
+				}
+			}
+			return parsed, nil
+		}
+		return &Version{original: original, branch: name}, nil
+	}
+
+	return nil, fmt.Errorf("%w: %q", ErrInvalidVersion, version)
+}
+
+// applyModifier 设置预发布修饰符
+func (v *Version) applyModifier(modifier string, number string, dev string) {
+	v.modifier = modifierStable
+	switch strings.ToLower(modifier) {
+	case "alpha", "a":
+		v.modifier = modifierAlpha
+	case "beta", "b":
+		v.modifier = modifierBeta
+	case "rc":
+		v.modifier = modifierRC
+	case "patch", "pl", "p":
+		v.modifier = modifierPatch
+	}
+	if modifier != "" {
+		for _, digits := range digitsPattern.FindAllString(number, -1) {
+			n, _ := strconv.Atoi(digits)
+			v.modifierNum = append(v.modifierNum, n)
+		}
+	}
+	v.devSuffix = dev != ""
+}
+
+// Original 返回原始的版本字符串
+func (v *Version) Original() string {
+	return v.original
+}
+
+// IsBranch 判断是否为 dev-xxx 形式的分支版本
+func (v *Version) IsBranch() bool {
+	return v.branch != ""
+}
+
+// Normalized 返回规范化后的版本字符串，与 composer.lock 中的 version_normalized 格式一致
+func (v *Version) Normalized() string {
+	if v.branch != "" {
+		return "dev-" + v.branch
+	}
+
+	s := fmt.Sprintf("%d.%d.%d.%d", v.parts[0], v.parts[1], v.parts[2], v.parts[3])
+	if v.modifier != modifierStable {
+		s += "-" + modifierName(v.modifier)
+		for i, n := range v.modifierNum {
+			if i > 0 {
+				s += "."
+			}
+			s += strconv.Itoa(n)
+		}
+	}
+	if v.devSuffix {
+		s += "-dev"
+	}
+	return s
+}
+
+// String 返回原始版本字符串，为空时返回规范化版本
+func (v *Version) String() string {
+	if v.original != "" {
+		return v.original
+	}
+	return v.Normalized()
+}
+
+// Stability 返回版本的稳定性
+func (v *Version) Stability() Stability {
+	if v.branch != "" || v.devSuffix {
+		return StabilityDev
+	}
+	switch v.modifier {
+	case modifierAlpha:
+		return StabilityAlpha
+	case modifierBeta:
+		return StabilityBeta
+	case modifierRC:
+		return StabilityRC
+	}
+	return StabilityStable
+}
+
+// Compare 比较两个版本
+//
+// 返回值：
+//   - int: v 小于、等于、大于 other 时分别返回 -1、0、1
+//
+// 分支版本总是小于数字版本，不同分支之间按名称排序。
+func (v *Version) Compare(other *Version) int {
+	switch {
+	case v.branch != "" && other.branch != "":
+		return strings.Compare(v.branch, other.branch)
+	case v.branch != "":
+		return -1
+	case other.branch != "":
+		return 1
+	}
+
+	for i := 0; i < 4; i++ {
+		if c := compareInt(v.parts[i], other.parts[i]); c != 0 {
+			return c
+		}
+	}
+
+	am, bm := v.effectiveModifier(), other.effectiveModifier()
+	if c := compareInt(am, bm); c != 0 {
+		return c
+	}
+	for i := 0; i < len(v.modifierNum) || i < len(other.modifierNum); i++ {
+		var a, b int
+		if i < len(v.modifierNum) {
+			a = v.modifierNum[i]
+		}
+		if i < len(other.modifierNum) {
+			b = other.modifierNum[i]
+		}
+		if c := compareInt(a, b); c != 0 {
+			return c
+		}
+	}
+
+	// 1.0.0-beta1-dev 早于 1.0.0-beta1
+	if am != modifierDev && v.devSuffix != other.devSuffix {
+		if v.devSuffix {
+			return -1
+		}
+		return 1
+	}
+	return 0
+}
+
+// effectiveModifier 返回参与比较的修饰符，纯 -dev 后缀视为最早的预发布版本
+func (v *Version) effectiveModifier() int {
+	if v.modifier == modifierStable && v.devSuffix {
+		return modifierDev
+	}
+	return v.modifier
+}
+
+// withDevSuffix 返回添加 -dev 后缀的副本，用于构造约束的下界
+func (v *Version) withDevSuffix() *Version {
+	c := *v
+	c.original = ""
+	c.devSuffix = true
+	return &c
+}
+
+// modifierName 返回修饰符在规范化版本中的名称
+func modifierName(modifier int) string {
+	switch modifier {
+	case modifierAlpha:
+		return "alpha"
+	case modifierBeta:
+		return "beta"
+	case modifierRC:
+		return "RC"
+	case modifierPatch:
+		return "patch"
+	}
+	return ""
+}
+
+func compareInt(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
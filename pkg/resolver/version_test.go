@@ -0,0 +1,117 @@
+package resolver
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseVersion(t *testing.T) {
+	cases := map[string]struct {
+		normalized string
+		stability  Stability
+	}{
+		"1.2":             {"1.2.0.0", StabilityStable},
+		"v2.0.0-beta1":    {"2.0.0.0-beta1", StabilityBeta},
+		"1.0.0-RC1":       {"1.0.0.0-RC1", StabilityRC},
+		"1.0.0-p1":        {"1.0.0.0-patch1", StabilityStable},
+		"1.0-dev":         {"1.0.0.0-dev", StabilityDev},
+		"2.x-dev":         {"2.9999999.9999999.9999999-dev", StabilityDev},
+		"dev-main":        {"dev-main", StabilityDev},
+		"1.0.0-beta1-dev": {"1.0.0.0-beta1-dev", StabilityDev},
+	}
+
+	for input, want := range cases {
+		v, err := ParseVersion(input)
+		if err != nil {
+			t.Errorf("%s: 解析失败: %v", input, err)
+			continue
+		}
+		if v.Normalized() != want.normalized || v.Stability() != want.stability {
+			t.Errorf("%s: 期望 %s/%s，实际为 %s/%s", input, want.normalized, want.stability, v.Normalized(), v.Stability())
+		}
+	}
+
+	if _, err := ParseVersion("not a version"); !errors.Is(err, ErrInvalidVersion) {
+		t.Errorf("无效版本应返回ErrInvalidVersion，实际为%v", err)
+	}
+}
+
+func TestVersionCompare(t *testing.T) {
+	ordered := []string{"dev-main", "1.0.0-dev", "1.0.0-alpha1", "1.0.0-beta1-dev", "1.0.0-beta1", "1.0.0-beta2", "1.0.0-RC1", "1.0.0", "1.0.0-p1", "1.0.1", "2.x-dev"}
+	for i := 0; i < len(ordered)-1; i++ {
+		a, _ := ParseVersion(ordered[i])
+		b, _ := ParseVersion(ordered[i+1])
+		if a.Compare(b) >= 0 || b.Compare(a) <= 0 {
+			t.Errorf("%s 应小于 %s", ordered[i], ordered[i+1])
+		}
+	}
+}
+
+func TestConstraintMatches(t *testing.T) {
+	versions := []string{"0.3.5", "1.0.0", "1.2.0", "1.2.5", "1.3.0", "2.0.0-beta1", "2.0.0", "2.1.0", "dev-main"}
+	cases := map[string][]string{
+		"^1.2":            {"1.2.0", "1.2.5", "1.3.0"},
+		"~1.2.3":          {"1.2.5"},
+		"1.2.*":           {"1.2.0", "1.2.5"},
+		">=1.0 <1.3":      {"1.0.0", "1.2.0", "1.2.5"},
+		">= 1.0, < 1.3":   {"1.0.0", "1.2.0", "1.2.5"},
+		"1.0 - 1.2":       {"1.0.0", "1.2.0", "1.2.5"},
+		"1.0.0 - 1.2.0":   {"1.0.0", "1.2.0"},
+		"^0.3":            {"0.3.5"},
+		"^1.0 || ^2.0":    {"1.0.0", "1.2.0", "1.2.5", "1.3.0", "2.0.0-beta1", "2.0.0", "2.1.0"},
+		"!=1.2.0 ^1.0":    {"1.0.0", "1.2.5", "1.3.0"},
+		"dev-main":        {"dev-main"},
+		"2.0.0":           {"2.0.0"},
+		"<2.0":            {"0.3.5", "1.0.0", "1.2.0", "1.2.5", "1.3.0"},
+		"*":               versions,
+		"dev-main as 1.x": {"dev-main"},
+	}
+
+	for raw, want := range cases {
+		c, err := ParseConstraint(raw)
+		if err != nil {
+			t.Errorf("%s: 解析失败: %v", raw, err)
+			continue
+		}
+		var got []string
+		for _, v := range versions {
+			pv, _ := ParseVersion(v)
+			if c.Matches(pv) {
+				got = append(got, v)
+			}
+		}
+		if len(got) != len(want) {
+			t.Errorf("%s: 期望匹配 %v，实际为 %v", raw, want, got)
+			continue
+		}
+		for i := range got {
+			if got[i] != want[i] {
+				t.Errorf("%s: 期望匹配 %v，实际为 %v", raw, want, got)
+				break
+			}
+		}
+	}
+
+	if _, err := ParseConstraint("^1.0@unstable"); !errors.Is(err, ErrInvalidConstraint) {
+		t.Errorf("无效的稳定性标记应返回ErrInvalidConstraint，实际为%v", err)
+	}
+}
+
+func TestConstraintStabilityAndIntersects(t *testing.T) {
+	if s := MustParseConstraint("^2.0@beta").Stability(); s != StabilityBeta {
+		t.Errorf("@beta 标记应被识别，实际为%q", s)
+	}
+	if s := MustParseConstraint(">=1.0").Stability(); s != "" {
+		t.Errorf("稳定版本约束不应带有稳定性标记，实际为%q", s)
+	}
+	if s := MustParseConstraint("dev-main").Stability(); s != StabilityDev {
+		t.Errorf("分支约束应为dev稳定性，实际为%q", s)
+	}
+
+	if !MustParseConstraint("^1.2").Intersects(MustParseConstraint(">=1.5 <3")) {
+		t.Error("^1.2 与 >=1.5 <3 应存在交集")
+	}
+	if MustParseConstraint("^1.2").Intersects(MustParseConstraint("^2.0")) {
+		t.Error("^1.2 与 ^2.0 不应存在交集")
+	}
+}
@@ -2,43 +2,77 @@
 package utils
 
 import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"hash"
 	"io"
 	"net/http"
-	"net/url"
 	"os"
+	"path/filepath"
+	"strings"
 	"time"
 )
 
-// ErrDownloadFailed 表示下载失败的错误
-var ErrDownloadFailed = errors.New("下载失败")
+var (
+	// ErrDownloadFailed 表示下载失败的错误
+	ErrDownloadFailed = errors.New("下载失败")
+	// ErrChecksumMismatch 表示下载文件的校验和与期望值不一致
+	ErrChecksumMismatch = errors.New("校验和不匹配")
+)
+
+// 重试等待时间的默认值和上限
+const (
+	defaultRetryBackoff = time.Second
+	maxRetryBackoff     = 30 * time.Second
+)
+
+// ProgressFunc 是下载进度回调函数
+//
+// downloaded 为已下载的字节数（断点续传时包括之前已下载的部分），
+// total 为文件总大小，服务器没有返回长度时为 -1。
+type ProgressFunc func(downloaded, total int64)
 
 // DownloadConfig 定义下载配置选项
 //
-// 该结构体包含配置下载行为的选项，如是否使用代理及代理URL。
-// 可以根据需要扩展此结构体，添加超时、重试次数等选项。
+// 该结构体包含配置下载行为的选项，如代理、超时、重试、进度回调和校验和。
+// 零值表示不使用显式代理、超时60秒、不重试、不校验。
 type DownloadConfig struct {
 	// UseProxy 指示是否使用代理
 	UseProxy bool
-	// ProxyURL 指定代理服务器URL，仅当UseProxy为true时有效
+	// ProxyURL 指定代理服务器URL，仅当UseProxy为true时有效；
+	// 未使用时按 HTTPS_PROXY、HTTP_PROXY 环境变量选择代理，两种情况下都遵循 NO_PROXY
 	ProxyURL string
-	// TimeoutSeconds 指定下载超时时间（秒），默认为60秒
+	// TimeoutSeconds 指定单次请求的超时时间（秒），默认为60秒
 	TimeoutSeconds int
+	// Retries 指定失败后的最大重试次数，网络错误、408、429 和 5xx 状态码会触发重试
+	Retries int
+	// RetryBackoff 指定第一次重试前的等待时间，之后每次加倍，最长30秒；默认为1秒
+	RetryBackoff time.Duration
+	// Progress 下载进度回调，可以为 nil
+	Progress ProgressFunc
+	// ExpectedChecksum 期望的十六进制校验和，根据长度识别 SHA-1、SHA-256、SHA-384 或 SHA-512
+	ExpectedChecksum string
 }
 
 // DownloadFile 从指定URL下载文件到目标路径
 //
 // 支持HTTP和HTTPS协议，可选择是否通过代理下载。
-// 如果目标文件已存在，将被覆盖。
+// 文件先写入目标目录中的临时文件，下载完成并通过校验后再重命名到目标路径，
+// 因此下载失败时不会留下不完整的文件，已存在的目标文件也保持不变。
+// 重试时如果服务器支持 Range 请求，会从已下载的位置继续下载。
 //
 // 参数:
 //   - sourceURL: 要下载文件的URL地址
 //   - destPath: 下载文件存储的本地路径
-//   - config: 下载配置，包含代理设置等
+//   - config: 下载配置，包含代理、重试、进度回调和校验和等设置
 //
 // 返回值:
-//   - error: 如果下载过程中出现错误则返回，成功则返回nil
+//   - error: 如果下载过程中出现错误则返回，成功则返回nil；
+//     所有错误都包装了 ErrDownloadFailed，校验失败时同时包装 ErrChecksumMismatch
 //
 // 使用示例:
 //
@@ -46,11 +80,16 @@ type DownloadConfig struct {
 //	config := utils.DownloadConfig{UseProxy: false}
 //	err := utils.DownloadFile("https://example.com/file.zip", "/tmp/file.zip", config)
 //
-//	// 使用代理下载
+//	// 使用代理、重试和进度回调下载，并校验 SHA-256
 //	proxyConfig := utils.DownloadConfig{
 //	    UseProxy: true,
 //	    ProxyURL: "http://proxy.example.com:8080",
 //	    TimeoutSeconds: 120, // 设置更长的超时时间
+//	    Retries: 3,
+//	    Progress: func(downloaded, total int64) {
+//	        fmt.Printf("\r%d/%d", downloaded, total)
+//	    },
+//	    ExpectedChecksum: "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
 //	}
 //	err := utils.DownloadFile("https://example.com/large-file.tar.gz", "/downloads/file.tar.gz", proxyConfig)
 //
@@ -60,52 +99,312 @@ type DownloadConfig struct {
 //   - HTTP响应状态码非200
 //   - 本地文件创建或写入失败
 //   - 超时
+//   - 校验和不匹配
 func DownloadFile(sourceURL, destPath string, config DownloadConfig) error {
-	// 创建HTTP客户端
-	client := &http.Client{}
+	newHash, expected, err := checksumHash(config.ExpectedChecksum)
+	if err != nil {
+		return err
+	}
 
-	// 设置超时
-	timeout := 60 // 默认60秒
-	if config.TimeoutSeconds > 0 {
-		timeout = config.TimeoutSeconds
+	client, err := NewHTTPClient(config)
+	if err != nil {
+		return err
+	}
+
+	// 在目标目录中创建临时文件，保证最后的重命名是原子的
+	dir, base := filepath.Split(destPath)
+	tmp, err := os.CreateTemp(dir, "."+base+"-*.part")
+	if err != nil {
+		return fmt.Errorf("%w: 无法创建目标文件: %v", ErrDownloadFailed, err)
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath)
+
+	d := &download{client: client, url: sourceURL, file: tmp, progress: config.Progress}
+	err = d.run(config)
+	if closeErr := tmp.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("%w: 写入文件失败: %v", ErrDownloadFailed, closeErr)
+	}
+	if err != nil {
+		return err
 	}
-	client.Timeout = time.Duration(timeout) * time.Second
 
-	// 如果需要使用代理
-	if config.UseProxy && config.ProxyURL != "" {
-		proxyURL, err := url.Parse(config.ProxyURL)
+	if newHash != nil {
+		actual, err := fileChecksum(tmpPath, newHash)
 		if err != nil {
-			return fmt.Errorf("%w: 代理URL格式无效: %v", ErrDownloadFailed, err)
+			return fmt.Errorf("%w: 计算校验和失败: %v", ErrDownloadFailed, err)
 		}
-		client.Transport = &http.Transport{
-			Proxy: http.ProxyURL(proxyURL),
+		if actual != expected {
+			return fmt.Errorf("%w: %w: 期望 %s，实际 %s", ErrDownloadFailed, ErrChecksumMismatch, expected, actual)
 		}
 	}
 
-	// 发起HTTP请求
-	resp, err := client.Get(sourceURL)
+	if err := os.Rename(tmpPath, destPath); err != nil {
+		return fmt.Errorf("%w: 无法创建目标文件: %v", ErrDownloadFailed, err)
+	}
+	return nil
+}
+
+// download 保存一次下载在多次尝试之间共享的状态
+type download struct {
+	client   *http.Client
+	url      string
+	file     *os.File
+	progress ProgressFunc
+	// offset 已写入临时文件的字节数
+	offset int64
+	// validator 第一次响应的 ETag 或 Last-Modified，用于 If-Range 确认续传的是同一个文件
+	validator string
+}
+
+// run 按配置的重试次数和指数退避执行下载
+func (d *download) run(config DownloadConfig) error {
+	backoff := config.RetryBackoff
+	if backoff <= 0 {
+		backoff = defaultRetryBackoff
+	}
+
+	for attempt := 0; ; attempt++ {
+		err := d.attempt()
+		if err == nil {
+			return nil
+		}
+		var retryable *retryableError
+		if !errors.As(err, &retryable) || attempt >= config.Retries {
+			return err
+		}
+
+		time.Sleep(backoff)
+		backoff *= 2
+		if backoff > maxRetryBackoff {
+			backoff = maxRetryBackoff
+		}
+	}
+}
+
+// attempt 发起一次请求，已有部分内容时通过 Range 请求续传
+func (d *download) attempt() error {
+	req, err := http.NewRequest(http.MethodGet, d.url, nil)
 	if err != nil {
 		return fmt.Errorf("%w: 请求失败: %v", ErrDownloadFailed, err)
 	}
+	if d.offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", d.offset))
+		if d.validator != "" {
+			req.Header.Set("If-Range", d.validator)
+		}
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return &retryableError{fmt.Errorf("%w: 请求失败: %v", ErrDownloadFailed, err)}
+	}
 	defer resp.Body.Close()
 
-	// 检查响应状态
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("%w: 服务器返回状态码 %d", ErrDownloadFailed, resp.StatusCode)
+	switch {
+	case resp.StatusCode == http.StatusPartialContent && d.offset > 0 && contentRangeStart(resp) == d.offset:
+		// 服务器接受了续传请求，继续追加
+	case resp.StatusCode == http.StatusOK:
+		// 第一次请求，或者服务器不支持续传、文件已变化，从头开始
+		if err := d.reset(); err != nil {
+			return err
+		}
+		if etag := resp.Header.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+			d.validator = etag
+		} else {
+			d.validator = resp.Header.Get("Last-Modified")
+		}
+	case resp.StatusCode == http.StatusPartialContent, resp.StatusCode == http.StatusRequestedRangeNotSatisfiable:
+		// 续传的位置与服务器不一致，下次从头开始
+		if err := d.reset(); err != nil {
+			return err
+		}
+		d.validator = ""
+		return &retryableError{fmt.Errorf("%w: 服务器无法从 %d 字节处续传", ErrDownloadFailed, d.offset)}
+	default:
+		err := fmt.Errorf("%w: 服务器返回状态码 %d", ErrDownloadFailed, resp.StatusCode)
+		if resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusRequestTimeout {
+			return &retryableError{err}
+		}
+		return err
 	}
 
-	// 创建目标文件
-	out, err := os.Create(destPath)
-	if err != nil {
-		return fmt.Errorf("%w: 无法创建目标文件: %v", ErrDownloadFailed, err)
+	total := int64(-1)
+	if resp.ContentLength >= 0 {
+		total = d.offset + resp.ContentLength
 	}
-	defer out.Close()
 
 	// 将响应内容写入文件
-	_, err = io.Copy(out, resp.Body)
-	if err != nil {
-		return fmt.Errorf("%w: 写入文件失败: %v", ErrDownloadFailed, err)
+	if d.progress != nil {
+		d.progress(d.offset, total)
+	}
+	if _, err := io.Copy(d.file, &progressReader{reader: resp.Body, download: d, total: total}); err != nil {
+		var writeErr *fileWriteError
+		if errors.As(err, &writeErr) {
+			return fmt.Errorf("%w: 写入文件失败: %v", ErrDownloadFailed, err)
+		}
+		return &retryableError{fmt.Errorf("%w: 读取响应失败: %v", ErrDownloadFailed, err)}
+	}
+	if total >= 0 && d.offset < total {
+		return &retryableError{fmt.Errorf("%w: 响应不完整，期望 %d 字节，实际 %d 字节", ErrDownloadFailed, total, d.offset)}
 	}
+	return nil
+}
 
+// reset 清空临时文件，从头开始下载
+func (d *download) reset() error {
+	d.offset = 0
+	if err := d.file.Truncate(0); err != nil {
+		return fmt.Errorf("%w: 写入文件失败: %v", ErrDownloadFailed, err)
+	}
+	if _, err := d.file.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("%w: 写入文件失败: %v", ErrDownloadFailed, err)
+	}
 	return nil
 }
+
+// progressReader 在读取响应时记录已下载的字节数并更新下载进度
+type progressReader struct {
+	reader   io.Reader
+	download *download
+	total    int64
+}
+
+func (r *progressReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	if n > 0 {
+		r.download.offset += int64(n)
+		if r.download.progress != nil {
+			r.download.progress(r.download.offset, r.total)
+		}
+	}
+	return n, err
+}
+
+// WriteTo 避免 io.Copy 绕过 Read，同时区分写入本地文件的错误和读取响应的错误
+func (r *progressReader) WriteTo(w io.Writer) (int64, error) {
+	buf := make([]byte, 32*1024)
+	var written int64
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			m, werr := w.Write(buf[:n])
+			written += int64(m)
+			if werr != nil {
+				return written, &fileWriteError{werr}
+			}
+		}
+		if err == io.EOF {
+			return written, nil
+		}
+		if err != nil {
+			return written, err
+		}
+	}
+}
+
+// retryableError 表示可以重试的下载错误
+type retryableError struct {
+	err error
+}
+
+func (e *retryableError) Error() string { return e.err.Error() }
+func (e *retryableError) Unwrap() error { return e.err }
+
+// fileWriteError 表示写入本地文件失败，这类错误不会重试
+type fileWriteError struct {
+	err error
+}
+
+func (e *fileWriteError) Error() string { return e.err.Error() }
+func (e *fileWriteError) Unwrap() error { return e.err }
+
+// contentRangeStart 解析 Content-Range 响应头中的起始位置，无法解析时返回 -1
+func contentRangeStart(resp *http.Response) int64 {
+	var start, end int64
+	if _, err := fmt.Sscanf(resp.Header.Get("Content-Range"), "bytes %d-%d/", &start, &end); err != nil {
+		return -1
+	}
+	return start
+}
+
+// checksumHash 根据十六进制校验和的长度选择哈希算法，checksum 为空时返回 nil
+func checksumHash(checksum string) (func() hash.Hash, string, error) {
+	checksum = strings.ToLower(strings.TrimSpace(checksum))
+	if checksum == "" {
+		return nil, "", nil
+	}
+	if _, err := hex.DecodeString(checksum); err != nil {
+		return nil, "", fmt.Errorf("%w: 校验和格式无效: %s", ErrDownloadFailed, checksum)
+	}
+
+	switch len(checksum) {
+	case sha1.Size * 2:
+		return sha1.New, checksum, nil
+	case sha256.Size * 2:
+		return sha256.New, checksum, nil
+	case sha512.Size384 * 2:
+		return sha512.New384, checksum, nil
+	case sha512.Size * 2:
+		return sha512.New, checksum, nil
+	default:
+		return nil, "", fmt.Errorf("%w: 无法识别校验和的算法: %s", ErrDownloadFailed, checksum)
+	}
+}
+
+// fileChecksum 计算文件的十六进制校验和
+func fileChecksum(path string, newHash func() hash.Hash) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := newHash()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// NewHTTPClient 根据下载配置创建HTTP客户端
+//
+// 该函数设置超时时间（默认60秒）和代理，供 DownloadFile 以及
+// 其他需要访问远程仓库的组件复用同一套HTTP配置。
+// UseProxy 为 true 时使用 ProxyURL，否则按 HTTPS_PROXY、HTTP_PROXY 环境变量选择代理；
+// 两种情况下 NO_PROXY 中列出的主机都直接连接。
+//
+// 参数:
+//   - config: 下载配置，包含代理和超时设置
+//
+// 返回值:
+//   - *http.Client: 配置好的HTTP客户端
+//   - error: 如果代理URL格式无效则返回错误
+//
+// 使用示例:
+//
+//	client, err := utils.NewHTTPClient(utils.DownloadConfig{TimeoutSeconds: 30})
+//	if err != nil {
+//	    log.Fatalf("创建HTTP客户端失败: %v", err)
+//	}
+//	resp, err := client.Get("https://repo.packagist.org/packages.json")
+func NewHTTPClient(config DownloadConfig) (*http.Client, error) {
+	client := &http.Client{}
+
+	// 设置超时
+	timeout := 60 // 默认60秒
+	if config.TimeoutSeconds > 0 {
+		timeout = config.TimeoutSeconds
+	}
+	client.Timeout = time.Duration(timeout) * time.Second
+
+	proxy, err := proxyFunc(config)
+	if err != nil {
+		return nil, err
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = proxy
+	client.Transport = transport
+
+	return client, nil
+}
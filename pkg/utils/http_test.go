@@ -1,12 +1,19 @@
 package utils
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -366,3 +373,163 @@ func isErrorOfType(err error, target error) bool {
 	// 简单的字符串匹配检查
 	return err != nil && target != nil && err.Error() == target.Error()
 }
+
+// 测试失败后按指数退避重试，客户端错误不重试
+func TestDownloadFileRetries(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&attempts, 1)
+		switch {
+		case r.URL.Path == "/missing":
+			w.WriteHeader(http.StatusNotFound)
+		case n < 3:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			_, _ = w.Write([]byte("retried content"))
+		}
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	destPath := filepath.Join(dir, "retried.txt")
+
+	// 重试次数不足时失败，并且不留下临时文件
+	config := DownloadConfig{Retries: 1, RetryBackoff: time.Millisecond}
+	if err := DownloadFile(server.URL+"/flaky", destPath, config); !errors.Is(err, ErrDownloadFailed) {
+		t.Errorf("期望错误 %v，但得到 %v", ErrDownloadFailed, err)
+	}
+	if n := atomic.LoadInt32(&attempts); n != 2 {
+		t.Errorf("期望请求 2 次，实际 %d 次", n)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("下载失败后不应留下文件，实际有 %d 个", len(entries))
+	}
+
+	atomic.StoreInt32(&attempts, 0)
+	config.Retries = 3
+	if err := DownloadFile(server.URL+"/flaky", destPath, config); err != nil {
+		t.Fatalf("重试后下载失败: %v", err)
+	}
+	if n := atomic.LoadInt32(&attempts); n != 3 {
+		t.Errorf("期望请求 3 次，实际 %d 次", n)
+	}
+	if content, _ := os.ReadFile(destPath); string(content) != "retried content" {
+		t.Errorf("文件内容错误: %q", content)
+	}
+
+	atomic.StoreInt32(&attempts, 0)
+	if err := DownloadFile(server.URL+"/missing", destPath, config); !errors.Is(err, ErrDownloadFailed) {
+		t.Errorf("期望错误 %v，但得到 %v", ErrDownloadFailed, err)
+	}
+	if n := atomic.LoadInt32(&attempts); n != 1 {
+		t.Errorf("客户端错误不应重试，实际请求 %d 次", n)
+	}
+}
+
+// 测试连接中断后通过 Range 请求续传，并报告下载进度
+func TestDownloadFileResume(t *testing.T) {
+	data := bytes.Repeat([]byte("0123456789"), 10000)
+	half := len(data) / 2
+
+	var attempts int32
+	var rangeHeader, ifRangeHeader string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"v1"`)
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			// 只发送一半内容后中断连接
+			w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+			_, _ = w.Write(data[:half])
+			w.(http.Flusher).Flush()
+			panic(http.ErrAbortHandler)
+		}
+		rangeHeader = r.Header.Get("Range")
+		ifRangeHeader = r.Header.Get("If-Range")
+		http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(data))
+	}))
+	defer server.Close()
+
+	var last, calls int64
+	config := DownloadConfig{
+		Retries:      2,
+		RetryBackoff: time.Millisecond,
+		Progress: func(downloaded, total int64) {
+			if downloaded < last {
+				t.Errorf("下载进度倒退: %d < %d", downloaded, last)
+			}
+			if total != int64(len(data)) {
+				t.Errorf("总大小错误: 期望 %d，实际 %d", len(data), total)
+			}
+			last = downloaded
+			calls++
+		},
+	}
+
+	destPath := filepath.Join(t.TempDir(), "resumed.bin")
+	if err := DownloadFile(server.URL, destPath, config); err != nil {
+		t.Fatalf("续传下载失败: %v", err)
+	}
+
+	content, err := os.ReadFile(destPath)
+	if err != nil {
+		t.Fatalf("无法读取下载的文件: %v", err)
+	}
+	if !bytes.Equal(content, data) {
+		t.Errorf("文件内容错误: 期望 %d 字节，实际 %d 字节", len(data), len(content))
+	}
+	if n := atomic.LoadInt32(&attempts); n != 2 {
+		t.Errorf("期望请求 2 次，实际 %d 次", n)
+	}
+	if expected := fmt.Sprintf("bytes=%d-", half); rangeHeader != expected {
+		t.Errorf("Range 错误: 期望 %q，实际 %q", expected, rangeHeader)
+	}
+	if ifRangeHeader != `"v1"` {
+		t.Errorf("If-Range 错误: %q", ifRangeHeader)
+	}
+	if last != int64(len(data)) {
+		t.Errorf("最后的进度错误: 期望 %d，实际 %d", len(data), last)
+	}
+	if calls <= 1 {
+		t.Errorf("进度回调次数过少: %d", calls)
+	}
+}
+
+// 测试校验和验证失败时不覆盖已有文件
+func TestDownloadFileChecksum(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("checksum content"))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	destPath := filepath.Join(dir, "file.txt")
+	if err := os.WriteFile(destPath, []byte("original"), 0644); err != nil {
+		t.Fatalf("无法创建文件: %v", err)
+	}
+
+	sum256 := sha256.Sum256([]byte("checksum content"))
+	sum384 := sha512.Sum384([]byte("checksum content"))
+
+	err := DownloadFile(server.URL, destPath, DownloadConfig{ExpectedChecksum: strings.Repeat("0", 64)})
+	if !errors.Is(err, ErrChecksumMismatch) || !errors.Is(err, ErrDownloadFailed) {
+		t.Errorf("期望校验和错误，但得到 %v", err)
+	}
+	if content, _ := os.ReadFile(destPath); string(content) != "original" {
+		t.Errorf("校验失败时不应覆盖已有文件，实际内容 %q", content)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("校验失败后不应留下临时文件，实际有 %d 个文件", len(entries))
+	}
+
+	if err := DownloadFile(server.URL, destPath, DownloadConfig{ExpectedChecksum: "abc"}); !errors.Is(err, ErrDownloadFailed) {
+		t.Errorf("期望错误 %v，但得到 %v", ErrDownloadFailed, err)
+	}
+
+	for _, checksum := range []string{hex.EncodeToString(sum384[:]), strings.ToUpper(hex.EncodeToString(sum256[:]))} {
+		if err := DownloadFile(server.URL, destPath, DownloadConfig{ExpectedChecksum: checksum}); err != nil {
+			t.Errorf("校验和 %s 验证失败: %v", checksum, err)
+		}
+	}
+	if content, _ := os.ReadFile(destPath); string(content) != "checksum content" {
+		t.Errorf("文件内容错误: %q", content)
+	}
+}
@@ -0,0 +1,165 @@
+package utils
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// proxyFunc 根据下载配置返回 http.Transport 使用的代理选择函数
+//
+// UseProxy 为 true 且设置了 ProxyURL 时所有请求使用 ProxyURL，否则 HTTPS 请求使用
+// HTTPS_PROXY，HTTP 请求使用 HTTP_PROXY（小写形式同样有效）。两种情况下都跳过 NO_PROXY
+// 中列出的主机；与标准库一致，环境变量中的代理不用于 localhost 和回环地址。
+// 环境变量中的代理URL格式无效时只有使用该代理的请求失败，返回的错误只来自 ProxyURL。
+func proxyFunc(config DownloadConfig) (func(*http.Request) (*url.URL, error), error) {
+	noProxy := parseNoProxy(getEnvAny("NO_PROXY", "no_proxy"))
+
+	if config.UseProxy && config.ProxyURL != "" {
+		proxyURL, err := url.Parse(config.ProxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("%w: 代理URL格式无效: %v", ErrDownloadFailed, err)
+		}
+		return func(req *http.Request) (*url.URL, error) {
+			if noProxy.matches(req.URL) {
+				return nil, nil
+			}
+			return proxyURL, nil
+		}, nil
+	}
+
+	// 环境变量中的代理在请求时按协议解析，格式错误只影响使用该代理的请求
+	httpProxy := getEnvAny("HTTP_PROXY", "http_proxy")
+	httpsProxy := getEnvAny("HTTPS_PROXY", "https_proxy")
+
+	return func(req *http.Request) (*url.URL, error) {
+		value := httpProxy
+		if req.URL.Scheme == "https" {
+			value = httpsProxy
+		}
+		if value == "" || isLoopbackHost(req.URL.Hostname()) || noProxy.matches(req.URL) {
+			return nil, nil
+		}
+		return parseEnvProxy(value)
+	}, nil
+}
+
+// getEnvAny 返回第一个非空的环境变量值
+func getEnvAny(names ...string) string {
+	for _, name := range names {
+		if value := os.Getenv(name); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// parseEnvProxy 解析环境变量中的代理地址，省略协议时按 http 处理
+func parseEnvProxy(value string) (*url.URL, error) {
+	if value == "" {
+		return nil, nil
+	}
+	if !strings.Contains(value, "://") {
+		value = "http://" + value
+	}
+	proxyURL, err := url.Parse(value)
+	if err != nil || proxyURL.Host == "" {
+		return nil, fmt.Errorf("%w: 环境变量中的代理URL格式无效: %s", ErrDownloadFailed, value)
+	}
+	return proxyURL, nil
+}
+
+// isLoopbackHost 判断主机是否为 localhost 或回环地址
+func isLoopbackHost(host string) bool {
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// noProxyList 是解析后的 NO_PROXY 列表
+type noProxyList struct {
+	all      bool
+	networks []*net.IPNet
+	// hosts 的每一项为主机名或 IP，domain 为 true 时同时匹配其子域名
+	hosts []noProxyHost
+}
+
+type noProxyHost struct {
+	name   string
+	port   string
+	domain bool
+}
+
+// parseNoProxy 解析逗号分隔的 NO_PROXY，支持 *、主机名、.域名、IP、CIDR 和可选端口
+func parseNoProxy(value string) noProxyList {
+	var list noProxyList
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		if entry == "" {
+			continue
+		}
+		if entry == "*" {
+			list.all = true
+			continue
+		}
+		if _, network, err := net.ParseCIDR(entry); err == nil {
+			list.networks = append(list.networks, network)
+			continue
+		}
+
+		host, port := entry, ""
+		if h, p, err := net.SplitHostPort(entry); err == nil {
+			host, port = h, p
+		}
+		host = strings.Trim(host, "[]")
+		domain := strings.HasPrefix(host, ".") || strings.HasPrefix(host, "*.")
+		host = strings.TrimPrefix(strings.TrimPrefix(host, "*"), ".")
+		if net.ParseIP(host) == nil {
+			// 与 curl 一致，example.com 同时匹配 example.com 的子域名
+			domain = true
+		}
+		list.hosts = append(list.hosts, noProxyHost{name: host, port: port, domain: domain})
+	}
+	return list
+}
+
+// matches 判断请求地址是否应该直接连接
+func (l noProxyList) matches(u *url.URL) bool {
+	if l.all {
+		return true
+	}
+
+	host := strings.ToLower(u.Hostname())
+	port := u.Port()
+	if port == "" {
+		switch u.Scheme {
+		case "https":
+			port = "443"
+		case "http":
+			port = "80"
+		}
+	}
+
+	if ip := net.ParseIP(host); ip != nil {
+		for _, network := range l.networks {
+			if network.Contains(ip) {
+				return true
+			}
+		}
+	}
+
+	for _, entry := range l.hosts {
+		if entry.port != "" && entry.port != port {
+			continue
+		}
+		if host == entry.name || (entry.domain && strings.HasSuffix(host, "."+entry.name)) {
+			return true
+		}
+	}
+	return false
+}
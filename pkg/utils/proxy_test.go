@@ -0,0 +1,141 @@
+package utils
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNoProxyMatches(t *testing.T) {
+	list := parseNoProxy("internal.example.com, .corp.example, 10.0.0.0/8, 192.168.1.5, mirror.example:8443")
+
+	tests := []struct {
+		url  string
+		want bool
+	}{
+		{"https://internal.example.com/file", true},
+		{"https://api.internal.example.com/file", true},
+		{"https://example.com/file", false},
+		{"https://pkg.corp.example/file", true},
+		{"http://10.1.2.3/file", true},
+		{"http://11.1.2.3/file", false},
+		{"http://192.168.1.5:8080/file", true},
+		{"https://mirror.example:8443/file", true},
+		{"https://mirror.example/file", false},
+	}
+	for _, tt := range tests {
+		u, err := url.Parse(tt.url)
+		if err != nil {
+			t.Fatalf("无法解析URL %s: %v", tt.url, err)
+		}
+		if got := list.matches(u); got != tt.want {
+			t.Errorf("%s: 期望 %v，实际 %v", tt.url, tt.want, got)
+		}
+	}
+
+	all := parseNoProxy("*")
+	u, _ := url.Parse("https://getcomposer.org")
+	if !all.matches(u) {
+		t.Error("NO_PROXY=* 应该匹配所有主机")
+	}
+}
+
+// proxyFor 返回代理选择函数为指定地址选择的代理，不使用代理时返回空字符串
+func proxyFor(t *testing.T, proxy func(*http.Request) (*url.URL, error), rawURL string) string {
+	t.Helper()
+	req, _ := http.NewRequest(http.MethodGet, rawURL, nil)
+	got, err := proxy(req)
+	if err != nil {
+		t.Fatalf("%s: 选择代理失败: %v", rawURL, err)
+	}
+	if got == nil {
+		return ""
+	}
+	return got.String()
+}
+
+func TestProxyFromEnvironment(t *testing.T) {
+	t.Setenv("HTTP_PROXY", "http://http-proxy.example:3128")
+	t.Setenv("HTTPS_PROXY", "https-proxy.example:3129")
+	t.Setenv("NO_PROXY", "packagist.internal")
+
+	proxy, err := proxyFunc(DownloadConfig{})
+	if err != nil {
+		t.Fatalf("proxyFunc 失败: %v", err)
+	}
+
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"http://getcomposer.org/installer", "http://http-proxy.example:3128"},
+		{"https://getcomposer.org/installer", "http://https-proxy.example:3129"},
+		{"https://repo.packagist.internal/packages.json", ""},
+		{"http://127.0.0.1:8080/file", ""},
+	}
+	for _, tt := range tests {
+		if got := proxyFor(t, proxy, tt.url); got != tt.want {
+			t.Errorf("%s: 期望代理 %q，实际 %q", tt.url, tt.want, got)
+		}
+	}
+
+	// 显式配置的代理优先于环境变量，同样遵循 NO_PROXY
+	proxy, err = proxyFunc(DownloadConfig{UseProxy: true, ProxyURL: "http://explicit.example:8080"})
+	if err != nil {
+		t.Fatalf("proxyFunc 失败: %v", err)
+	}
+	if got := proxyFor(t, proxy, "https://getcomposer.org/installer"); got != "http://explicit.example:8080" {
+		t.Errorf("期望使用显式配置的代理，实际 %q", got)
+	}
+	if got := proxyFor(t, proxy, "https://repo.packagist.internal/p2/a/b.json"); got != "" {
+		t.Errorf("NO_PROXY 中的主机不应使用代理，实际 %q", got)
+	}
+
+	// 格式错误的环境变量只影响使用该代理的请求
+	t.Setenv("HTTP_PROXY", "http://%zz")
+	proxy, err = proxyFunc(DownloadConfig{})
+	if err != nil {
+		t.Fatalf("proxyFunc 失败: %v", err)
+	}
+	req, _ := http.NewRequest(http.MethodGet, "http://getcomposer.org/installer", nil)
+	if _, err := proxy(req); !errors.Is(err, ErrDownloadFailed) {
+		t.Errorf("期望错误 %v，但得到 %v", ErrDownloadFailed, err)
+	}
+	if got := proxyFor(t, proxy, "https://getcomposer.org/installer"); got != "http://https-proxy.example:3129" {
+		t.Errorf("HTTPS 请求应使用 HTTPS_PROXY，实际 %q", got)
+	}
+	if got := proxyFor(t, proxy, "http://repo.packagist.internal/packages.json"); got != "" {
+		t.Errorf("NO_PROXY 中的主机不应使用代理，实际 %q", got)
+	}
+}
+
+func TestDownloadFileViaEnvironmentProxy(t *testing.T) {
+	proxyServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// 经过代理的请求使用绝对地址
+		if r.URL.String() != "http://composer.example/installer" {
+			t.Errorf("代理收到的地址错误: %s", r.URL)
+		}
+		_, _ = w.Write([]byte("via environment proxy"))
+	}))
+	defer proxyServer.Close()
+
+	t.Setenv("HTTP_PROXY", proxyServer.URL)
+	t.Setenv("NO_PROXY", "")
+	t.Setenv("no_proxy", "")
+
+	destPath := filepath.Join(t.TempDir(), "installer")
+	if err := DownloadFile("http://composer.example/installer", destPath, DownloadConfig{TimeoutSeconds: 5}); err != nil {
+		t.Fatalf("通过代理下载失败: %v", err)
+	}
+	content, err := os.ReadFile(destPath)
+	if err != nil {
+		t.Fatalf("无法读取下载的文件: %v", err)
+	}
+	if string(content) != "via environment proxy" {
+		t.Errorf("文件内容错误: %q", content)
+	}
+}